@@ -38,6 +38,9 @@ type SqlClause struct {
 	ModelType     reflect.Type
 	Expr          string
 	Args          []any
+	// Rows is the number of rows a VALUES clause spreads Args across;
+	// zero means a single row.
+	Rows int
 }
 
 // Write renders an individual SQL clause to a string.
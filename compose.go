@@ -3,6 +3,7 @@ package sqlcompose
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/kisielk/sqlstruct"
@@ -14,12 +15,31 @@ type SqlOpts struct {
 	Fields    []string
 	// Driver chooses the SQL dialect for rendering; defaults to DefaultDriver when nil.
 	Driver Driver
+	// TagName is the struct tag read for column names, e.g. "db"; defaults to
+	// DefaultTagName when empty.
+	TagName string
+	// OmitZero makes Exec leave zero-valued model fields out of INSERT
+	// statements so database defaults apply. A zero you mean to insert needs
+	// a pointer field or an explicit Values call.
+	OmitZero bool
+	// NameMapper converts untagged field names to column names and the type
+	// name to the table name when TableName is empty, e.g. to split acronyms
+	// ("APIKey" -> "api_key"); defaults to sqlstruct.ToSnakeCase when nil.
+	NameMapper func(string) string
 }
 
 // SQLStatement represents a sequence of SQL clauses forming a statement.
 type SQLStatement struct {
 	Clauses []SqlClause
 	Driver  Driver
+	// TagName is the struct tag used to map fields to columns when binding
+	// models and scanning rows; empty means DefaultTagName.
+	TagName string
+	// NameMapper is copied from SqlOpts.NameMapper; nil means
+	// sqlstruct.ToSnakeCase.
+	NameMapper func(string) string
+	// OmitZero is copied from SqlOpts.OmitZero by Insert.
+	OmitZero bool
 }
 
 // Write renders the complete SQL statement by concatenating all clauses using the configured Driver (or DefaultDriver).
@@ -59,13 +79,77 @@ func (s SQLStatement) Args() []any {
 		if c.Type == ClauseJoin {
 			out = append(out, c.JoinStatement.Args()...)
 		}
+		if c.Type == ClauseWhere || c.Type == ClauseJoin {
+			out = append(out, expandArgs(c.Args)...)
+			continue
+		}
 		out = append(out, c.Args...)
 	}
 	return out
 }
 
-// Where appends a WHERE clause to the statement.
+// Where adds a condition to the WHERE clause. A slice argument expands to
+// one placeholder per element, e.g. Where("id IN (?)", []int{1, 2, 3}).
+//
+// Repeated calls combine with AND: Where("a=?", 1).Where("b=?", 2) renders
+// WHERE (a=?) AND (b=?).
 func (s SQLStatement) Where(expr string, args ...any) SQLStatement {
+	return s.combineWhere("AND", expr, args)
+}
+
+// OrWhere combines expr with the existing WHERE condition using OR, e.g.
+// Where("a=?", 1).OrWhere("b=?", 2) renders WHERE (a=?) OR (b=?). Without a
+// prior condition it behaves like Where.
+func (s SQLStatement) OrWhere(expr string, args ...any) SQLStatement {
+	return s.combineWhere("OR", expr, args)
+}
+
+// WhereEq ANDs a column = ? condition per filter, in sorted key order so the
+// SQL and args are stable; a nil value renders column IS NULL. Keys are
+// written into the SQL as column names, so they must not come from user
+// input. An empty map leaves the statement unchanged.
+func (s SQLStatement) WhereEq(filters map[string]any) SQLStatement {
+	if len(filters) == 0 {
+		return s
+	}
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conds := make([]string, len(keys))
+	var args []any
+	for i, key := range keys {
+		if filters[key] == nil {
+			conds[i] = key + " IS NULL"
+			continue
+		}
+		conds[i] = key + " = ?"
+		args = append(args, filters[key])
+	}
+	return s.Where(strings.Join(conds, " AND "), args...)
+}
+
+// combineWhere folds expr into the statement's WHERE clause, parenthesizing
+// both sides so earlier conditions group before the new operator.
+func (s SQLStatement) combineWhere(op, expr string, args []any) SQLStatement {
+	for i, c := range s.Clauses {
+		if c.Type != ClauseWhere {
+			continue
+		}
+		clauses := make([]SqlClause, len(s.Clauses))
+		copy(clauses, s.Clauses)
+		combined := make([]any, 0, len(c.Args)+len(args))
+		combined = append(append(combined, c.Args...), args...)
+		clauses[i] = SqlClause{
+			Type: ClauseWhere,
+			Expr: fmt.Sprintf("(%s) %s (%s)", c.Expr, op, expr),
+			Args: combined,
+		}
+		s.Clauses = clauses
+		return s
+	}
 	s.Clauses = append(s.Clauses, SqlClause{Type: ClauseWhere, Expr: expr, Args: args})
 	return s
 }
@@ -142,7 +226,7 @@ func (s SQLStatement) Values(values ...any) SQLStatement {
 
 	if val.IsValid() && val.Type() == first.ModelType && len(values) == 1 {
 		// Extract field values from the struct in the order of ColumnNames
-		extractedValues := extractFieldValues(val, first.ModelType, first.ColumnNames)
+		extractedValues := extractFieldValues(val, first.ModelType, first.ColumnNames, s.TagName, s.NameMapper)
 		s.Clauses = append(s.Clauses, SqlClause{Type: ClauseValues, Args: extractedValues})
 		return s
 	}
@@ -152,26 +236,18 @@ func (s SQLStatement) Values(values ...any) SQLStatement {
 	return s
 }
 
-func extractFieldValues(val reflect.Value, typ reflect.Type, columnNames []string) []any {
+func extractFieldValues(val reflect.Value, typ reflect.Type, columnNames []string, tagName string, mapper func(string) string) []any {
 	columns := make(map[string]struct{}, len(columnNames))
 	for _, c := range columnNames {
 		columns[c] = struct{}{}
 	}
 
 	args := make([]any, 0, len(columns))
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		if f.PkgPath != "" || f.Tag.Get(sqlstruct.TagName) == "-" {
+	for _, f := range modelFields(typ, tagName, mapper) {
+		if _, ok := columns[f.column]; !ok {
 			continue
 		}
-		tag := f.Tag.Get(sqlstruct.TagName)
-		if tag == "" {
-			tag = sqlstruct.ToSnakeCase(f.Name)
-		}
-		if _, ok := columns[tag]; !ok {
-			continue
-		}
-		args = append(args, val.Field(i).Interface())
+		args = append(args, val.FieldByIndex(f.index).Interface())
 	}
 	return args
 }
@@ -207,7 +283,9 @@ func Update[T any](opts *SqlOpts) SQLStatement {
 		typ = typ.Elem()
 	}
 
-	tableName := getTableName(sqlstruct.ToSnakeCase(typ.Name()), opts)
+	mapper := getNameMapper(opts)
+	tableName := getTableName(mapper(typ.Name()), opts)
+	tagName := getTagName(opts)
 
 	var names []string
 	var fieldFilter map[string]struct{}
@@ -218,24 +296,16 @@ func Update[T any](opts *SqlOpts) SQLStatement {
 		}
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		if f.PkgPath != "" {
+	for _, f := range modelFields(typ, tagName, mapper) {
+		if f.readonly {
 			continue
 		}
-		tag := f.Tag.Get(sqlstruct.TagName)
-		if tag == "-" {
-			continue
-		}
-		if tag == "" {
-			tag = sqlstruct.ToSnakeCase(f.Name)
-		}
 		if fieldFilter != nil {
-			if _, ok := fieldFilter[tag]; !ok {
+			if _, ok := fieldFilter[f.column]; !ok {
 				continue
 			}
 		}
-		names = append(names, tag)
+		names = append(names, f.column)
 	}
 
 	clause := SqlClause{
@@ -248,7 +318,22 @@ func Update[T any](opts *SqlOpts) SQLStatement {
 	if opts != nil && opts.Driver != nil {
 		driver = opts.Driver
 	}
-	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver}
+	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver, TagName: tagName, NameMapper: mapper}
+}
+
+func getTagName(opts *SqlOpts) string {
+	if opts != nil && opts.TagName != "" {
+		return opts.TagName
+	}
+	return DefaultTagName
+}
+
+// getNameMapper returns opts.NameMapper, defaulting to sqlstruct.ToSnakeCase
+func getNameMapper(opts *SqlOpts) func(string) string {
+	if opts != nil && opts.NameMapper != nil {
+		return opts.NameMapper
+	}
+	return sqlstruct.ToSnakeCase
 }
 
 func getTableName(def string, opts *SqlOpts) string {
@@ -262,8 +347,8 @@ func getTableName(def string, opts *SqlOpts) string {
 
 // Insert builds an INSERT statement for type T using the provided options.
 //
-// Fields are mapped to column names using the `db` struct tag; if absent, the
-// field name is converted to snake_case. The table name defaults to the struct
+// Fields are mapped to column names using the opts.TagName struct tag (`sql`
+// by default); if absent, the field name is converted to snake_case. The table name defaults to the struct
 // type name converted to snake_case when opts.TableName is empty. The reflected
 // type is stored in the resulting clause.
 func Insert[T any](opts *SqlOpts) SQLStatement {
@@ -272,7 +357,9 @@ func Insert[T any](opts *SqlOpts) SQLStatement {
 		typ = typ.Elem()
 	}
 
-	tableName := getTableName(sqlstruct.ToSnakeCase(typ.Name()), opts)
+	mapper := getNameMapper(opts)
+	tableName := getTableName(mapper(typ.Name()), opts)
+	tagName := getTagName(opts)
 
 	var names []string
 	var fieldFilter map[string]struct{}
@@ -283,25 +370,16 @@ func Insert[T any](opts *SqlOpts) SQLStatement {
 		}
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		// Skip unexported fields
-		if f.PkgPath != "" {
+	for _, f := range modelFields(typ, tagName, mapper) {
+		if f.readonly {
 			continue
 		}
-		tag := f.Tag.Get(sqlstruct.TagName)
-		if tag == "-" {
-			continue
-		}
-		if tag == "" {
-			tag = sqlstruct.ToSnakeCase(f.Name)
-		}
 		if fieldFilter != nil {
-			if _, ok := fieldFilter[tag]; !ok {
+			if _, ok := fieldFilter[f.column]; !ok {
 				continue
 			}
 		}
-		names = append(names, tag)
+		names = append(names, f.column)
 	}
 
 	clause := SqlClause{
@@ -314,7 +392,34 @@ func Insert[T any](opts *SqlOpts) SQLStatement {
 	if opts != nil && opts.Driver != nil {
 		driver = opts.Driver
 	}
-	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver}
+	omitZero := opts != nil && opts.OmitZero
+	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver, TagName: tagName, NameMapper: mapper, OmitZero: omitZero}
+}
+
+// InsertValues builds a single INSERT for type T with one VALUES row per
+// model, e.g. INSERT INTO user (id, name) VALUES (?, ?), (?, ?). Columns
+// follow the same rules as Insert, and Args returns every row's values in
+// order. Nil pointer models are skipped. Execute it with Exec and no models;
+// OmitZero does not apply because every row shares one column list.
+func InsertValues[T any](opts *SqlOpts, models []T) SQLStatement {
+	s := Insert[T](opts)
+	first := s.Clauses[0]
+
+	var args []any
+	rows := 0
+	for _, model := range models {
+		val := reflect.ValueOf(model)
+		for val.Kind() == reflect.Pointer {
+			val = val.Elem()
+		}
+		if !val.IsValid() {
+			continue
+		}
+		args = append(args, extractFieldValues(val, first.ModelType, first.ColumnNames, s.TagName, s.NameMapper)...)
+		rows++
+	}
+	s.Clauses = append(s.Clauses, SqlClause{Type: ClauseValues, Args: args, Rows: rows})
+	return s
 }
 
 // Select builds a SELECT statement listing all exported fields of type T.
@@ -327,7 +432,9 @@ func Select[T any](opts *SqlOpts) SQLStatement {
 		typ = typ.Elem()
 	}
 
-	tableName := getTableName(sqlstruct.ToSnakeCase(typ.Name()), opts)
+	mapper := getNameMapper(opts)
+	tableName := getTableName(mapper(typ.Name()), opts)
+	tagName := getTagName(opts)
 
 	var names []string
 	var fieldFilter map[string]struct{}
@@ -338,24 +445,13 @@ func Select[T any](opts *SqlOpts) SQLStatement {
 		}
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		if f.PkgPath != "" {
-			continue
-		}
-		tag := f.Tag.Get(sqlstruct.TagName)
-		if tag == "-" {
-			continue
-		}
-		if tag == "" {
-			tag = sqlstruct.ToSnakeCase(f.Name)
-		}
+	for _, f := range modelFields(typ, tagName, mapper) {
 		if fieldFilter != nil {
-			if _, ok := fieldFilter[tag]; !ok {
+			if _, ok := fieldFilter[f.column]; !ok {
 				continue
 			}
 		}
-		names = append(names, tag)
+		names = append(names, f.column)
 	}
 
 	clause := SqlClause{
@@ -368,7 +464,7 @@ func Select[T any](opts *SqlOpts) SQLStatement {
 	if opts != nil && opts.Driver != nil {
 		driver = opts.Driver
 	}
-	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver}
+	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver, TagName: tagName, NameMapper: mapper}
 }
 
 // Delete builds a DELETE statement for type T.
@@ -381,7 +477,9 @@ func Delete[T any](opts *SqlOpts) SQLStatement {
 		typ = typ.Elem()
 	}
 
-	tableName := getTableName(sqlstruct.ToSnakeCase(typ.Name()), opts)
+	mapper := getNameMapper(opts)
+	tableName := getTableName(mapper(typ.Name()), opts)
+	tagName := getTagName(opts)
 
 	clause := SqlClause{
 		Type:      ClauseDelete,
@@ -392,7 +490,7 @@ func Delete[T any](opts *SqlOpts) SQLStatement {
 	if opts != nil && opts.Driver != nil {
 		driver = opts.Driver
 	}
-	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver}
+	return SQLStatement{Clauses: []SqlClause{clause}, Driver: driver, TagName: tagName, NameMapper: mapper}
 }
 
 func renderClauses(stmt SQLStatement, driver Driver, renderer placeholderRenderer, argPosition int) (string, int, error) {
@@ -425,11 +523,23 @@ func renderClauses(stmt SQLStatement, driver Driver, renderer placeholderRendere
 				// We need to start from the position before the INSERT consumed them.
 				insertColumns := len(stmt.Clauses[i-1].ColumnNames)
 				valuesStartPos := argPosition - insertColumns
+				rows := c.Rows
+				if rows == 0 {
+					rows = 1
+				}
+				if len(c.Args) == 0 || len(c.Args)%rows != 0 {
+					return "", 0, fmt.Errorf("sqlcompose: VALUES needs at least one row of equal length")
+				}
+				width := len(c.Args) / rows
 				placeholdersList := make([]string, len(c.Args))
 				for j := range placeholdersList {
 					placeholdersList[j] = renderer.Placeholder(valuesStartPos + j)
 				}
-				parts[len(parts)-1] = insertClause[:idx] + fmt.Sprintf(" VALUES (%s)", strings.Join(placeholdersList, ", "))
+				tuples := make([]string, rows)
+				for r := range tuples {
+					tuples[r] = "(" + strings.Join(placeholdersList[r*width:(r+1)*width], ", ") + ")"
+				}
+				parts[len(parts)-1] = insertClause[:idx] + " VALUES " + strings.Join(tuples, ", ")
 				// Adjust the position and total: we're replacing insertColumns placeholders with len(c.Args) placeholders
 				argPosition = argPosition - insertColumns + len(placeholdersList)
 				usedTotal = usedTotal - insertColumns + len(placeholdersList)
@@ -512,7 +622,7 @@ func renderJoinClause(stmt SQLStatement, clause SqlClause, driver Driver, render
 		return "", 0, err
 	}
 
-	onExpr, usedOn := replacePlaceholders(clause.Expr, argPosition+usedInner, renderer)
+	onExpr, usedOn := replacePlaceholders(clause.Expr, clause.Args, argPosition+usedInner, renderer)
 
 	joinSQL := fmt.Sprintf("JOIN (%s) %s ON %s", innerSQL, clause.Identifier, onExpr)
 	return joinSQL, usedInner + usedOn, nil
@@ -116,6 +116,37 @@ func TestSelectWithFieldsOpt(t *testing.T) {
 	}
 }
 
+func TestReadonlyTagOption(t *testing.T) {
+	type User struct {
+		ID        int    `sql:"id,readonly"`
+		FirstName string `sql:"first_name"`
+	}
+
+	insert, err := Insert[User](nil).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT INTO user (first_name) VALUES (?);"; insert != expected {
+		t.Fatalf("expected %q, got %q", expected, insert)
+	}
+
+	update, err := Update[User](nil).Where("id=?", 1).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "UPDATE user SET first_name=? WHERE id=?;"; update != expected {
+		t.Fatalf("expected %q, got %q", expected, update)
+	}
+
+	sel, err := Select[User](nil).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT id, first_name FROM user;"; sel != expected {
+		t.Fatalf("expected %q, got %q", expected, sel)
+	}
+}
+
 func TestSelectWhere(t *testing.T) {
 	type User struct {
 		ID        int    `db:"id"`
@@ -133,6 +164,72 @@ func TestSelectWhere(t *testing.T) {
 	}
 }
 
+func TestSelectWhereCombines(t *testing.T) {
+	type User struct {
+		ID        int    `db:"id"`
+		FirstName string `db:"first_name"`
+	}
+
+	base := Select[User](&SqlOpts{Driver: PostgresDriver{}}).Where("id>?", 1)
+	stmt := base.Where("first_name=?", "bob").OrWhere("id IN (?)", []int{7, 8}).Limit(5)
+	expected := "SELECT id, first_name FROM user WHERE ((id>$1) AND (first_name=$2)) OR (id IN ($3, $4)) LIMIT $5"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	wantArgs := []any{1, "bob", 7, 8, 5}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+
+	// Combining must not modify the statement it was derived from.
+	if got, _ := base.Write(); got != "SELECT id, first_name FROM user WHERE id>$1" {
+		t.Fatalf("base statement changed: %s", got)
+	}
+}
+
+func TestSelectWhereEq(t *testing.T) {
+	type User struct {
+		ID       int    `db:"id"`
+		Status   string `db:"status"`
+		ClientID int    `db:"client_id"`
+	}
+
+	filters := map[string]any{"status": "active", "client_id": 1, "id": nil}
+	for i := 0; i < 3; i++ {
+		stmt := Select[User](&SqlOpts{Driver: PostgresDriver{}}).WhereEq(filters)
+		expected := "SELECT id, status, client_id FROM user WHERE client_id = $1 AND id IS NULL AND status = $2"
+		got, err := stmt.Write()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+		wantArgs := []any{1, "active"}
+		if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+			t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+		}
+	}
+}
+
+func TestSelectOrWhereWithoutWhere(t *testing.T) {
+	type User struct {
+		ID int `db:"id"`
+	}
+
+	got, err := Select[User](nil).OrWhere("id=?", 1).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT id FROM user WHERE id=?;"; got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
 func TestSelectOrderByDesc(t *testing.T) {
 	type User struct {
 		ID        int    `db:"id"`
@@ -346,6 +443,55 @@ func TestSnakeCase(t *testing.T) {
 	}
 }
 
+func TestAcronymSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"User":        "user",
+		"UserID":      "user_id",
+		"APIKey":      "api_key",
+		"HTTPServer":  "http_server",
+		"OAuthToken":  "o_auth_token",
+		"ServeHTTP":   "serve_http",
+		"already_low": "already_low",
+	}
+	for in, want := range cases {
+		if got := AcronymSnakeCase(in); got != want {
+			t.Fatalf("AcronymSnakeCase(%q)=%q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestNameMapper(t *testing.T) {
+	type APIClient struct {
+		ID         int `db:"id"`
+		APIKey     string
+		OAuthToken string
+	}
+
+	mapper := func(name string) string {
+		if name == "OAuthToken" {
+			return "oauth_token"
+		}
+		return AcronymSnakeCase(name)
+	}
+	opts := &SqlOpts{TagName: "db", NameMapper: mapper}
+
+	got, err := Insert[APIClient](opts).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "INSERT INTO api_client (id, api_key, oauth_token) VALUES (?, ?, ?);"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	got, err = Select[APIClient](&SqlOpts{TagName: "db"}).Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT id, apikey, oauth_token FROM apiclient;"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
 func TestInvalidClause(t *testing.T) {
 	stmt := SQLStatement{Clauses: []SqlClause{{Type: ClauseType("BAD")}}}
 	_, err := stmt.Write()
@@ -593,6 +739,62 @@ func TestInsertValuesReturning(t *testing.T) {
 	}
 }
 
+func TestInsertValuesMultiRow(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	users := []*User{{1, "Alice"}, nil, {2, "Bob"}, {3, "Carol"}}
+	stmt := InsertValues(&SqlOpts{Driver: PostgresDriver{}}, users).Returning("id")
+	expected := "INSERT INTO user (id, name) VALUES ($1, $2), ($3, $4), ($5, $6) RETURNING id"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("unexpected SQL: got %s, want %s", got, expected)
+	}
+
+	wantArgs := []any{1, "Alice", 2, "Bob", 3, "Carol"}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
+
+func TestInsertValuesFields(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+		Age  int    `db:"age"`
+	}
+
+	stmt := InsertValues(&SqlOpts{Fields: []string{"name", "age"}}, []User{{1, "Alice", 30}, {2, "Bob", 40}})
+	expected := "INSERT INTO user (name, age) VALUES (?, ?), (?, ?);"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("unexpected SQL: got %s, want %s", got, expected)
+	}
+
+	wantArgs := []any{"Alice", 30, "Bob", 40}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
+
+func TestInsertValuesEmpty(t *testing.T) {
+	type User struct {
+		ID int `db:"id"`
+	}
+
+	if _, err := InsertValues[User](nil, nil).Write(); err == nil {
+		t.Fatalf("expected error for INSERT without rows")
+	}
+}
+
 func TestValuesRequiresInsert(t *testing.T) {
 	type User struct {
 		ID int `db:"id"`
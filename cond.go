@@ -0,0 +1,101 @@
+package sqlcompose
+
+import "strings"
+
+// Cond is a composable WHERE predicate that renders to an expression with ?
+// placeholders and its arguments. Build leaves with Eq, Ne, Lt, Gt, In and
+// Like, combine them with And and Or, and pass the result to WhereCond.
+//
+// Column names are written into the SQL as-is, so they must not come from
+// user input; values are always bound as arguments.
+type Cond struct {
+	expr string
+	args []any
+	// compound is set for And/Or results with more than one operand, which
+	// are parenthesized when nested
+	compound bool
+}
+
+// SQL returns the rendered expression and its arguments in placeholder order.
+func (c Cond) SQL() (string, []any) {
+	return c.expr, c.args
+}
+
+// Eq renders column = ?, or column IS NULL when value is nil.
+func Eq(column string, value any) Cond {
+	if value == nil {
+		return Cond{expr: column + " IS NULL"}
+	}
+	return compare(column, "=", value)
+}
+
+// Ne renders column <> ?, or column IS NOT NULL when value is nil.
+func Ne(column string, value any) Cond {
+	if value == nil {
+		return Cond{expr: column + " IS NOT NULL"}
+	}
+	return compare(column, "<>", value)
+}
+
+// Lt renders column < ?.
+func Lt(column string, value any) Cond {
+	return compare(column, "<", value)
+}
+
+// Gt renders column > ?.
+func Gt(column string, value any) Cond {
+	return compare(column, ">", value)
+}
+
+// In renders column IN (?) with values bound as a slice, which Where expands
+// to one placeholder per element; an empty slice matches nothing.
+func In(column string, values any) Cond {
+	return Cond{expr: column + " IN (?)", args: []any{values}}
+}
+
+// Like renders column LIKE ?.
+func Like(column string, pattern string) Cond {
+	return compare(column, "LIKE", pattern)
+}
+
+func compare(column, op string, value any) Cond {
+	return Cond{expr: column + " " + op + " ?", args: []any{value}}
+}
+
+// And joins conds with AND, parenthesizing nested And/Or operands. With no
+// conds it renders 1=1.
+func And(conds ...Cond) Cond {
+	return combine("AND", "1=1", conds)
+}
+
+// Or joins conds with OR, parenthesizing nested And/Or operands. With no
+// conds it renders 1=0.
+func Or(conds ...Cond) Cond {
+	return combine("OR", "1=0", conds)
+}
+
+func combine(op, empty string, conds []Cond) Cond {
+	switch len(conds) {
+	case 0:
+		return Cond{expr: empty}
+	case 1:
+		return conds[0]
+	}
+	parts := make([]string, len(conds))
+	var args []any
+	for i, c := range conds {
+		parts[i] = c.expr
+		if c.compound {
+			parts[i] = "(" + c.expr + ")"
+		}
+		args = append(args, c.args...)
+	}
+	return Cond{expr: strings.Join(parts, " "+op+" "), args: args, compound: true}
+}
+
+// WhereCond adds c to the WHERE clause like Where, e.g.
+// WhereCond(Or(Eq("role", "admin"), Gt("age", 18))).
+func (s SQLStatement) WhereCond(c Cond) SQLStatement {
+	expr, args := c.SQL()
+	return s.Where(expr, args...)
+}
@@ -0,0 +1,65 @@
+package sqlcompose
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCondLeaves(t *testing.T) {
+	tests := []struct {
+		cond     Cond
+		wantSQL  string
+		wantArgs []any
+	}{
+		{Eq("name", "bob"), "name = ?", []any{"bob"}},
+		{Eq("deleted_at", nil), "deleted_at IS NULL", nil},
+		{Ne("name", "bob"), "name <> ?", []any{"bob"}},
+		{Ne("deleted_at", nil), "deleted_at IS NOT NULL", nil},
+		{Lt("age", 18), "age < ?", []any{18}},
+		{Gt("age", 65), "age > ?", []any{65}},
+		{Like("email", "%@example.com"), "email LIKE ?", []any{"%@example.com"}},
+		{And(), "1=1", nil},
+		{Or(), "1=0", nil},
+		{And(Eq("id", 1)), "id = ?", []any{1}},
+	}
+
+	for _, tt := range tests {
+		sql, args := tt.cond.SQL()
+		if sql != tt.wantSQL {
+			t.Errorf("expected %q, got %q", tt.wantSQL, sql)
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%s: expected args %v, got %v", tt.wantSQL, tt.wantArgs, args)
+		}
+	}
+}
+
+func TestWhereCondNested(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	cond := And(
+		Eq("active", true),
+		Or(In("id", []int{1, 2}), And(Gt("age", 18), Like("name", "a%"))),
+		Ne("role", "guest"),
+	)
+	stmt := Select[User](&SqlOpts{Driver: PostgresDriver{}}).
+		WhereCond(cond).
+		OrWhere("name=?", "root")
+
+	expected := "SELECT id, name FROM user WHERE (active = $1 AND (id IN ($2, $3) OR (age > $4 AND name LIKE $5)) AND role <> $6) OR (name=$7)"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	wantArgs := []any{true, 1, 2, 18, "a%", "guest", "root"}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
@@ -1,7 +1,10 @@
 package sqlcompose
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -48,7 +51,7 @@ func writeClause(clause SqlClause, argPosition int, placeholders placeholderRend
 	case ClauseDelete:
 		return fmt.Sprintf("DELETE FROM %s", clause.TableName), 0, nil
 	case ClauseWhere:
-		expr, count := replacePlaceholders(clause.Expr, argPosition, placeholders)
+		expr, count := replacePlaceholders(clause.Expr, clause.Args, argPosition, placeholders)
 		return fmt.Sprintf("WHERE %s", expr), count, nil
 	case ClauseOrderBy:
 		cols := strings.Join(clause.ColumnNames, ", ")
@@ -77,21 +80,118 @@ func writeClause(clause SqlClause, argPosition int, placeholders placeholderRend
 	}
 }
 
-func replacePlaceholders(expr string, argPosition int, placeholders placeholderRenderer) (string, int) {
+// replacePlaceholders renders each ? in expr as a driver placeholder. A ?
+// whose argument is a slice expands to one placeholder per element, so
+// Where("id IN (?)", []int{1, 2, 3}) renders id IN (?, ?, ?). An empty slice
+// turns "column IN (?)" into 1=0 and "column NOT IN (?)" into 1=1; anywhere
+// else it renders NULL. A ? inside a quoted literal or
+// identifier is left alone, as are the Postgres JSON operators ?| and ?&;
+// ?? is rendered as a single literal ? for the bare JSON ? operator.
+func replacePlaceholders(expr string, args []any, argPosition int, placeholders placeholderRenderer) (string, int) {
 	var b strings.Builder
 	count := 0
+	argIdx := 0
+	var quote byte
 	for i := 0; i < len(expr); i++ {
-		if expr[i] == '?' {
+		c := expr[i]
+		if quote != 0 || c != '?' {
+			// a doubled quote ('') closes and immediately reopens the literal
+			if quote == 0 && (c == '\'' || c == '"') {
+				quote = c
+			} else if c == quote {
+				quote = 0
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 < len(expr) && expr[i+1] == '?' {
+			b.WriteByte('?')
+			i++
+			continue
+		}
+		if isJSONOperator(expr, i) {
+			b.WriteByte(c)
+			continue
+		}
+		n := 1
+		if argIdx < len(args) {
+			if elems, ok := expandableSlice(args[argIdx]); ok {
+				n = elems.Len()
+			}
+		}
+		argIdx++
+		if n == 0 {
+			if loc := emptyInOperand.FindStringSubmatchIndex(b.String()); loc != nil && emptyInClose.MatchString(expr[i+1:]) {
+				written := b.String()
+				b.Reset()
+				b.WriteString(written[:loc[0]])
+				if loc[2] >= 0 {
+					b.WriteString("1=1")
+				} else {
+					b.WriteString("1=0")
+				}
+				i += len(emptyInClose.FindString(expr[i+1:]))
+				continue
+			}
+			b.WriteString("NULL")
+			continue
+		}
+		for j := 0; j < n; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
 			b.WriteString(placeholders.Placeholder(argPosition + count))
 			count++
+		}
+	}
+	return b.String(), count
+}
+
+var (
+	// emptyInOperand matches the "column [NOT] IN (" before a placeholder
+	emptyInOperand = regexp.MustCompile("(?i)[\\w.\"`]+\\s+(NOT\\s+)?IN\\s*\\(\\s*$")
+	// emptyInClose matches the ")" closing the IN list after a placeholder
+	emptyInClose = regexp.MustCompile(`^\s*\)`)
+)
+
+// isJSONOperator reports whether the ? at expr[i] starts a Postgres ?| or ?&
+// operator rather than a placeholder followed by || or &&.
+func isJSONOperator(expr string, i int) bool {
+	if i+1 >= len(expr) || (expr[i+1] != '|' && expr[i+1] != '&') {
+		return false
+	}
+	return i+2 >= len(expr) || expr[i+2] != expr[i+1]
+}
+
+// expandArgs flattens slice arguments to match the placeholders produced by
+// replacePlaceholders.
+func expandArgs(args []any) []any {
+	var out []any
+	for _, arg := range args {
+		elems, ok := expandableSlice(arg)
+		if !ok {
+			out = append(out, arg)
 			continue
 		}
-		b.WriteByte(expr[i])
+		for i := 0; i < elems.Len(); i++ {
+			out = append(out, elems.Index(i).Interface())
+		}
+	}
+	return out
+}
+
+// expandableSlice reports whether arg is a slice to expand into a list of
+// placeholders. []byte and driver.Valuer implementations are bound as a
+// single value.
+func expandableSlice(arg any) (reflect.Value, bool) {
+	if _, ok := arg.(driver.Valuer); ok {
+		return reflect.Value{}, false
 	}
-	if count == 0 {
-		return expr, 0
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return reflect.Value{}, false
 	}
-	return b.String(), count
+	return v, true
 }
 
 // DriverByName returns a Driver instance matching the provided name.
@@ -1,6 +1,9 @@
 package sqlcompose
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestDriverByNamePostgres(t *testing.T) {
 	d, _ := DriverByName("postgres")
@@ -8,3 +11,91 @@ func TestDriverByNamePostgres(t *testing.T) {
 		t.Fatalf("expected PostgresDriver, got %T", d)
 	}
 }
+
+func TestWhereExpandsSliceArgs(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	stmt := Select[User](&SqlOpts{Driver: PostgresDriver{}}).
+		Where("name=? AND id IN (?) AND name<>?", "bob", []int{1, 2, 3}, "eve").
+		Limit(1)
+	expected := "SELECT id, name FROM user WHERE name=$1 AND id IN ($2, $3, $4) AND name<>$5 LIMIT $6"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	wantArgs := []any{"bob", 1, 2, 3, "eve", 1}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
+
+func TestWhereSliceArgEdgeCases(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Data []byte `db:"data"`
+	}
+
+	stmt := Select[User](nil).Where("id IN (?) AND data=?", []int{}, []byte("raw"))
+	expected := "SELECT id, data FROM user WHERE 1=0 AND data=?;"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if args := stmt.Args(); len(args) != 1 || string(args[0].([]byte)) != "raw" {
+		t.Fatalf("expected []byte bound as one arg, got %v", args)
+	}
+}
+
+func TestWhereEmptySliceNotIn(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	stmt := Select[User](&SqlOpts{Driver: PostgresDriver{}}).
+		Where("user.id NOT IN ( ? ) AND name=?", []int{}, "bob").
+		Where("id in (?)", []string{})
+	expected := "SELECT id, name FROM user WHERE (1=1 AND name=$1) AND (1=0)"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if wantArgs := []any{"bob"}; !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
+
+func TestWhereSkipsLiteralQuestionMarks(t *testing.T) {
+	type Note struct {
+		ID   int    `db:"id"`
+		Note string `db:"note"`
+	}
+
+	stmt := Select[Note](&SqlOpts{Driver: PostgresDriver{}}).
+		Where("note LIKE '%?%' AND id=?", 7).
+		Where("tags ?| array['a', 'b?'] AND tags ?? ?", "c")
+	expected := "SELECT id, note FROM note WHERE (note LIKE '%?%' AND id=$1) AND (tags ?| array['a', 'b?'] AND tags ? $2)"
+	got, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	wantArgs := []any{7, "c"}
+	if !reflect.DeepEqual(stmt.Args(), wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, stmt.Args())
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
-
-	"github.com/kisielk/sqlstruct"
 )
 
 // Exec executes the INSERT, UPDATE, or DELETE statement against the provided database using
@@ -26,9 +24,27 @@ func Exec(db *sql.DB, stmt SQLStatement, models ...any) (sql.Result, error) {
 // statements, models are optional; if none are provided the statement is executed
 // once using only the arguments supplied in the builder (e.g., WHERE clause).
 //
+// For INSERT statements built with SqlOpts.OmitZero, each model's zero-valued
+// fields are left out of the column list so database defaults apply.
+//
 // If the statement contains a RETURNING clause, ExecContext returns an error
 // because Exec cannot retrieve returned values. Use Query instead.
 func ExecContext(ctx context.Context, db *sql.DB, stmt SQLStatement, models ...any) (sql.Result, error) {
+	return execContext(ctx, db, stmt, models...)
+}
+
+// ExecTx executes the statement inside an existing transaction. It behaves
+// like ExecContext; committing or rolling back tx is left to the caller.
+func ExecTx(ctx context.Context, tx *sql.Tx, stmt SQLStatement, models ...any) (sql.Result, error) {
+	return execContext(ctx, tx, stmt, models...)
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func execContext(ctx context.Context, db execer, stmt SQLStatement, models ...any) (sql.Result, error) {
 	if len(stmt.Clauses) == 0 {
 		return nil, fmt.Errorf("sqlcompose: Exec requires an INSERT, UPDATE, or DELETE clause")
 	}
@@ -73,24 +89,34 @@ func ExecContext(ctx context.Context, db *sql.DB, stmt SQLStatement, models ...a
 			return nil, fmt.Errorf("sqlcompose: model type %T does not match clause type %s", model, first.ModelType)
 		}
 
+		omitZero := stmt.OmitZero && first.Type == ClauseInsert
 		args := make([]any, 0, len(columns)+len(stmt.Args()))
-		for i := 0; i < first.ModelType.NumField(); i++ {
-			f := first.ModelType.Field(i)
-			if f.PkgPath != "" || f.Tag.Get(sqlstruct.TagName) == "-" {
+		var kept []string
+		for _, f := range modelFields(first.ModelType, stmt.TagName, stmt.NameMapper) {
+			if _, ok := columns[f.column]; !ok {
 				continue
 			}
-			tag := f.Tag.Get(sqlstruct.TagName)
-			if tag == "" {
-				tag = sqlstruct.ToSnakeCase(f.Name)
-			}
-			if _, ok := columns[tag]; !ok {
+			field := val.FieldByIndex(f.index)
+			if omitZero && field.IsZero() {
 				continue
 			}
-			args = append(args, val.Field(i).Interface())
+			kept = append(kept, f.column)
+			args = append(args, field.Interface())
 		}
 		args = append(args, stmt.Args()...)
 
-		r, err := db.ExecContext(ctx, sqlStmt, args...)
+		modelSQL := sqlStmt
+		if omitZero {
+			if len(kept) == 0 {
+				return res, fmt.Errorf("sqlcompose: OmitZero left no columns to insert for %T", model)
+			}
+			modelSQL, err = stmt.withInsertColumns(kept).Write()
+			if err != nil {
+				return res, err
+			}
+		}
+
+		r, err := db.ExecContext(ctx, modelSQL, args...)
 		if err != nil {
 			return r, err
 		}
@@ -99,3 +125,60 @@ func ExecContext(ctx context.Context, db *sql.DB, stmt SQLStatement, models ...a
 
 	return res, nil
 }
+
+// withInsertColumns returns a copy of the INSERT statement listing only columns.
+func (s SQLStatement) withInsertColumns(columns []string) SQLStatement {
+	clauses := make([]SqlClause, len(s.Clauses))
+	copy(clauses, s.Clauses)
+	clauses[0].ColumnNames = columns
+	s.Clauses = clauses
+	return s
+}
+
+// ExecReturning executes the INSERT, UPDATE, or DELETE statement with a
+// RETURNING clause using context.Background(). It delegates to
+// ExecReturningContext.
+func ExecReturning[T any](db *sql.DB, stmt SQLStatement) ([]T, error) {
+	return ExecReturningContext[T](context.Background(), db, stmt)
+}
+
+// ExecReturningContext executes the INSERT, UPDATE, or DELETE SQLStatement
+// against the provided database using the supplied context and scans every
+// row produced by its RETURNING clause into a T, matching struct fields by
+// the statement's tag name. It returns an error if the statement is not a DML statement or has
+// no RETURNING clause.
+func ExecReturningContext[T any](ctx context.Context, db *sql.DB, stmt SQLStatement) ([]T, error) {
+	if len(stmt.Clauses) == 0 {
+		return nil, fmt.Errorf("sqlcompose: ExecReturning requires an INSERT, UPDATE, or DELETE clause")
+	}
+
+	switch stmt.Clauses[0].Type {
+	case ClauseInsert, ClauseUpdate, ClauseDelete:
+	default:
+		return nil, fmt.Errorf("sqlcompose: ExecReturning requires an INSERT, UPDATE, or DELETE clause")
+	}
+
+	if !hasReturningClause(stmt) {
+		return nil, fmt.Errorf("sqlcompose: ExecReturning requires a RETURNING clause")
+	}
+
+	iter, err := QueryContext[T](ctx, db, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var results []T
+	for iter.Next() {
+		var result T
+		if err := iter.Scan(&result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
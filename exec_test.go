@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -45,6 +46,140 @@ func TestExec(t *testing.T) {
 	}
 }
 
+func TestExecTagName(t *testing.T) {
+	type User struct {
+		ID    int    `db:"user_id"`
+		Name  string `db:"login"`
+		Extra string `db:"-"`
+	}
+
+	stmt := Insert[User](&SqlOpts{TableName: "users", TagName: "db"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	sqlStr, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "INSERT INTO users (user_id, login) VALUES (?, ?);"; sqlStr != expected {
+		t.Fatalf("expected %q, got %q", expected, sqlStr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(sqlStr)).
+		WithArgs(1, "alice").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if _, err := Exec(db, stmt, User{ID: 1, Name: "alice", Extra: "ignored"}); err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExecOmitZero(t *testing.T) {
+	type Event struct {
+		ID        int       `sql:"id"`
+		Name      string    `sql:"name"`
+		CreatedAt time.Time `sql:"created_at"`
+	}
+
+	stmt := Insert[Event](&SqlOpts{OmitZero: true})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO event (name) VALUES (?);").
+		WithArgs("signup").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO event (name, created_at) VALUES (?, ?);").
+		WithArgs("login", created).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	if _, err := Exec(db, stmt, Event{Name: "signup"}, Event{Name: "login", CreatedAt: created}); err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	if _, err := Exec(db, stmt, Event{}); err == nil {
+		t.Fatalf("expected error when every field is zero")
+	}
+}
+
+type Base struct {
+	ID        int       `sql:"id,readonly"`
+	CreatedAt time.Time `sql:"created_at"`
+}
+
+type Invoice struct {
+	Base
+	Number string `sql:"number"`
+}
+
+func TestEmbeddedBaseColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt SQLStatement
+		want string
+	}{
+		{"insert", Insert[Invoice](nil), "INSERT INTO invoice (created_at, number) VALUES (?, ?);"},
+		{"select", Select[Invoice](nil), "SELECT id, created_at, number FROM invoice;"},
+		{"update", Update[Invoice](nil), "UPDATE invoice SET created_at=?, number=?;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.stmt.Write()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecEmbeddedBase(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO invoice (created_at, number) VALUES (?, ?);").
+		WithArgs(created, "INV-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, created_at, number FROM invoice;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "number"}).AddRow(1, created, "INV-1"))
+
+	if _, err := Exec(db, Insert[Invoice](nil), Invoice{Base: Base{ID: 9, CreatedAt: created}, Number: "INV-1"}); err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+	got, err := QueryOne[Invoice](db, Select[Invoice](nil))
+	if err != nil {
+		t.Fatalf("QueryOne returned error: %v", err)
+	}
+	if want := (Invoice{Base: Base{ID: 1, CreatedAt: created}, Number: "INV-1"}); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestExecPointer(t *testing.T) {
 	type User struct {
 		ID   int    `db:"id"`
@@ -151,6 +286,119 @@ func TestExecContext(t *testing.T) {
 	}
 }
 
+func TestExecInsertValues(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	stmt := InsertValues(nil, []User{{1, "Alice"}, {2, "Bob"}})
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user (id, name) VALUES (?, ?), (?, ?);")).
+		WithArgs(1, "Alice", 2, "Bob").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+
+	res, err := Exec(db, stmt)
+	if err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("expected 2 rows affected, got %d", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExecTx(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	insert := Insert[User](nil)
+	insertSQL, err := insert.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSQL)).
+		WithArgs(1, "Alice").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertSQL)).
+		WithArgs(2, "Bob").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if _, err := ExecTx(ctx, tx, insert, User{1, "Alice"}, User{2, "Bob"}); err != nil {
+		t.Fatalf("ExecTx returned error: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExecTxRollback(t *testing.T) {
+	type User struct {
+		ID int `db:"id"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	stmt := Delete[User](nil).Where("id=?", 7)
+	sqlStr, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failure := errors.New("boom")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlStr)).WithArgs(7).WillReturnError(failure)
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if _, err := ExecTx(ctx, tx, stmt); !errors.Is(err, failure) {
+		t.Fatalf("expected %v, got %v", failure, err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestExecReturning(t *testing.T) {
 	type User struct {
 		ID   int    `db:"id"`
@@ -583,3 +831,58 @@ func TestUpdateWithModelWorks(t *testing.T) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+func TestExecReturningDelete(t *testing.T) {
+	type User struct {
+		ID   int    `sql:"id"`
+		Name string `sql:"name"`
+	}
+
+	stmt := Delete[User](nil).Where("name=?", "Alice").Returning("*")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	sqlStr, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice").AddRow(4, "Alice")
+	mock.ExpectQuery(regexp.QuoteMeta(sqlStr)).WithArgs("Alice").WillReturnRows(rows)
+
+	users, err := ExecReturning[User](db, stmt)
+	if err != nil {
+		t.Fatalf("ExecReturning returned error: %v", err)
+	}
+	expected := []User{{ID: 1, Name: "Alice"}, {ID: 4, Name: "Alice"}}
+	if !reflect.DeepEqual(users, expected) {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExecReturningRequiresReturning(t *testing.T) {
+	type User struct {
+		ID int `sql:"id"`
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := ExecReturning[User](db, Delete[User](nil).Where("id=?", 1)); err == nil {
+		t.Fatalf("expected error without RETURNING clause")
+	}
+	if _, err := ExecReturning[User](db, Select[User](nil).Returning("id")); err == nil {
+		t.Fatalf("expected error for SELECT statement")
+	}
+}
@@ -0,0 +1,127 @@
+package sqlcompose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DeleteByPK builds a DELETE for type T matching model's primary key, e.g.
+// DELETE FROM user WHERE id = ?. The key is the fields tagged pk in the
+// statement's tag, e.g. `sql:"id,pk"`; a composite key tags several and
+// renders WHERE a = ? AND b = ?. Execute it with Exec and no models.
+func DeleteByPK[T any](opts *SqlOpts, model T) (SQLStatement, error) {
+	s := Delete[T](opts)
+	return s.wherePK(model)
+}
+
+// UpdateByPK builds an UPDATE for type T setting model's columns and
+// matching its primary key, e.g. UPDATE user SET name=? WHERE id = ?.
+// Columns follow the same rules as Update; key columns are never set. The
+// key is identified as for DeleteByPK. Execute it with Exec and no models.
+func UpdateByPK[T any](opts *SqlOpts, model T) (SQLStatement, error) {
+	s := Update[T](opts)
+	pk, err := primaryKeyFields(s.Clauses[0].ModelType, s.TagName, s.NameMapper)
+	if err != nil {
+		return SQLStatement{}, err
+	}
+	keys := make(map[string]struct{}, len(pk))
+	for _, f := range pk {
+		keys[f.column] = struct{}{}
+	}
+	var names []string
+	for _, name := range s.Clauses[0].ColumnNames {
+		if _, ok := keys[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	s.Clauses[0].ColumnNames = names
+	if len(names) == 0 {
+		return SQLStatement{}, fmt.Errorf("sqlcompose: UpdateByPK has no columns to set besides the primary key of %s", s.Clauses[0].ModelType)
+	}
+
+	val, err := modelValue(s.Clauses[0].ModelType, model)
+	if err != nil {
+		return SQLStatement{}, err
+	}
+	return s.Values(val.Interface()).wherePK(model)
+}
+
+// GetByPK selects the row of type T whose primary key equals keys, e.g.
+// SELECT id, title FROM article WHERE id = ?, using context.Background().
+// It delegates to GetByPKContext.
+func GetByPK[T any](db *sql.DB, opts *SqlOpts, keys ...any) (T, error) {
+	return GetByPKContext[T](context.Background(), db, opts, keys...)
+}
+
+// GetByPKContext selects the row of type T whose primary key equals keys,
+// given in the order of T's pk-tagged fields, and returns it like
+// QueryOneContext: sql.ErrNoRows when no row matches.
+func GetByPKContext[T any](ctx context.Context, db *sql.DB, opts *SqlOpts, keys ...any) (T, error) {
+	var zero T
+	s := Select[T](opts)
+	pk, err := primaryKeyFields(s.Clauses[0].ModelType, s.TagName, s.NameMapper)
+	if err != nil {
+		return zero, err
+	}
+	if len(keys) != len(pk) {
+		return zero, fmt.Errorf("sqlcompose: GetByPK for %s needs %d key values, got %d", s.Clauses[0].ModelType, len(pk), len(keys))
+	}
+	return QueryOneContext[T](ctx, db, s.whereKeys(pk, keys))
+}
+
+// wherePK adds WHERE pk = ? with the primary key value of model, one
+// condition per key column in field order.
+func (s SQLStatement) wherePK(model any) (SQLStatement, error) {
+	typ := s.Clauses[0].ModelType
+	pk, err := primaryKeyFields(typ, s.TagName, s.NameMapper)
+	if err != nil {
+		return SQLStatement{}, err
+	}
+	val, err := modelValue(typ, model)
+	if err != nil {
+		return SQLStatement{}, err
+	}
+	keys := make([]any, len(pk))
+	for i, f := range pk {
+		keys[i] = val.FieldByIndex(f.index).Interface()
+	}
+	return s.whereKeys(pk, keys), nil
+}
+
+// whereKeys adds WHERE a = ? AND b = ? binding keys to the pk columns.
+func (s SQLStatement) whereKeys(pk []modelField, keys []any) SQLStatement {
+	conds := make([]string, len(pk))
+	for i, f := range pk {
+		conds[i] = f.column + " = ?"
+	}
+	return s.Where(strings.Join(conds, " AND "), keys...)
+}
+
+// primaryKeyFields returns the fields of typ tagged pk in declaration order.
+func primaryKeyFields(typ reflect.Type, tagName string, mapper func(string) string) ([]modelField, error) {
+	var pk []modelField
+	for _, f := range modelFields(typ, tagName, mapper) {
+		if f.pk {
+			pk = append(pk, f)
+		}
+	}
+	if len(pk) == 0 {
+		return nil, fmt.Errorf("sqlcompose: %s has no field tagged pk", typ)
+	}
+	return pk, nil
+}
+
+// modelValue dereferences model, which must be a non-nil value of typ.
+func modelValue(typ reflect.Type, model any) (reflect.Value, error) {
+	val := reflect.ValueOf(model)
+	for val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+	if !val.IsValid() || val.Type() != typ {
+		return reflect.Value{}, fmt.Errorf("sqlcompose: model type %T does not match clause type %s", model, typ)
+	}
+	return val, nil
+}
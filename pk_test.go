@@ -0,0 +1,184 @@
+package sqlcompose
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type Article struct {
+	ID    int    `sql:"id,pk"`
+	Title string `sql:"title"`
+	Body  string `sql:"body"`
+}
+
+func TestDeleteByPK(t *testing.T) {
+	stmt, err := DeleteByPK[Article](nil, Article{ID: 7, Title: "ignored"})
+	if err != nil {
+		t.Fatalf("DeleteByPK: %v", err)
+	}
+	sql, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := "DELETE FROM article WHERE id = ?;"; sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectExec(regexp.QuoteMeta(sql)).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := Exec(db, stmt); err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateByPK(t *testing.T) {
+	article := &Article{ID: 7, Title: "Hello", Body: "World"}
+	stmt, err := UpdateByPK(&SqlOpts{Driver: PostgresDriver{}}, article)
+	if err != nil {
+		t.Fatalf("UpdateByPK: %v", err)
+	}
+	sql, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := "UPDATE article SET title=$1, body=$2 WHERE id = $3"; sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+	if want := []any{"Hello", "World", 7}; !reflect.DeepEqual(stmt.Args(), want) {
+		t.Fatalf("expected args %v, got %v", want, stmt.Args())
+	}
+}
+
+func TestByPKCompositeKey(t *testing.T) {
+	type UserRole struct {
+		UserID    int    `sql:"user_id,pk"`
+		RoleID    int    `sql:"role_id,pk"`
+		GrantedBy string `sql:"granted_by"`
+	}
+	link := UserRole{UserID: 1, RoleID: 2, GrantedBy: "admin"}
+
+	del, err := DeleteByPK(nil, link)
+	if err != nil {
+		t.Fatalf("DeleteByPK: %v", err)
+	}
+	sql, err := del.Write()
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := "DELETE FROM user_role WHERE user_id = ? AND role_id = ?;"; sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+	if want := []any{1, 2}; !reflect.DeepEqual(del.Args(), want) {
+		t.Fatalf("expected args %v, got %v", want, del.Args())
+	}
+
+	upd, err := UpdateByPK(nil, link)
+	if err != nil {
+		t.Fatalf("UpdateByPK: %v", err)
+	}
+	sql, err = upd.Write()
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := "UPDATE user_role SET granted_by=? WHERE user_id = ? AND role_id = ?;"; sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+	if want := []any{"admin", 1, 2}; !reflect.DeepEqual(upd.Args(), want) {
+		t.Fatalf("expected args %v, got %v", want, upd.Args())
+	}
+}
+
+func TestGetByPK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, body FROM article WHERE id = ?;")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body"}).AddRow(7, "Hello", "World"))
+	got, err := GetByPK[Article](db, nil, 7)
+	if err != nil {
+		t.Fatalf("GetByPK: %v", err)
+	}
+	if want := (Article{ID: 7, Title: "Hello", Body: "World"}); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, body FROM article WHERE id = ?;")).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body"}))
+	if _, err := GetByPK[Article](db, nil, 8); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetByPKCompositeKey(t *testing.T) {
+	type UserRole struct {
+		UserID    int    `sql:"user_id,pk"`
+		RoleID    int    `sql:"role_id,pk"`
+		GrantedBy string `sql:"granted_by"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT user_id, role_id, granted_by FROM user_role WHERE user_id = ? AND role_id = ?;")).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "granted_by"}).AddRow(1, 2, "admin"))
+	got, err := GetByPK[UserRole](db, nil, 1, 2)
+	if err != nil {
+		t.Fatalf("GetByPK: %v", err)
+	}
+	if want := (UserRole{UserID: 1, RoleID: 2, GrantedBy: "admin"}); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	if _, err := GetByPK[UserRole](db, nil, 1); err == nil || !strings.Contains(err.Error(), "needs 2 key values, got 1") {
+		t.Fatalf("expected key count error, got %v", err)
+	}
+}
+
+func TestByPKRequiresPKField(t *testing.T) {
+	type NoKey struct {
+		ID   int    `sql:"id"`
+		Name string `sql:"name"`
+	}
+	type OnlyKeys struct {
+		A int `sql:"a,pk"`
+		B int `sql:"b,pk"`
+	}
+
+	if _, err := DeleteByPK(nil, NoKey{ID: 1}); err == nil || !strings.Contains(err.Error(), "has no field tagged pk") {
+		t.Fatalf("expected missing pk error, got %v", err)
+	}
+	if _, err := UpdateByPK(nil, NoKey{ID: 1}); err == nil || !strings.Contains(err.Error(), "has no field tagged pk") {
+		t.Fatalf("expected missing pk error, got %v", err)
+	}
+	if _, err := UpdateByPK(nil, OnlyKeys{A: 1, B: 2}); err == nil || !strings.Contains(err.Error(), "no columns to set") {
+		t.Fatalf("expected no columns error, got %v", err)
+	}
+}
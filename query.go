@@ -5,8 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
-
-	"github.com/kisielk/sqlstruct"
 )
 
 func Query[T any](db *sql.DB, stmt SQLStatement) (*QueryRowIterator[T], error) {
@@ -15,9 +13,12 @@ func Query[T any](db *sql.DB, stmt SQLStatement) (*QueryRowIterator[T], error) {
 
 // QueryRowIterator allows for iterating over the results of a query one by one.
 type QueryRowIterator[T any] struct {
-	rows  *sql.Rows
-	isPtr bool
-	model reflect.Type
+	rows    *sql.Rows
+	isPtr   bool
+	model   reflect.Type
+	tagName string
+	// nameMapper maps untagged field names to columns; nil is snake_case
+	nameMapper func(string) string
 }
 
 // Next prepares the next result row for reading.
@@ -32,10 +33,10 @@ func (iter *QueryRowIterator[T]) Err() error {
 
 // Scan scans the current row into the given destination.
 func (iter *QueryRowIterator[T]) Scan(dest *T) error {
-	// Check if T is a struct type that needs sqlstruct.Scan
+	// Struct types are scanned field by field using the statement's tag name
 	if iter.model.Kind() == reflect.Struct {
 		pv := reflect.New(iter.model)
-		if err := sqlstruct.Scan(pv.Interface(), iter.rows); err != nil {
+		if err := scanStruct(pv.Interface(), iter.rows, iter.tagName, iter.nameMapper); err != nil {
 			return err
 		}
 		if iter.isPtr {
@@ -58,6 +59,21 @@ func (iter *QueryRowIterator[T]) Close() error {
 // QueryContext executes the SELECT SQLStatement against the provided database
 // and returns a QueryRowIterator so the caller can iterate over the results.
 func QueryContext[T any](ctx context.Context, db *sql.DB, stmt SQLStatement) (*QueryRowIterator[T], error) {
+	return queryContext[T](ctx, db, stmt)
+}
+
+// QueryTx executes the statement inside an existing transaction. It behaves
+// like QueryContext; the iterator must be closed before tx is committed.
+func QueryTx[T any](ctx context.Context, tx *sql.Tx, stmt SQLStatement) (*QueryRowIterator[T], error) {
+	return queryContext[T](ctx, tx, stmt)
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryContext[T any](ctx context.Context, db queryer, stmt SQLStatement) (*QueryRowIterator[T], error) {
 	errNeedsRowSet := fmt.Errorf("sqlcompose: Query requires a SELECT clause or a RETURNING clause")
 	if len(stmt.Clauses) == 0 {
 		return nil, errNeedsRowSet
@@ -91,9 +107,11 @@ func QueryContext[T any](ctx context.Context, db *sql.DB, stmt SQLStatement) (*Q
 	}
 
 	return &QueryRowIterator[T]{
-		rows:  rows,
-		isPtr: isPtr,
-		model: typ,
+		rows:       rows,
+		isPtr:      isPtr,
+		model:      typ,
+		tagName:    stmt.TagName,
+		nameMapper: stmt.NameMapper,
 	}, nil
 }
 
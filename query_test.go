@@ -60,6 +60,152 @@ func TestQuery(t *testing.T) {
 	}
 }
 
+func TestQueryTx(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	stmt := Select[User](nil).Where("id=?", 1)
+	sqlStr, err := stmt.Write()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlStr)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice"))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+
+	iter, err := QueryTx[User](ctx, tx, stmt)
+	if err != nil {
+		t.Fatalf("QueryTx returned error: %v", err)
+	}
+	var got []User
+	for iter.Next() {
+		var u User
+		if err := iter.Scan(&u); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		got = append(got, u)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("iteration error: %v", err)
+	}
+	if err := iter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	want := []User{{1, "Alice"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestQueryTagName(t *testing.T) {
+	type SQLUser struct {
+		ID   int    `sql:"user_id"`
+		Name string `sql:"login"`
+	}
+	type DBUser struct {
+		ID   int    `db:"user_id"`
+		Name string `db:"login"`
+	}
+
+	tests := []struct {
+		name string
+		stmt SQLStatement
+		want any
+	}{
+		{
+			name: "sql",
+			stmt: Select[SQLUser](&SqlOpts{TableName: "users"}),
+			want: SQLUser{ID: 1, Name: "alice"},
+		},
+		{
+			name: "db",
+			stmt: Select[DBUser](&SqlOpts{TableName: "users", TagName: "db"}),
+			want: DBUser{ID: 1, Name: "alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("sqlmock.New: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery("SELECT user_id, login FROM users;").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "login"}).AddRow(1, "alice"))
+
+			var got any
+			switch tt.want.(type) {
+			case SQLUser:
+				got, err = QueryOne[SQLUser](db, tt.stmt)
+			case DBUser:
+				got, err = QueryOne[DBUser](db, tt.stmt)
+			}
+			if err != nil {
+				t.Fatalf("QueryOne returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestQueryNameMapper(t *testing.T) {
+	type APIClient struct {
+		ID     int `sql:"id"`
+		APIKey string
+	}
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, api_key FROM api_client;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "api_key"}).AddRow(1, "k-1"))
+
+	got, err := QueryOne[APIClient](db, Select[APIClient](&SqlOpts{NameMapper: AcronymSnakeCase}))
+	if err != nil {
+		t.Fatalf("QueryOne returned error: %v", err)
+	}
+	if want := (APIClient{ID: 1, APIKey: "k-1"}); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestQueryWhereArgs(t *testing.T) {
 	type User struct {
 		ID        int    `sql:"id"`
@@ -1,11 +1,170 @@
 package sqlcompose
 
-import "github.com/kisielk/sqlstruct"
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/kisielk/sqlstruct"
+)
+
+// DefaultTagName is the struct tag read for column names when
+// SqlOpts.TagName is empty.
+const DefaultTagName = "sql"
 
 // The init function sets up the sqlstruct package with custom configurations.
 // It changes the struct field tag used for SQL mapping to "sql" and modifies
 // the default name mapping function to convert struct field names to snake_case.
 func init() {
-	sqlstruct.TagName = "sql"
+	sqlstruct.TagName = DefaultTagName
 	sqlstruct.NameMapper = sqlstruct.ToSnakeCase
 }
+
+// AcronymSnakeCase converts a Go name to snake_case, splitting acronyms
+// from the following word: APIKey -> api_key, HTTPServer -> http_server.
+// Set it as SqlOpts.NameMapper, or wrap it to override individual names.
+func AcronymSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) && prev != '_' || unicode.IsUpper(prev) && nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// fieldColumn returns the column name for struct field f using the tagName
+// tag, falling back to the field name converted by mapper (snake_case when
+// nil). Options after a comma,
+// e.g. `sql:"created_at,readonly"`, are ignored. It reports false for
+// unexported fields and fields tagged "-".
+func fieldColumn(f reflect.StructField, tagName string, mapper func(string) string) (string, bool) {
+	if tagName == "" {
+		tagName = DefaultTagName
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get(tagName), ",")
+	if f.PkgPath != "" || tag == "-" {
+		return "", false
+	}
+	if tag == "" {
+		if mapper == nil {
+			mapper = sqlstruct.ToSnakeCase
+		}
+		tag = mapper(f.Name)
+	}
+	return tag, true
+}
+
+// fieldReadonly reports whether f carries the readonly tag option: it is
+// selected and scanned but left out of INSERT and UPDATE column lists.
+func fieldReadonly(f reflect.StructField, tagName string) bool {
+	return fieldHasOption(f, tagName, "readonly")
+}
+
+// fieldPK reports whether f carries the pk tag option, e.g. `sql:"id,pk"`,
+// marking the primary key used by DeleteByPK and UpdateByPK.
+func fieldPK(f reflect.StructField, tagName string) bool {
+	return fieldHasOption(f, tagName, "pk")
+}
+
+// fieldHasOption reports whether the tagName tag of f lists option after
+// the column name.
+func fieldHasOption(f reflect.StructField, tagName, option string) bool {
+	if tagName == "" {
+		tagName = DefaultTagName
+	}
+	_, opts, _ := strings.Cut(f.Tag.Get(tagName), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// modelField is a column-mapped field of a model struct.
+type modelField struct {
+	column   string
+	index    []int
+	readonly bool
+	pk       bool
+}
+
+// modelFields lists the column-mapped fields of struct type typ in
+// declaration order, flattening embedded structs like fieldIndexes, so an
+// embedded Base{ID, CreatedAt} contributes its columns in place.
+func modelFields(typ reflect.Type, tagName string, mapper func(string) string) []modelField {
+	var out []modelField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.PkgPath == "" {
+			for _, inner := range modelFields(f.Type, tagName, mapper) {
+				inner.index = append([]int{i}, inner.index...)
+				out = append(out, inner)
+			}
+			continue
+		}
+		name, ok := fieldColumn(f, tagName, mapper)
+		if !ok {
+			continue
+		}
+		out = append(out, modelField{column: name, index: []int{i}, readonly: fieldReadonly(f, tagName), pk: fieldPK(f, tagName)})
+	}
+	return out
+}
+
+// fieldIndexes maps column names to field indexes of struct type typ,
+// flattening embedded structs the way sqlstruct does.
+func fieldIndexes(typ reflect.Type, tagName string, mapper func(string) string) map[string][]int {
+	out := make(map[string][]int)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.PkgPath == "" {
+			for name, idx := range fieldIndexes(f.Type, tagName, mapper) {
+				out[name] = append([]int{i}, idx...)
+			}
+			continue
+		}
+		name, ok := fieldColumn(f, tagName, mapper)
+		if !ok {
+			continue
+		}
+		out[strings.ToLower(name)] = []int{i}
+	}
+	return out
+}
+
+// scanStruct scans the current row into dest, a pointer to a struct, matching
+// columns to fields by their tagName tag or mapped field name. Columns without a matching field are
+// discarded.
+func scanStruct(dest any, rows *sql.Rows, tagName string, mapper func(string) string) error {
+	destv := reflect.ValueOf(dest)
+	if destv.Kind() != reflect.Pointer || destv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("sqlcompose: scan destination must be a pointer to a struct, got %T", dest)
+	}
+	elem := destv.Elem()
+	fields := fieldIndexes(elem.Type(), tagName, mapper)
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(cols))
+	for i, name := range cols {
+		idx, ok := fields[strings.ToLower(name)]
+		if !ok {
+			values[i] = &sql.RawBytes{}
+			continue
+		}
+		values[i] = elem.FieldByIndex(idx).Addr().Interface()
+	}
+	return rows.Scan(values...)
+}
@@ -1,8 +1,51 @@
 package builder
 
+import "github.com/guadalsistema/go-compose-sql/v2/dialect"
+
 // Builder is the interface that all query builders must implement.
 // It provides a method to generate SQL queries with their arguments.
 type Builder interface {
 	// ToSQL generates the SQL query string and arguments
 	ToSQL() (string, []interface{}, error)
 }
+
+// dialectConn is a connection rendering for another dialect. DebugSQL binds
+// a copy of the builder to it so the output matches what that dialect would
+// execute (LIMIT forms, ILIKE fallbacks), keeping the original connection's
+// settings such as identifier quoting.
+type dialectConn struct {
+	ConnectionInterface
+	dialect dialect.Dialect
+}
+
+// withDialect returns conn rendering for d; a nil d keeps conn unchanged
+func withDialect(conn ConnectionInterface, d dialect.Dialect) ConnectionInterface {
+	if d == nil {
+		return conn
+	}
+	return dialectConn{ConnectionInterface: conn, dialect: d}
+}
+
+func (c dialectConn) Dialect() dialect.Dialect {
+	return c.dialect
+}
+
+func (c dialectConn) QuoteIdentifiers() bool {
+	return c.ConnectionInterface != nil && c.ConnectionInterface.QuoteIdentifiers()
+}
+
+// debugSQL returns the statement as it would be sent to the driver: SQL with
+// dialect placeholders and unwrapped (unredacted) args. b must already be
+// bound to d (see withDialect). Builder errors yield empty SQL; ToSQL
+// reports them.
+func debugSQL(b Builder, d dialect.Dialect) (string, []interface{}) {
+	sql, args, err := b.ToSQL()
+	if err != nil {
+		return "", nil
+	}
+	args, _ = redactArgs(args)
+	if d == nil {
+		return sql, args
+	}
+	return FormatPlaceholders(sql, d), args
+}
@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type UsersColumns struct {
+	ID        *table.Column[int64]
+	Name      *table.Column[string]
+	Email     *table.Column[string]
+	Age       *table.Column[int]
+	DeletedAt *table.Column[sql.NullTime]
+}
+
+type User struct {
+	ID    int64  `sql:"id"`
+	Name  string `sql:"name"`
+	Email string `sql:"email"`
+	Age   int    `sql:"age"`
+}
+
+func newUsersTable() *table.Table[UsersColumns] {
+	return table.NewTable("users", UsersColumns{
+		ID:    table.Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Name:  table.Col[string]("name").NotNull(),
+		Email: table.Col[string]("email").Unique(),
+		Age:   table.Col[int]("age"),
+	})
+}
+
+func newSoftDeleteUsersTable() *table.Table[UsersColumns] {
+	return table.NewTable("users", UsersColumns{
+		ID:        table.Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Name:      table.Col[string]("name").NotNull(),
+		DeletedAt: table.Col[sql.NullTime]("deleted_at").SoftDelete(),
+	})
+}
+
+// testConn implements ConnectionInterface on top of a sqlmock database.
+type testConn struct {
+	db       *sql.DB
+	dialect  dialect.Dialect
+	timeout  time.Duration
+	logger   *slog.Logger
+	observer QueryObserver
+	redact   func([]any) []any
+	tracer   Tracer
+	quote    bool
+	lenient  bool
+	slow     time.Duration
+	explain  bool
+	cache    ResultCache
+}
+
+func newTestConn(t *testing.T, d dialect.Dialect) (*testConn, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return &testConn{db: db, dialect: d}, mock
+}
+
+func (c *testConn) Dialect() dialect.Dialect          { return c.dialect }
+func (c *testConn) Logger() *slog.Logger              { return c.logger }
+func (c *testConn) Context() context.Context          { return context.Background() }
+func (c *testConn) QueryTimeout() time.Duration       { return c.timeout }
+func (c *testConn) LogLevel() slog.Level              { return slog.LevelInfo }
+func (c *testConn) QueryObserver() QueryObserver      { return c.observer }
+func (c *testConn) RedactArgs() func([]any) []any     { return c.redact }
+func (c *testConn) Tracer() Tracer                    { return c.tracer }
+func (c *testConn) QuoteIdentifiers() bool            { return c.quote }
+func (c *testConn) LenientScan() bool                 { return c.lenient }
+func (c *testConn) SlowQueryThreshold() time.Duration { return c.slow }
+func (c *testConn) ExplainSlowQueries() bool          { return c.explain }
+func (c *testConn) ResultCache() ResultCache          { return c.cache }
+func (c *testConn) ExecuteContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.db.ExecContext(ctx, query, args...)
+}
+func (c *testConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+func (c *testConn) QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, query, args...)
+}
+func (c *testConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.db.PrepareContext(ctx, query)
+}
@@ -0,0 +1,148 @@
+package builder
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResultCache stores the scanned results of SelectBuilder.All, keyed by the
+// destination type, SQL and args. Values are slices owned by the cache;
+// implementations must be safe for concurrent use.
+type ResultCache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any)
+}
+
+// TTLCache is a ResultCache holding at most size entries, least recently
+// used first out, each expiring ttl after it was stored
+type TTLCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List // front = most recently used
+	items map[string]*list.Element
+}
+
+type ttlCacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// NewTTLCache returns an empty TTLCache
+func NewTTLCache(size int, ttl time.Duration) *TTLCache {
+	return &TTLCache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key unless it has expired
+func (c *TTLCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*ttlCacheEntry)
+	if !now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entries
+// beyond the size limit
+func (c *TTLCache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*ttlCacheEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&ttlCacheEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*ttlCacheEntry).key)
+	}
+}
+
+// Purge drops every entry, e.g. after writes the cached queries read
+func (c *TTLCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Len returns the number of stored entries, expired ones included
+func (c *TTLCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// cachedAll is queryAll through cache: a hit copies the stored slice into
+// dest without touching the database, a miss runs the query and stores a
+// copy of the result
+func cachedAll(ctx context.Context, conn ConnectionInterface, b Builder, cache ResultCache, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return queryAll(ctx, conn, b, dest)
+	}
+	sql, args, err := b.ToSQL()
+	if err != nil {
+		return err
+	}
+	slice := rv.Elem()
+	key := resultCacheKey(slice.Type(), sql, args)
+
+	if cached, ok := cache.Get(key); ok {
+		if v := reflect.ValueOf(cached); v.Type() == slice.Type() {
+			slice.Set(copySlice(v))
+			return nil
+		}
+	}
+	if err := queryAll(ctx, conn, b, dest); err != nil {
+		return err
+	}
+	cache.Set(key, copySlice(slice).Interface())
+	return nil
+}
+
+// resultCacheKey identifies a result by destination type, SQL and args
+func resultCacheKey(typ reflect.Type, sql string, args []interface{}) string {
+	var key strings.Builder
+	key.WriteString(typ.String())
+	key.WriteByte(0)
+	key.WriteString(sql)
+	for _, arg := range args {
+		fmt.Fprintf(&key, "\x00%T:%v", arg, arg)
+	}
+	return key.String()
+}
+
+// copySlice returns a shallow copy of the slice v, so callers appending to
+// or reassigning elements of their result don't change the cached one
+func copySlice(v reflect.Value) reflect.Value {
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(out, v)
+	return out
+}
@@ -0,0 +1,118 @@
+package builder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+)
+
+func TestSelectAllResultCache(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.cache = NewTTLCache(10, time.Minute)
+
+	mock.ExpectQuery("SELECT id, name FROM users WHERE users.age > $1").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(int64(1), "john").
+			AddRow(int64(2), "jane"))
+
+	query := func(age int) []User {
+		t.Helper()
+		var got []User
+		err := NewSelect(users).
+			WithConnection(conn).
+			Select("id", "name").
+			Where(expr.Gt(users.C.Age, age)).
+			All(context.Background(), &got)
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+		return got
+	}
+
+	first := query(18)
+	first[0].Name = "changed"
+	second := query(18)
+	if len(second) != 2 || second[0].Name != "john" || second[1].ID != 2 {
+		t.Fatalf("cached rows = %+v", second)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("second query reached the database: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name FROM users WHERE users.age > $1").
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	if got := query(30); len(got) != 0 {
+		t.Fatalf("rows for other args = %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectAllNoCache(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.cache = NewTTLCache(10, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT id FROM users").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		var got []User
+		err := NewSelect(users).WithConnection(conn).Select("id").NoCache().All(context.Background(), &got)
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+	if n := conn.cache.(*TTLCache).Len(); n != 0 {
+		t.Fatalf("NoCache stored %d entries", n)
+	}
+}
+
+func TestTTLCacheExpiry(t *testing.T) {
+	stepClock(t, time.Second)
+	cache := NewTTLCache(10, 2*time.Second)
+
+	cache.Set("k", 1)
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Fatalf("Get() = %v, %v; want 1, true", v, ok)
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("Get() returned an expired entry")
+	}
+	if n := cache.Len(); n != 0 {
+		t.Fatalf("Len() = %d after expiry, want 0", n)
+	}
+}
+
+func TestTTLCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewTTLCache(2, time.Minute)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("least recently used entry was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("entry %q was evicted", key)
+		}
+	}
+
+	cache.Purge()
+	if n := cache.Len(); n != 0 {
+		t.Fatalf("Len() = %d after Purge, want 0", n)
+	}
+}
@@ -1,23 +1,614 @@
 package builder
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
 )
 
+// ConnectionInterface defines the methods builders need to execute queries.
+// engine.Connection satisfies it.
+type ConnectionInterface interface {
+	// Dialect returns the SQL dialect for placeholder formatting and feature support
+	Dialect() dialect.Dialect
+
+	// Logger returns the logger for SQL statement tracing (may be nil)
+	Logger() *slog.Logger
+
+	// Context returns the connection context
+	Context() context.Context
+
+	// QueryTimeout returns the default per-statement timeout (zero for none)
+	QueryTimeout() time.Duration
+
+	// LogLevel returns the level executed statements are logged at
+	LogLevel() slog.Level
+
+	// QueryObserver returns the observer notified after each statement (may be nil)
+	QueryObserver() QueryObserver
+
+	// RedactArgs returns the hook applied to args before logging (may be nil)
+	RedactArgs() func([]any) []any
+
+	// Tracer returns the tracer wrapping each statement in a span (may be nil)
+	Tracer() Tracer
+
+	// QuoteIdentifiers reports whether generated identifiers are quoted
+	QuoteIdentifiers() bool
+
+	// LenientScan reports whether result columns without a matching struct
+	// field are discarded instead of failing the scan
+	LenientScan() bool
+
+	// SlowQueryThreshold returns the duration from which a statement is
+	// reported as slow (zero disables detection)
+	SlowQueryThreshold() time.Duration
+
+	// ExplainSlowQueries reports whether slow statements are re-run under
+	// EXPLAIN to include their plan in the report
+	ExplainSlowQueries() bool
+
+	// ResultCache returns the cache SelectBuilder.All reads through (may be nil)
+	ResultCache() ResultCache
+
+	// ExecuteContext runs a SQL statement
+	ExecuteContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+
+	// QueryRowContext executes a query that returns a single row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+
+	// QueryRowsContext executes a query that returns multiple rows
+	QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 // FormatPlaceholders converts ? placeholders to driver-specific format.
+// A ? inside a single-quoted string literal or double-quoted identifier is
+// left alone, as are the Postgres JSON operators ?| and ?&. Write ?? for the
+// bare JSON ? operator; it is emitted as a single ?.
 func FormatPlaceholders(sql string, dialect dialect.Dialect) string {
-	position := 1
+	formatted, _ := FormatPlaceholdersFrom(sql, dialect, 1)
+	return formatted
+}
+
+// FormatPlaceholdersFrom is FormatPlaceholders numbering from position start
+// and returning the next free position, so SQL fragments formatted one after
+// another (e.g. the parts of a composed query) don't reuse $n numbers.
+func FormatPlaceholdersFrom(sql string, dialect dialect.Dialect, start int) (string, int) {
+	position := start
 	var b strings.Builder
 	b.Grow(len(sql))
+	var quote byte
 	for i := 0; i < len(sql); i++ {
-		if sql[i] == '?' {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			// a doubled quote ('') closes and immediately reopens the literal
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?' && i+1 < len(sql) && sql[i+1] == '?':
+			i++
+		case c == '?' && isJSONOperator(sql, i):
+		case c == '?':
 			b.WriteString(dialect.Placeholder(position))
 			position++
 			continue
 		}
-		b.WriteByte(sql[i])
+		b.WriteByte(c)
+	}
+	return b.String(), position
+}
+
+// isJSONOperator reports whether the ? at sql[i] starts a Postgres ?| or ?&
+// operator rather than a placeholder followed by || or &&
+func isJSONOperator(sql string, i int) bool {
+	if i+1 >= len(sql) || (sql[i+1] != '|' && sql[i+1] != '&') {
+		return false
+	}
+	return i+2 >= len(sql) || sql[i+2] != sql[i+1]
+}
+
+// QueryObserver is notified after every statement a builder executes, e.g.
+// for metrics or slow-query detection. sql is the dialect-formatted statement
+// and args are redacted the same way as in the query log.
+type QueryObserver interface {
+	OnQuery(ctx context.Context, sql string, args []any, dur time.Duration, err error)
+}
+
+// statement is a rendered builder query ready to run on a connection.
+type statement struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	conn    ConnectionInterface
+	rawSQL  string
+	query   string
+	args    []interface{}
+	logArgs []any // args with sensitive values redacted
+	span    Span
+	start   time.Time
+	explain bool // may be re-run under EXPLAIN when slow
+}
+
+// now is the statement clock, replaced in tests
+var now = time.Now
+
+// prepare resolves the execution context and renders the builder SQL for the
+// connection dialect. A nil ctx falls back to the connection context.
+// When the connection has a query timeout the statement context is bounded by
+// it (an earlier caller deadline still wins); the caller must invoke done
+// once the statement has finished.
+func prepare(ctx context.Context, conn ConnectionInterface, b Builder) (*statement, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("builder has no connection")
+	}
+	if ctx == nil {
+		ctx = conn.Context()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rawSQL, args, err := b.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	args, logArgs := redactArgs(args)
+	if args, err = bindArrays(conn.Dialect(), args); err != nil {
+		return nil, err
+	}
+	if redact := conn.RedactArgs(); redact != nil {
+		logArgs = redact(logArgs)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout := conn.QueryTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	if err := runSetup(ctx, conn, b); err != nil {
+		cancel()
+		return nil, err
+	}
+	_, isExplain := b.(*explainQuery)
+	st := &statement{
+		ctx:     ctx,
+		cancel:  cancel,
+		conn:    conn,
+		rawSQL:  rawSQL,
+		query:   FormatPlaceholders(rawSQL, conn.Dialect()),
+		args:    args,
+		logArgs: logArgs,
+		explain: !isExplain,
+	}
+	st.startSpan(b)
+	st.start = now()
+	return st, nil
+}
+
+// setupStatement is implemented by builders that run a statement on the same
+// connection before their query, such as SET LOCAL lock_timeout
+type setupStatement interface {
+	setupSQL(conn ConnectionInterface) (string, error)
+}
+
+// runSetup executes the builder's setup statement, if any
+func runSetup(ctx context.Context, conn ConnectionInterface, b Builder) error {
+	s, ok := b.(setupStatement)
+	if !ok {
+		return nil
+	}
+	setup, err := s.setupSQL(conn)
+	if err != nil || setup == "" {
+		return err
+	}
+	_, err = conn.ExecuteContext(ctx, setup)
+	return err
+}
+
+// done ends the statement span, releases its context, logs the statement and
+// notifies the connection observer. rowsAffected is negative when unknown.
+func (s *statement) done(rowsAffected int64, err error) {
+	dur := now().Sub(s.start)
+	if s.span != nil {
+		if err != nil {
+			s.span.RecordError(err)
+		}
+		s.span.End()
+	}
+	s.cancel()
+
+	if logger := s.conn.Logger(); logger != nil {
+		attrs := []any{"raw_sql", s.rawSQL, "sql", s.query, "args", s.logArgs, "duration", dur}
+		if rowsAffected >= 0 {
+			attrs = append(attrs, "rows_affected", rowsAffected)
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		logger.Log(s.ctx, s.conn.LogLevel(), "sqlcompose: query executed", attrs...)
+	}
+	if observer := s.conn.QueryObserver(); observer != nil {
+		observer.OnQuery(s.ctx, s.query, s.logArgs, dur, err)
+	}
+	if threshold := s.conn.SlowQueryThreshold(); threshold > 0 && dur >= threshold {
+		s.reportSlow(dur, err)
+	}
+}
+
+// SlowQueryObserver is an optional QueryObserver extension notified of
+// statements that ran for at least the connection's SlowQueryThreshold.
+// plan holds the EXPLAIN output when ExplainSlowQueries is enabled and the
+// statement succeeded; it is empty otherwise.
+type SlowQueryObserver interface {
+	OnSlowQuery(ctx context.Context, sql string, args []any, dur time.Duration, plan string)
+}
+
+// reportSlow logs a slow statement at warning level, with its plan when
+// ExplainSlowQueries is enabled, and notifies a SlowQueryObserver
+func (s *statement) reportSlow(dur time.Duration, err error) {
+	var plan string
+	var explainErr error
+	if s.explain && err == nil && s.conn.ExplainSlowQueries() {
+		plan, explainErr = s.explainPlan()
+	}
+
+	if logger := s.conn.Logger(); logger != nil {
+		attrs := []any{"sql", s.query, "args", s.logArgs, "duration", dur}
+		if plan != "" {
+			attrs = append(attrs, "plan", plan)
+		}
+		if explainErr != nil {
+			attrs = append(attrs, "explain_error", explainErr)
+		}
+		logger.Log(s.ctx, slog.LevelWarn, "sqlcompose: slow query", attrs...)
+	}
+	if observer, ok := s.conn.QueryObserver().(SlowQueryObserver); ok {
+		observer.OnSlowQuery(s.ctx, s.query, s.logArgs, dur, plan)
+	}
+}
+
+// explainPlan runs the statement under the dialect's plain EXPLAIN, which
+// doesn't execute it. The statement context has been released, so the plan
+// query gets its own, bounded by the connection query timeout.
+func (s *statement) explainPlan() (string, error) {
+	prefix := s.conn.Dialect().FormatExplain(false)
+	ctx := context.WithoutCancel(s.ctx)
+	cancel := context.CancelFunc(func() {})
+	if timeout := s.conn.QueryTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	rows, err := s.conn.QueryRowsContext(ctx, prefix+" "+s.query, s.args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	return formatPlan(rows)
+}
+
+// redactedValue replaces sensitive values in logs
+const redactedValue = "****"
+
+// redactArgs unwraps table.SensitiveValue args for the driver and returns a
+// separate copy for logging with those values replaced by redactedValue.
+func redactArgs(args []interface{}) ([]interface{}, []any) {
+	logArgs := make([]any, len(args))
+	var unwrapped []interface{}
+	for i, arg := range args {
+		sv, ok := arg.(table.SensitiveValue)
+		if !ok {
+			logArgs[i] = arg
+			continue
+		}
+		if unwrapped == nil {
+			unwrapped = append([]interface{}(nil), args...)
+		}
+		unwrapped[i] = sv.Val
+		logArgs[i] = redactedValue
+	}
+	if unwrapped == nil {
+		return args, logArgs
+	}
+	return unwrapped, logArgs
+}
+
+// EncodeValuers returns a copy of args with each driver.Valuer replaced by
+// its Value(), as the driver will see it, e.g. a sql.NullString as NULL or
+// its string. Use it as EngineOpts.RedactArgs, or on DebugSQL args, to log
+// or preview encoded values; execution still leaves Valuers to the driver.
+// An arg whose Value() fails is kept as is.
+func EncodeValuers(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		out[i] = arg
+		valuer, ok := arg.(driver.Valuer)
+		if !ok {
+			continue
+		}
+		if rv := reflect.ValueOf(arg); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			out[i] = nil
+			continue
+		}
+		if v, err := valuer.Value(); err == nil {
+			out[i] = v
+		}
+	}
+	return out
+}
+
+// execute runs a statement that does not return rows.
+func execute(ctx context.Context, conn ConnectionInterface, b Builder) (sql.Result, error) {
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return nil, err
+	}
+	res, err := conn.ExecuteContext(st.ctx, st.query, st.args...)
+	rowsAffected := int64(-1)
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			rowsAffected = n
+		}
+	}
+	st.done(rowsAffected, err)
+	return res, err
+}
+
+// queryAll runs a row-returning statement and scans every row into dest.
+func queryAll(ctx context.Context, conn ConnectionInterface, b Builder, dest interface{}) (err error) {
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanAll(st.ctx, rows, dest, newScanOptions(conn, b))
+}
+
+// queryRows runs a row-returning statement and returns an iterator over it.
+// The statement context stays alive until the iterator is closed.
+func queryRows(ctx context.Context, conn ConnectionInterface, b Builder) (*RowIterator, error) {
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		st.done(-1, err)
+		return nil, err
+	}
+	return &RowIterator{ctx: st.ctx, rows: rows, stmt: st, scan: newScanOptions(conn, b)}, nil
+}
+
+// queryOne runs a row-returning statement and scans exactly one row into dest.
+func queryOne(ctx context.Context, conn ConnectionInterface, b Builder, dest interface{}) (err error) {
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanOne(rows, dest, newScanOptions(conn, b))
+}
+
+// queryInto runs a statement returning one row and scans its columns into
+// the matching fields of dest, leaving every other field as it was
+func queryInto(ctx context.Context, conn ConnectionInterface, b Builder, dest interface{}) (err error) {
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	opts := newScanOptions(conn, b)
+	opts.merge = true
+	return scanOne(rows, dest, opts)
+}
+
+// queryScalar runs a statement returning one column and one row and scans
+// the value into dest.
+func queryScalar(ctx context.Context, conn ConnectionInterface, b Builder, dest interface{}) (err error) {
+	if err := checkScalarDest(dest); err != nil {
+		return err
+	}
+	st, err := prepare(ctx, conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanScalar(rows, dest, newScanOptions(conn, b))
+}
+
+// identifierQuoter returns the function builders pass generated identifiers
+// through: the dialect Quote when the connection enables QuoteIdentifiers,
+// identity otherwise (including builders without a connection).
+func identifierQuoter(conn ConnectionInterface) func(string) string {
+	if conn == nil || !conn.QuoteIdentifiers() {
+		return func(ident string) string { return ident }
+	}
+	d := conn.Dialect()
+	return func(ident string) string { return quoteIdentifier(d, ident) }
+}
+
+// quoteIdentifier quotes each part of a plain or table-qualified identifier.
+// Anything else (*, expressions, already quoted names) is returned unchanged.
+func quoteIdentifier(d dialect.Dialect, ident string) string {
+	parts := strings.Split(ident, ".")
+	for _, part := range parts {
+		if !isPlainIdentifier(part) {
+			return ident
+		}
+	}
+	for i, part := range parts {
+		parts[i] = d.Quote(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// isPlainIdentifier reports whether s is a bare SQL identifier
+func isPlainIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteAll applies quote to every identifier
+func quoteAll(quote func(string) string, idents []string) []string {
+	quoted := make([]string, len(idents))
+	for i, ident := range idents {
+		quoted[i] = quote(ident)
+	}
+	return quoted
+}
+
+// softDeleteColumn returns the table's soft-delete column, or nil.
+func softDeleteColumn(tbl table.TableInterface) *table.ColumnRef {
+	for _, col := range tbl.Columns() {
+		if col.Options.SoftDelete {
+			return col
+		}
+	}
+	return nil
+}
+
+// bindColumn marks a value bound to a Sensitive column for log redaction.
+func bindColumn(tbl table.TableInterface, column string, value interface{}) interface{} {
+	for _, col := range tbl.Columns() {
+		if col.Name == column && col.Options.Sensitive {
+			return table.SensitiveValue{Val: value}
+		}
+	}
+	return value
+}
+
+// primaryKeyColumn returns the name of the table's single primary key
+// column, whether declared on the column or with Table.PrimaryKey.
+func primaryKeyColumn(tbl table.TableInterface) (string, error) {
+	pk := tbl.PrimaryKeyColumns()
+	if len(pk) != 1 {
+		return "", fmt.Errorf("table %s must have exactly one primary key column", tbl.Name())
+	}
+	return pk[0], nil
+}
+
+// maxParams returns the number of bind parameters a single statement may
+// carry on the dialect
+func maxParams(d dialect.Dialect) int {
+	if d == nil {
+		return 999
+	}
+	switch d.Name() {
+	case "sqlite":
+		// SQLITE_MAX_VARIABLE_NUMBER before 3.32
+		return 999
+	default:
+		return 65535
+	}
+}
+
+// renderExpr renders e for the dialect after checking the dialect supports
+// every construct it uses (e.g. array operators). Column references are
+// quoted when conn enables QuoteIdentifiers.
+func renderExpr(conn ConnectionInterface, e expr.Expr, d dialect.Dialect) (string, []interface{}, error) {
+	if err := expr.Check(e, d); err != nil {
+		return "", nil, err
+	}
+	if d != nil && conn != nil && conn.QuoteIdentifiers() {
+		base := d
+		d = &expr.QuotingDialect{Dialect: base, QuoteIdent: func(ident string) string {
+			return quoteIdentifier(base, ident)
+		}}
+	}
+	sql, args := expr.Render(e, d)
+	return sql, args, nil
+}
+
+// bindArrays wraps Go slice arguments (other than []byte) with the dialect's
+// array encoding, failing on dialects without native arrays
+func bindArrays(d dialect.Dialect, args []interface{}) ([]interface{}, error) {
+	var bound []interface{}
+	for i, arg := range args {
+		if !isArrayValue(reflect.TypeOf(arg)) {
+			continue
+		}
+		conv, ok := d.(dialect.ArrayConverter)
+		if !ok || !d.Supports(dialect.FeatureArrays) {
+			return nil, dialect.Require(d, dialect.FeatureArrays)
+		}
+		if bound == nil {
+			bound = append([]interface{}(nil), args...)
+		}
+		bound[i] = conv.Array(arg)
+	}
+	if bound == nil {
+		return args, nil
+	}
+	return bound, nil
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// isArrayValue reports whether typ is a slice bound as a SQL array
+func isArrayValue(typ reflect.Type) bool {
+	if typ == nil || typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	return !typ.Implements(valuerType) && !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// orWhere collapses the accumulated WHERE conditions into a single
+// (previous AND ...) OR condition group
+func orWhere(exprs []expr.Expr, condition expr.Expr) []expr.Expr {
+	switch len(exprs) {
+	case 0:
+		return []expr.Expr{condition}
+	case 1:
+		return []expr.Expr{expr.Or(exprs[0], condition)}
+	default:
+		return []expr.Expr{expr.Or(expr.And(exprs...), condition)}
 	}
-	return b.String()
 }
@@ -0,0 +1,481 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.timeout = 10 * time.Millisecond
+
+	mock.ExpectExec("DELETE FROM users").
+		WillDelayFor(time.Second).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	start := time.Now()
+	_, err := NewDelete(conn.Dialect(), newUsersTable()).WithConnection(conn).Exec(context.Background())
+	if err == nil {
+		t.Fatal("expected Exec() to be cancelled by the query timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Exec() took %v, want it bounded by the query timeout", elapsed)
+	}
+}
+
+func TestQueryTimeoutRespectsCallerDeadline(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.timeout = time.Hour
+
+	mock.ExpectExec("DELETE FROM users").
+		WillDelayFor(time.Second).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewDelete(conn.Dialect(), newUsersTable()).WithConnection(conn).Exec(ctx)
+	if err == nil {
+		t.Fatal("expected Exec() to be cancelled by the caller deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Exec() took %v, want it bounded by the caller deadline", elapsed)
+	}
+}
+
+type recordingObserver struct {
+	sql  string
+	args []any
+	err  error
+}
+
+func (o *recordingObserver) OnQuery(ctx context.Context, sql string, args []any, dur time.Duration, err error) {
+	o.sql, o.args, o.err = sql, args, err
+}
+
+func TestQueryObserverAndLogging(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	var logs bytes.Buffer
+	conn.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	observer := &recordingObserver{}
+	conn.observer = observer
+
+	mock.ExpectExec("UPDATE users SET name = $1").
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	if _, err := NewUpdate(conn.Dialect(), newUsersTable()).WithConnection(conn).Set("name", "john").AllowNoWhere().Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if observer.sql != "UPDATE users SET name = $1" || len(observer.args) != 1 || observer.err != nil {
+		t.Fatalf("unexpected observation %+v", observer)
+	}
+	out := logs.String()
+	for _, want := range []string{"level=INFO", "sql=\"UPDATE users SET name = $1\"", "args=[john]", "rows_affected=3", "duration="} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log output %q missing %q", out, want)
+		}
+	}
+}
+
+// slowObserver records OnQuery calls and slow-query reports
+type slowObserver struct {
+	recordingObserver
+	slowSQL string
+	slowDur time.Duration
+	plan    string
+	slow    int
+}
+
+func (o *slowObserver) OnSlowQuery(ctx context.Context, sql string, args []any, dur time.Duration, plan string) {
+	o.slowSQL, o.slowDur, o.plan = sql, dur, plan
+	o.slow++
+}
+
+// stepClock replaces the statement clock with one advancing step per call
+func stepClock(t *testing.T, step time.Duration) {
+	t.Helper()
+	current := time.Unix(0, 0)
+	now = func() time.Time {
+		current = current.Add(step)
+		return current
+	}
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestSlowQueryExplained(t *testing.T) {
+	stepClock(t, 2*time.Second)
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.slow = time.Second
+	conn.explain = true
+	var logs bytes.Buffer
+	conn.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	observer := &slowObserver{}
+	conn.observer = observer
+	users := newUsersTable()
+
+	mock.ExpectQuery("SELECT * FROM users WHERE users.age > $1").
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "age"}))
+	mock.ExpectQuery("EXPLAIN (FORMAT TEXT) SELECT * FROM users WHERE users.age > $1").
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on users").
+			AddRow("  Filter: (age > 30)"))
+
+	var got []User
+	if err := NewSelect(users).WithConnection(conn).Where(expr.Gt(users.C.Age, 30)).All(context.Background(), &got); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	if observer.slow != 1 || observer.slowSQL != "SELECT * FROM users WHERE users.age > $1" || observer.slowDur != 2*time.Second {
+		t.Fatalf("unexpected slow-query report %+v", observer)
+	}
+	if want := "Seq Scan on users\n  Filter: (age > 30)"; observer.plan != want {
+		t.Fatalf("plan = %q, want %q", observer.plan, want)
+	}
+	out := logs.String()
+	for _, want := range []string{"level=WARN", "\"sqlcompose: slow query\"", "plan=\"Seq Scan on users"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log output %q missing %q", out, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSlowQueryThreshold(t *testing.T) {
+	stepClock(t, 10*time.Millisecond)
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.slow = time.Second
+	observer := &slowObserver{}
+	conn.observer = observer
+
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := NewDelete(conn.Dialect(), newUsersTable()).WithConnection(conn).AllowNoWhere().Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if observer.slow != 0 {
+		t.Fatalf("fast statement reported as slow: %+v", observer)
+	}
+
+	// Without ExplainSlowQueries the report has no plan and no extra query runs
+	stepClock(t, 5*time.Second)
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := NewDelete(conn.Dialect(), newUsersTable()).WithConnection(conn).AllowNoWhere().Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if observer.slow != 1 || observer.plan != "" {
+		t.Fatalf("unexpected slow-query report %+v", observer)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+type credentialsColumns struct {
+	Username *table.Column[string]
+	Password *table.Column[string]
+}
+
+func TestSensitiveArgsRedactedFromLogs(t *testing.T) {
+	creds := table.NewTable("credentials", credentialsColumns{
+		Username: table.Col[string]("username"),
+		Password: table.Col[string]("password").Sensitive(),
+	})
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	var logs bytes.Buffer
+	conn.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	observer := &recordingObserver{}
+	conn.observer = observer
+
+	// Values reach the database unredacted
+	mock.ExpectExec("UPDATE credentials SET password = $1 WHERE credentials.password = $2").
+		WithArgs("new-secret", "old-secret").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := NewUpdate(conn.Dialect(), creds).
+		WithConnection(conn).
+		Set("password", "new-secret").
+		Where(expr.Eq(creds.C.Password, "old-secret")).
+		Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if strings.Contains(logs.String(), "secret") {
+		t.Fatalf("log output leaks sensitive value: %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), "args=\"[**** ****]\"") {
+		t.Fatalf("log output %q missing redacted args", logs.String())
+	}
+	if len(observer.args) != 2 || observer.args[0] != "****" {
+		t.Fatalf("observer args = %v, want redacted", observer.args)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRedactArgsHook(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	var logs bytes.Buffer
+	conn.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	conn.redact = func(args []any) []any {
+		for i := range args {
+			args[i] = "redacted"
+		}
+		return args
+	}
+
+	mock.ExpectExec("UPDATE users SET name = $1").
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := NewUpdate(conn.Dialect(), newUsersTable()).WithConnection(conn).Set("name", "john").AllowNoWhere().Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "args=[redacted]") {
+		t.Fatalf("log output %q missing hook redaction", logs.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// shouting is a custom driver.Valuer that upper-cases its value
+type shouting string
+
+func (s shouting) Value() (driver.Value, error) {
+	return strings.ToUpper(string(s)), nil
+}
+
+func TestEncodeValuers(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	var logs bytes.Buffer
+	conn.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	conn.redact = EncodeValuers
+	observer := &recordingObserver{}
+	conn.observer = observer
+
+	// The driver encodes the Valuer itself at execution
+	mock.ExpectExec("UPDATE users SET name = $1 WHERE users.email = $2").
+		WithArgs("JOHN", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	update := NewUpdate(conn.Dialect(), newUsersTable()).
+		WithConnection(conn).
+		Set("name", shouting("john")).
+		Where(expr.Eq(newUsersTable().C.Email, sql.NullString{}))
+	if _, err := update.Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "args=\"[JOHN <nil>]\"") {
+		t.Fatalf("log output %q missing encoded args", logs.String())
+	}
+	if want := []any{"JOHN", nil}; !reflect.DeepEqual(observer.args, want) {
+		t.Fatalf("observer args = %v, want %v", observer.args, want)
+	}
+
+	// Previews keep the raw values unless encoded explicitly
+	_, args := update.DebugSQL(conn.Dialect())
+	if args[0] != shouting("john") {
+		t.Fatalf("DebugSQL() args = %v, want raw values", args)
+	}
+	if got := EncodeValuers(args); !reflect.DeepEqual(got, []any{"JOHN", nil}) {
+		t.Fatalf("EncodeValuers() = %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+type orderColumns struct {
+	ID    *table.Column[int64]
+	User  *table.Column[string]
+	Group *table.Column[string]
+}
+
+func TestQuoteIdentifiers(t *testing.T) {
+	orders := table.NewTable("order", orderColumns{
+		ID:    table.Col[int64]("id").PrimaryKey(),
+		User:  table.Col[string]("user"),
+		Group: table.Col[string]("group"),
+	})
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		build   func(conn ConnectionInterface) Builder
+		want    string
+	}{
+		{
+			name:    "select postgres",
+			dialect: &postgres.PostgresDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				return NewSelect(orders).WithConnection(conn).Select("id", "order.user", "COUNT(*)").GroupBy("group").OrderBy("user")
+			},
+			want: `SELECT "id", "order"."user", COUNT(*) FROM "order" GROUP BY "group" ORDER BY "user" ASC`,
+		},
+		{
+			name:    "insert mysql",
+			dialect: &mysql.MySQLDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				return NewInsert(conn.Dialect(), orders).WithConnection(conn).Set("user", "john").Set("group", "admin")
+			},
+			want: "INSERT INTO `order` (`user`, `group`) VALUES (?, ?)",
+		},
+		{
+			name:    "update sqlite",
+			dialect: &sqlite.SQLiteDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				return NewUpdate(conn.Dialect(), orders).WithConnection(conn).Set("user", "john").Returning("id")
+			},
+			want: `UPDATE "order" SET "user" = ? RETURNING "id"`,
+		},
+		{
+			name:    "delete postgres",
+			dialect: &postgres.PostgresDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				return NewDelete(conn.Dialect(), orders).WithConnection(conn)
+			},
+			want: `DELETE FROM "order"`,
+		},
+		{
+			name:    "where sqlite",
+			dialect: &sqlite.SQLiteDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				return NewSelect(orders).WithConnection(conn).
+					Where(expr.Eq(orders.C.User, "john")).
+					Where(expr.In(orders.C.ID, 1, 2)).
+					WhereEq(map[string]interface{}{"group": "admin"})
+			},
+			want: `SELECT * FROM "order" WHERE "order"."user" = ? AND "order"."id" IN (?, ?) AND "order"."group" = ?`,
+		},
+		{
+			name:    "join mysql",
+			dialect: &mysql.MySQLDialect{},
+			build: func(conn ConnectionInterface) Builder {
+				users := newUsersTable()
+				return NewSelect(orders).WithConnection(conn).
+					Join(users, expr.Eq(users.C.ID, orders.C.ID)).
+					Where(expr.IsNull(users.C.Email))
+			},
+			want: "SELECT * FROM `order` INNER JOIN `users` ON `users`.`id` = `order`.`id` WHERE `users`.`email` IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			conn.quote = true
+
+			sql, _, err := tt.build(conn).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifiersDisabledByDefault(t *testing.T) {
+	conn, _ := newTestConn(t, &postgres.PostgresDialect{})
+
+	sql, _, err := NewSelect(newUsersTable()).WithConnection(conn).Select("id").ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT id FROM users"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestDebugSQL(t *testing.T) {
+	users := newUsersTable()
+	pg := &postgres.PostgresDialect{}
+	conn, mock := newTestConn(t, pg)
+
+	sel := NewSelect(users).Where(expr.Eq(users.C.Name, "john")).Limit(5)
+	sql, args := sel.DebugSQL(pg)
+	if want := "SELECT * FROM users WHERE users.name = $1 LIMIT $2"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 2 || args[0] != "john" || args[1] != 5 {
+		t.Fatalf("DebugSQL() args = %v", args)
+	}
+
+	upd := NewUpdate(pg, users).Set("name", "jane").Where(expr.Eq(users.C.ID, int64(1)))
+	sql, args = upd.DebugSQL(pg)
+	if want := "UPDATE users SET name = $1 WHERE users.id = $2"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+
+	// The debug output is exactly what is executed
+	mock.ExpectExec(sql).WithArgs(args[0], args[1]).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := upd.WithConnection(conn).Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if sql, _ := NewInsert(&sqlite.SQLiteDialect{}, users).Set("name", "john").DebugSQL(&sqlite.SQLiteDialect{}); sql != "INSERT INTO users (name) VALUES (?)" {
+		t.Fatalf("DebugSQL() = %q", sql)
+	}
+	if sql, _ := NewDelete(pg, users).Where(expr.Eq(users.C.ID, int64(1))).DebugSQL(pg); sql != "DELETE FROM users WHERE users.id = $1" {
+		t.Fatalf("DebugSQL() = %q", sql)
+	}
+	if sql, args := NewInsert(pg, users).DebugSQL(pg); sql != "" || args != nil {
+		t.Fatalf("DebugSQL() of invalid builder = %q %v, want empty", sql, args)
+	}
+}
+
+func TestDebugSQLRendersForDialect(t *testing.T) {
+	users := newUsersTable()
+	lite := &sqlite.SQLiteDialect{}
+
+	// Unbound builder: rendered for d, not dialect-neutral
+	sql, _ := NewSelect(users).Offset(5).DebugSQL(lite)
+	if want := "SELECT * FROM users LIMIT -1 OFFSET ?"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+
+	// Bound to Postgres, previewed for SQLite: ILIKE falls back to LOWER
+	conn, _ := newTestConn(t, &postgres.PostgresDialect{})
+	sel := NewSelect(users).WithConnection(conn).Where(expr.ILike(users.C.Email, "%@x.com"))
+	sql, _ = sel.DebugSQL(lite)
+	if want := "SELECT * FROM users WHERE LOWER(users.email) LIKE LOWER(?)"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+	sql, _ = sel.DebugSQL(&postgres.PostgresDialect{})
+	if want := "SELECT * FROM users WHERE users.email ILIKE $1"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+
+	// The builder itself keeps its dialect
+	upd := NewUpdate(lite, users).Set("name", "jane").Where(expr.ILike(users.C.Email, "%@x.com"))
+	if sql, _ := upd.DebugSQL(&postgres.PostgresDialect{}); sql != "UPDATE users SET name = $1 WHERE users.email ILIKE $2" {
+		t.Fatalf("DebugSQL() = %q", sql)
+	}
+	if sql, _, _ := upd.ToSQL(); sql != "UPDATE users SET name = ? WHERE LOWER(users.email) LIKE LOWER(?)" {
+		t.Fatalf("ToSQL() = %q", sql)
+	}
+}
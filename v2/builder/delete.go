@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -11,10 +13,14 @@ import (
 
 // DeleteBuilder builds DELETE queries
 type DeleteBuilder struct {
+	conn       ConnectionInterface
 	dialect    dialect.Dialect
 	table      table.TableInterface
 	whereExprs []expr.Expr
 	returning  []string
+	hardDelete bool
+	in         *expr.InExpr
+	allowAll   bool // AllowNoWhere
 }
 
 // NewDelete creates a new DELETE builder
@@ -31,33 +37,239 @@ func (b *DeleteBuilder) Where(condition expr.Expr) *DeleteBuilder {
 	return b
 }
 
+// WhereOr adds one WHERE condition that holds when any of conditions does;
+// it is ANDed with the other conditions like Where
+func (b *DeleteBuilder) WhereOr(conditions ...expr.Expr) *DeleteBuilder {
+	b.whereExprs = append(b.whereExprs, expr.Or(conditions...))
+	return b
+}
+
+// WhereAny is WhereOr, named to pair with WhereAll
+func (b *DeleteBuilder) WhereAny(conditions ...expr.Expr) *DeleteBuilder {
+	return b.WhereOr(conditions...)
+}
+
+// WhereAll adds one WHERE condition that holds when every one of
+// conditions does, grouped like WhereOr
+func (b *DeleteBuilder) WhereAll(conditions ...expr.Expr) *DeleteBuilder {
+	b.whereExprs = append(b.whereExprs, expr.And(conditions...))
+	return b
+}
+
+// OrWhere ORs condition with every condition added so far, so
+// Where(a).Where(b).OrWhere(c) renders ((a AND b) OR c); later Where calls
+// are ANDed with that whole group
+func (b *DeleteBuilder) OrWhere(condition expr.Expr) *DeleteBuilder {
+	b.whereExprs = orWhere(b.whereExprs, condition)
+	return b
+}
+
+// WhereIn restricts the delete to rows whose column value is in values.
+// Go methods cannot take type parameters, so this is a function rather than
+// a DeleteBuilder method. When values exceed the dialect's bind parameter
+// limit, Exec splits the delete into several statements.
+func WhereIn[T any](b *DeleteBuilder, col *table.Column[T], values []T) *DeleteBuilder {
+	b.in = expr.In(col, values...).(*expr.InExpr)
+	return b
+}
+
+// HardDelete removes rows even when the table has a soft-delete column
+func (b *DeleteBuilder) HardDelete() *DeleteBuilder {
+	b.hardDelete = true
+	return b
+}
+
+// WithConnection binds the builder to a connection so it can be executed
+func (b *DeleteBuilder) WithConnection(conn ConnectionInterface) *DeleteBuilder {
+	b.conn = conn
+	return b
+}
+
+// targetTable returns the table the statement operates on
+func (b *DeleteBuilder) targetTable() table.TableInterface {
+	return b.table
+}
+
 // Returning specifies which columns to return
 func (b *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
 	b.returning = columns
 	return b
 }
 
-// ToSQL generates the SQL query and arguments
+// AllowNoWhere permits executing the delete without a WHERE condition,
+// removing every row in the table
+func (b *DeleteBuilder) AllowNoWhere() *DeleteBuilder {
+	b.allowAll = true
+	return b
+}
+
+// checkWhere refuses to run a delete that would remove every row, unless
+// AllowNoWhere was called. The soft-delete filter does not count as a
+// condition.
+func (b *DeleteBuilder) checkWhere() error {
+	if len(b.whereExprs) > 0 || b.in != nil || b.allowAll {
+		return nil
+	}
+	return fmt.Errorf("DELETE FROM %s has no WHERE condition; call AllowNoWhere to delete every row", b.table.Name())
+}
+
+// Exec executes the statement and returns the driver result. It fails
+// without a WHERE condition unless AllowNoWhere was called.
+// A WhereIn list larger than the dialect's bind parameter limit is deleted in
+// chunks, inside a transaction unless the connection is already in one.
+func (b *DeleteBuilder) Exec(ctx context.Context) (sql.Result, error) {
+	if err := b.checkWhere(); err != nil {
+		return nil, err
+	}
+	if b.in == nil {
+		return execute(ctx, b.conn, b)
+	}
+	chunk, err := b.inChunkSize()
+	if err != nil {
+		return nil, err
+	}
+	if len(b.in.Values) <= chunk {
+		return execute(ctx, b.conn, b)
+	}
+	if b.conn == nil {
+		return nil, fmt.Errorf("builder has no connection")
+	}
+
+	var total batchResult
+	err = inTransaction(ctx, b.conn, func() error {
+		for start := 0; start < len(b.in.Values); start += chunk {
+			end := start + chunk
+			if end > len(b.in.Values) {
+				end = len(b.in.Values)
+			}
+			part := *b
+			part.in = &expr.InExpr{Column: b.in.Column, Values: b.in.Values[start:end]}
+			res, err := execute(ctx, b.conn, &part)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err == nil {
+				total.rowsAffected += n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// ExecCount executes the statement and returns the number of rows deleted
+// (soft-deleted rows included), without materializing them. A Returning
+// list is ignored. It fails without a WHERE condition unless AllowNoWhere
+// was called.
+func (b *DeleteBuilder) ExecCount(ctx context.Context) (int64, error) {
+	plain := *b
+	plain.returning = nil
+	res, err := plain.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// inChunkSize returns how many WhereIn values fit in one statement next to
+// the other WHERE arguments
+func (b *DeleteBuilder) inChunkSize() (int, error) {
+	rest := *b
+	rest.in = nil
+	_, args, err := rest.ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	chunk := maxParams(b.dialect) - len(args)
+	if chunk < 1 {
+		return 0, fmt.Errorf("too many bind parameters for dialect")
+	}
+	return chunk, nil
+}
+
+// One executes the statement and scans the single RETURNING row into dest
+func (b *DeleteBuilder) One(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("One requires a RETURNING clause")
+	}
+	if err := b.checkWhere(); err != nil {
+		return err
+	}
+	return queryOne(ctx, b.conn, b, dest)
+}
+
+// All executes the statement and scans every RETURNING row into dest
+func (b *DeleteBuilder) All(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("All requires a RETURNING clause")
+	}
+	if err := b.checkWhere(); err != nil {
+		return err
+	}
+	return queryAll(ctx, b.conn, b, dest)
+}
+
+// DebugSQL returns the SQL and args exactly as executed on dialect d
+// (placeholders formatted), for logging or sqlmock expectations.
+// It returns empty SQL if the builder is invalid; call ToSQL for the error.
+func (b *DeleteBuilder) DebugSQL(d dialect.Dialect) (string, []interface{}) {
+	c := *b
+	c.conn = withDialect(b.conn, d)
+	if d != nil {
+		c.dialect = d
+	}
+	return debugSQL(&c, d)
+}
+
+// ToSQL generates the SQL query and arguments.
+// On tables with a soft-delete column (and without HardDelete) the statement
+// is rendered as an UPDATE stamping that column on rows not yet deleted.
 func (b *DeleteBuilder) ToSQL() (string, []interface{}, error) {
 	var sql strings.Builder
 	var args []interface{}
+	quote := identifierQuoter(b.conn)
 
 	// DELETE FROM table_name
 	tableName := b.table.Name()
 	if tableName == "" {
 		return "", nil, fmt.Errorf("invalid table")
 	}
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(tableName)
+
+	whereExprs := b.whereExprs
+	if b.in != nil {
+		whereExprs = append(whereExprs[:len(whereExprs):len(whereExprs)], b.in)
+	}
+	softDelete := softDeleteColumn(b.table)
+	if softDelete != nil && !b.hardDelete {
+		// UPDATE table_name SET deleted_at = CURRENT_TIMESTAMP
+		sql.WriteString("UPDATE ")
+		sql.WriteString(quote(tableName))
+		sql.WriteString(" SET ")
+		sql.WriteString(quote(softDelete.Name))
+		sql.WriteString(" = CURRENT_TIMESTAMP")
+		whereExprs = append(whereExprs[:len(whereExprs):len(whereExprs)], &expr.UnaryExpr{
+			Column:   quote(softDelete.FullName),
+			Operator: "IS NULL",
+		})
+	} else {
+		sql.WriteString("DELETE FROM ")
+		sql.WriteString(quote(tableName))
+	}
 
 	// WHERE
-	if len(b.whereExprs) > 0 {
+	if len(whereExprs) > 0 {
 		sql.WriteString(" WHERE ")
-		for i, whereExpr := range b.whereExprs {
+		for i, whereExpr := range whereExprs {
 			if i > 0 {
 				sql.WriteString(" AND ")
 			}
-			whereSQL, whereArgs := whereExpr.ToSQL()
+			whereSQL, whereArgs, err := renderExpr(b.conn, whereExpr, b.dialect)
+			if err != nil {
+				return "", nil, err
+			}
 			sql.WriteString(whereSQL)
 			args = append(args, whereArgs...)
 		}
@@ -69,7 +281,7 @@ func (b *DeleteBuilder) ToSQL() (string, []interface{}, error) {
 			return "", nil, fmt.Errorf("driver does not support RETURNING clause")
 		}
 		sql.WriteString(" RETURNING ")
-		sql.WriteString(strings.Join(b.returning, ", "))
+		sql.WriteString(strings.Join(quoteAll(quote, b.returning), ", "))
 	}
 
 	return sql.String(), args, nil
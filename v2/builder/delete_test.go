@@ -0,0 +1,185 @@
+package builder
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+)
+
+func TestDeleteSoftDeleteRendersUpdate(t *testing.T) {
+	users := newSoftDeleteUsersTable()
+
+	sql, args, err := NewDelete(&postgres.PostgresDialect{}, users).
+		Where(expr.Eq(users.C.ID, int64(7))).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	want := "UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE users.id = ? AND users.deleted_at IS NULL"
+	if sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 1 || args[0] != int64(7) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestDeleteHardDeleteBypassesSoftDelete(t *testing.T) {
+	users := newSoftDeleteUsersTable()
+
+	sql, _, err := NewDelete(&postgres.PostgresDialect{}, users).
+		Where(expr.Eq(users.C.ID, int64(7))).
+		HardDelete().
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "DELETE FROM users WHERE users.id = ?"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestDeleteExecSoftDelete(t *testing.T) {
+	users := newSoftDeleteUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectExec("UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE users.id = $1 AND users.deleted_at IS NULL").
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	res, err := NewDelete(conn.Dialect(), users).
+		WithConnection(conn).
+		Where(expr.Eq(users.C.ID, int64(7))).
+		Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("RowsAffected = %d, want 1", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestDeleteExecWithoutConnection(t *testing.T) {
+	users := newUsersTable()
+
+	if _, err := NewDelete(&postgres.PostgresDialect{}, users).Exec(context.Background()); err == nil {
+		t.Fatalf("expected error executing an unbound builder")
+	}
+}
+
+func TestDeleteWhereIn(t *testing.T) {
+	users := newUsersTable()
+
+	sql, args, err := WhereIn(NewDelete(&postgres.PostgresDialect{}, users), users.C.ID, []int64{1, 2, 3}).
+		Where(expr.Gt(users.C.Age, 30)).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "DELETE FROM users WHERE users.age > ? AND users.id IN (?, ?, ?)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 4 || args[0] != 30 || args[3] != int64(3) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestDeleteWhereInChunksByParameterLimit(t *testing.T) {
+	users := newUsersTable()
+	base, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+	conn := &txTestConn{testConn: base}
+
+	ids := make([]int64, 1000)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	// SQLite allows 999 parameters; one is taken by the age filter.
+	for _, chunk := range [][]int64{ids[:998], ids[998:]} {
+		args := make([]driver.Value, 0, len(chunk)+1)
+		args = append(args, 30)
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+		mock.ExpectExec("DELETE FROM users WHERE users.age > ? AND users.id IN (" + placeholders + ")").
+			WithArgs(args...).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(chunk))))
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	res, err := WhereIn(NewDelete(conn.Dialect(), users).WithConnection(conn), users.C.ID, ids).
+		Where(expr.Gt(users.C.Age, 30)).
+		Exec(ctx)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1000 {
+		t.Fatalf("RowsAffected = %d, want 1000", n)
+	}
+	if !conn.committed || conn.beginCtx != ctx {
+		t.Fatal("expected the chunks to run in a transaction begun with the Exec context")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestDeleteRequiresWhere(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	_, err := NewDelete(conn.Dialect(), users).WithConnection(conn).Exec(context.Background())
+	if err == nil || err.Error() != "DELETE FROM users has no WHERE condition; call AllowNoWhere to delete every row" {
+		t.Fatalf("Exec() error = %v, want missing WHERE error", err)
+	}
+
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 5))
+	res, err := NewDelete(conn.Dialect(), users).WithConnection(conn).AllowNoWhere().Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 5 {
+		t.Fatalf("RowsAffected = %d, want 5", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestDeleteExecCount(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	// RETURNING is dropped: the count comes from RowsAffected
+	mock.ExpectExec("DELETE FROM users WHERE users.age < $1").
+		WithArgs(18).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	n, err := NewDelete(conn.Dialect(), users).
+		WithConnection(conn).
+		Where(expr.Lt(users.C.Age, 18)).
+		Returning("id").
+		ExecCount(context.Background())
+	if err != nil {
+		t.Fatalf("ExecCount() error = %v", err)
+	}
+	if n != 42 {
+		t.Fatalf("ExecCount() = %d, want 42", n)
+	}
+
+	if _, err := NewDelete(conn.Dialect(), users).WithConnection(conn).ExecCount(context.Background()); err == nil {
+		t.Fatalf("expected ExecCount without WHERE to fail")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
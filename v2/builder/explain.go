@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// Explain returns the query plan the database chooses for the query, as
+// text: EXPLAIN (FORMAT TEXT) on Postgres, EXPLAIN on MySQL and
+// EXPLAIN QUERY PLAN on SQLite. The query itself is not executed.
+func (b *SelectBuilder) Explain(ctx context.Context) (string, error) {
+	return explain(ctx, b.conn, b, false)
+}
+
+// ExplainAnalyze executes the query under EXPLAIN ANALYZE and returns the
+// plan with actual row counts and timings. SQLite has no EXPLAIN ANALYZE.
+func (b *SelectBuilder) ExplainAnalyze(ctx context.Context) (string, error) {
+	return explain(ctx, b.conn, b, true)
+}
+
+// explainQuery prefixes a statement with the dialect's EXPLAIN
+type explainQuery struct {
+	inner  Builder
+	prefix string
+}
+
+func (q *explainQuery) ToSQL() (string, []interface{}, error) {
+	sql, args, err := q.inner.ToSQL()
+	if err != nil {
+		return "", nil, err
+	}
+	return q.prefix + " " + sql, args, nil
+}
+
+func (q *explainQuery) targetTable() table.TableInterface {
+	if tb, ok := q.inner.(tableBuilder); ok {
+		return tb.targetTable()
+	}
+	return nil
+}
+
+// explain runs b under EXPLAIN and renders the result rows as text: a single
+// plan column (Postgres, MySQL EXPLAIN ANALYZE) gives one line per row;
+// tabular plans (MySQL EXPLAIN, SQLite) get a header line and tab-separated
+// values
+func explain(ctx context.Context, conn ConnectionInterface, b Builder, analyze bool) (plan string, err error) {
+	if conn == nil {
+		return "", fmt.Errorf("builder has no connection")
+	}
+	d := conn.Dialect()
+	prefix := d.FormatExplain(analyze)
+	if prefix == "" {
+		return "", fmt.Errorf("EXPLAIN ANALYZE is not supported by the %s dialect", d.Name())
+	}
+
+	st, err := prepare(ctx, conn, &explainQuery{inner: b, prefix: prefix})
+	if err != nil {
+		return "", err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := conn.QueryRowsContext(st.ctx, st.query, st.args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	return formatPlan(rows)
+}
+
+// formatPlan concatenates EXPLAIN result rows into readable text
+func formatPlan(rows *sql.Rows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	if len(columns) > 1 {
+		lines = append(lines, strings.Join(columns, "\t"))
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String // NULL renders empty
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,84 @@
+package builder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+)
+
+func TestSelectExplainPostgres(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("EXPLAIN (FORMAT TEXT) SELECT * FROM users WHERE users.age > $1").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on users  (cost=0.00..25.88 rows=423 width=72)").
+			AddRow("  Filter: (age > 18)"))
+
+	plan, err := NewSelect(users).WithConnection(conn).Where(expr.Gt(users.C.Age, 18)).Explain(context.Background())
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	want := "Seq Scan on users  (cost=0.00..25.88 rows=423 width=72)\n  Filter: (age > 18)"
+	if plan != want {
+		t.Fatalf("Explain() = %q, want %q", plan, want)
+	}
+
+	mock.ExpectQuery("EXPLAIN (ANALYZE, FORMAT TEXT) SELECT * FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow("Seq Scan on users (actual rows=3 loops=1)"))
+	if _, err := NewSelect(users).WithConnection(conn).ExplainAnalyze(context.Background()); err != nil {
+		t.Fatalf("ExplainAnalyze() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectExplainTabularPlan(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+
+	mock.ExpectQuery("EXPLAIN QUERY PLAN SELECT * FROM users WHERE users.name = ?").
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent", "notused", "detail"}).
+			AddRow(int64(2), int64(0), int64(0), "SCAN users"))
+
+	plan, err := NewSelect(users).WithConnection(conn).Where(expr.Eq(users.C.Name, "john")).Explain(context.Background())
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if want := "id\tparent\tnotused\tdetail\n2\t0\t0\tSCAN users"; plan != want {
+		t.Fatalf("Explain() = %q, want %q", plan, want)
+	}
+
+	_, err = NewSelect(users).WithConnection(conn).ExplainAnalyze(context.Background())
+	if err == nil || err.Error() != "EXPLAIN ANALYZE is not supported by the sqlite dialect" {
+		t.Fatalf("ExplainAnalyze() error = %v, want unsupported", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectExplainMySQLNullColumns(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &mysql.MySQLDialect{})
+
+	mock.ExpectQuery("EXPLAIN SELECT * FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "select_type", "table", "key"}).
+			AddRow(int64(1), "SIMPLE", "users", nil))
+
+	plan, err := NewSelect(users).WithConnection(conn).Explain(context.Background())
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if want := "id\tselect_type\ttable\tkey\n1\tSIMPLE\tusers\t"; plan != want {
+		t.Fatalf("Explain() = %q, want %q", plan, want)
+	}
+}
@@ -1,7 +1,10 @@
 package builder
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/guadalsistema/go-compose-sql/v2/dialect"
@@ -10,14 +13,32 @@ import (
 
 // InsertBuilder builds INSERT queries
 type InsertBuilder struct {
+	conn      ConnectionInterface
 	dialect   dialect.Dialect
 	table     table.TableInterface
 	values    []map[string]interface{} // Column-value pairs for each row
+	sets      map[string]interface{}   // Explicit values applied to every row
 	returning []string
 	orIgnore  bool
+	omitZero  bool
+	into      interface{} // struct refreshed from RETURNING, see ValuesReturning
 	err       error
+
+	conflictColumns    []string // ON CONFLICT (columns)
+	conflictConstraint string   // ON CONFLICT ON CONSTRAINT name
+	conflictUpdate     []string // DO UPDATE SET col = EXCLUDED.col
+	conflictNothing    bool     // DO NOTHING
 }
 
+// defaultKeyword is the type of Default
+type defaultKeyword string
+
+// Default is a value for Set or Values that inserts the column's database
+// DEFAULT (a sequence, CURRENT_TIMESTAMP, ...) instead of a bound value.
+// SQLite has no DEFAULT in VALUES, so there the column is left out of the
+// statement, which requires every row to use Default for it.
+const Default defaultKeyword = "DEFAULT"
+
 // NewInsert creates a new INSERT builder
 func NewInsert(d dialect.Dialect, tbl table.TableInterface) *InsertBuilder {
 	return &InsertBuilder{
@@ -32,7 +53,7 @@ func (b *InsertBuilder) Values(data interface{}) *InsertBuilder {
 		return b
 	}
 
-	rows, err := normalizeInsertValues(data, b.table.Columns())
+	rows, err := normalizeInsertValues(data, b.table)
 	if err != nil {
 		b.err = err
 		return b
@@ -41,15 +62,60 @@ func (b *InsertBuilder) Values(data interface{}) *InsertBuilder {
 	return b
 }
 
-// Set sets a specific column value
+// ValuesReturning inserts the struct ptr points to and, on Exec, scans the
+// RETURNING row back into it, so generated columns (id, created_at) are set
+// on the same struct. Only the returned columns are written; other fields
+// keep their values. Without Returning, every table column the struct has a
+// field for is returned. Requires RETURNING support (not MySQL).
+func (b *InsertBuilder) ValuesReturning(ptr interface{}) *InsertBuilder {
+	if b.err != nil {
+		return b
+	}
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		b.err = fmt.Errorf("ValuesReturning requires a pointer to a struct, got %T", ptr)
+		return b
+	}
+	b.into = ptr
+	return b.Values(ptr)
+}
+
+// Set sets a specific column value for every inserted row.
+// Precedence per column is: explicit Set > value provided through Values >
+// the column default declared on the table (Column.Default).
 func (b *InsertBuilder) Set(column string, value interface{}) *InsertBuilder {
-	if len(b.values) == 0 {
-		b.values = append(b.values, make(map[string]interface{}))
+	if b.sets == nil {
+		b.sets = make(map[string]interface{})
 	}
-	b.values[0][column] = value
+	b.sets[column] = value
 	return b
 }
 
+// OmitZero leaves out values passed to Values that hold the zero value for
+// their type, so the column default (or the database's own default) applies,
+// e.g. an unset time.Time created_at. An intentional zero is
+// indistinguishable from an unset field: use Set, or a pointer field holding
+// a pointer to the zero value, to insert it explicitly.
+//
+// In a multi-row insert, a value omitted from only some rows renders as
+// DEFAULT. SQLite has no DEFAULT keyword in VALUES, so there ToSQL fails
+// unless each column is omitted from every row or from none.
+func (b *InsertBuilder) OmitZero() *InsertBuilder {
+	b.omitZero = true
+	return b
+}
+
+// WithConnection binds the builder to a connection so it can be executed
+func (b *InsertBuilder) WithConnection(conn ConnectionInterface) *InsertBuilder {
+	b.conn = conn
+	return b
+}
+
+// targetTable returns the table the statement operates on
+func (b *InsertBuilder) targetTable() table.TableInterface {
+	return b.table
+}
+
 // Returning specifies which columns to return
 func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
 	b.returning = columns
@@ -66,17 +132,278 @@ func (b *InsertBuilder) OrIgnore() *InsertBuilder {
 	return b
 }
 
+// OnConflict sets the upsert conflict target to a unique set of columns;
+// follow it with DoUpdate or DoNothing
+func (b *InsertBuilder) OnConflict(columns ...string) *InsertBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(columns) == 0 {
+		b.err = fmt.Errorf("OnConflict requires at least one column")
+		return b
+	}
+	if b.conflictConstraint != "" {
+		b.err = fmt.Errorf("OnConflict cannot be combined with OnConflictConstraint")
+		return b
+	}
+	b.conflictColumns = columns
+	return b
+}
+
+// OnConflictConstraint sets the upsert conflict target to a named unique
+// constraint (PostgreSQL), for indexes whose columns are awkward to list
+// such as expression indexes; follow it with DoUpdate or DoNothing
+func (b *InsertBuilder) OnConflictConstraint(name string) *InsertBuilder {
+	if b.err != nil {
+		return b
+	}
+	if name == "" {
+		b.err = fmt.Errorf("OnConflictConstraint requires a constraint name")
+		return b
+	}
+	if len(b.conflictColumns) > 0 {
+		b.err = fmt.Errorf("OnConflictConstraint cannot be combined with OnConflict")
+		return b
+	}
+	b.conflictConstraint = name
+	return b
+}
+
+// DoUpdate resolves a conflict by setting columns to the values proposed
+// for insertion (EXCLUDED.column)
+func (b *InsertBuilder) DoUpdate(columns ...string) *InsertBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(columns) == 0 {
+		b.err = fmt.Errorf("DoUpdate requires at least one column")
+		return b
+	}
+	b.conflictUpdate = columns
+	b.conflictNothing = false
+	return b
+}
+
+// DoNothing resolves a conflict on the target by skipping the row
+func (b *InsertBuilder) DoNothing() *InsertBuilder {
+	b.conflictNothing = true
+	b.conflictUpdate = nil
+	return b
+}
+
+// conflictSQL renders the ON CONFLICT clause, or "" without an upsert
+func (b *InsertBuilder) conflictSQL(quote func(string) string) (string, error) {
+	hasTarget := len(b.conflictColumns) > 0 || b.conflictConstraint != ""
+	hasAction := len(b.conflictUpdate) > 0 || b.conflictNothing
+	if !hasTarget && !hasAction {
+		return "", nil
+	}
+	if b.orIgnore {
+		return "", fmt.Errorf("OrIgnore cannot be combined with an ON CONFLICT clause")
+	}
+	if !hasAction {
+		return "", fmt.Errorf("OnConflict requires DoUpdate or DoNothing")
+	}
+	if len(b.conflictUpdate) > 0 && !hasTarget {
+		return "", fmt.Errorf("DoUpdate requires OnConflict or OnConflictConstraint")
+	}
+	if b.dialect != nil {
+		if err := dialect.Require(b.dialect, dialect.FeatureOnConflict); err != nil {
+			return "", err
+		}
+		if b.conflictConstraint != "" {
+			if err := dialect.Require(b.dialect, dialect.FeatureConflictConstraint); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString(" ON CONFLICT")
+	if b.conflictConstraint != "" {
+		sql.WriteString(" ON CONSTRAINT ")
+		sql.WriteString(quote(b.conflictConstraint))
+	} else if len(b.conflictColumns) > 0 {
+		sql.WriteString(" (")
+		sql.WriteString(strings.Join(quoteAll(quote, b.conflictColumns), ", "))
+		sql.WriteString(")")
+	}
+	if b.conflictNothing {
+		sql.WriteString(" DO NOTHING")
+		return sql.String(), nil
+	}
+	sql.WriteString(" DO UPDATE SET ")
+	for i, col := range b.conflictUpdate {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(quote(col))
+		sql.WriteString(" = EXCLUDED.")
+		sql.WriteString(quote(col))
+	}
+	return sql.String(), nil
+}
+
+// Exec executes the statement and returns the driver result
+func (b *InsertBuilder) Exec(ctx context.Context) (sql.Result, error) {
+	if b.into != nil {
+		return b.execInto(ctx)
+	}
+	return execute(ctx, b.conn, b)
+}
+
+// execInto runs a ValuesReturning insert, scanning the row into b.into
+func (b *InsertBuilder) execInto(ctx context.Context) (sql.Result, error) {
+	if err := dialect.Require(b.dialect, dialect.FeatureReturning); err != nil {
+		return nil, err
+	}
+	if len(b.values) != 1 {
+		return nil, fmt.Errorf("ValuesReturning inserts exactly one row, got %d", len(b.values))
+	}
+	withReturning := *b
+	if len(withReturning.returning) == 0 {
+		withReturning.returning = structColumns(b.table, reflect.TypeOf(b.into).Elem())
+	}
+	if err := queryInto(ctx, withReturning.conn, &withReturning, b.into); err != nil {
+		return nil, err
+	}
+	return returningResult{}, nil
+}
+
+// structColumns returns the columns of tbl that typ has a field for
+func structColumns(tbl table.TableInterface, typ reflect.Type) []string {
+	var mapping fieldMapping
+	if mapper := fieldMapper(tbl); mapper != nil {
+		mapping = fieldMapping{table: tbl, mapper: mapper}
+	}
+	fields := structFields(typ, mapping)
+	var columns []string
+	for _, col := range tbl.Columns() {
+		if _, ok := fields[strings.ToLower(col.Name)]; ok {
+			columns = append(columns, col.Name)
+		}
+	}
+	return columns
+}
+
+// returningResult is the sql.Result of a ValuesReturning insert
+type returningResult struct{}
+
+func (returningResult) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("LastInsertId is not available with ValuesReturning; read the generated key from the struct")
+}
+
+func (returningResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+// One executes the statement and scans the single RETURNING row into dest
+func (b *InsertBuilder) One(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("One requires a RETURNING clause")
+	}
+	return queryOne(ctx, b.conn, b, dest)
+}
+
+// All executes the statement and scans every RETURNING row into dest, a
+// pointer to a slice; with Values([]T) this returns the generated columns of
+// every inserted row from a single statement
+func (b *InsertBuilder) All(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("All requires a RETURNING clause")
+	}
+	if err := dialect.Require(b.dialect, dialect.FeatureReturning); err != nil {
+		return err
+	}
+	return queryAll(ctx, b.conn, b, dest)
+}
+
+// ExecGetID inserts a single row and returns its generated primary key.
+// Dialects with RETURNING support (Postgres, SQLite) return the primary key
+// column directly; others (MySQL) use sql.Result.LastInsertId.
+func (b *InsertBuilder) ExecGetID(ctx context.Context) (int64, error) {
+	if !b.dialect.SupportsReturning() {
+		res, err := b.Exec(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+
+	pk, err := primaryKeyColumn(b.table)
+	if err != nil {
+		return 0, err
+	}
+	withID := *b
+	withID.returning = []string{pk}
+
+	var id int64
+	if err := queryOne(ctx, withID.conn, &withID, &id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// InsertResult is the outcome of ExecResult
+type InsertResult struct {
+	RowsAffected int64
+	LastInsertID int64
+	HasID        bool // false when the driver cannot report LastInsertId
+}
+
+// ExecResult executes the statement and reads RowsAffected and LastInsertId
+// from the result. MySQL and SQLite report the last generated ID; Postgres
+// drivers don't (use ExecGetID or Returning there), so HasID is false and
+// LastInsertID is zero rather than an error.
+func (b *InsertBuilder) ExecResult(ctx context.Context) (InsertResult, error) {
+	res, err := b.Exec(ctx)
+	if err != nil {
+		return InsertResult{}, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return InsertResult{}, err
+	}
+	out := InsertResult{RowsAffected: n}
+	if id, err := res.LastInsertId(); err == nil {
+		out.LastInsertID = id
+		out.HasID = true
+	}
+	return out, nil
+}
+
+// DebugSQL returns the SQL and args exactly as executed on dialect d
+// (placeholders formatted), for logging or sqlmock expectations.
+// It returns empty SQL if the builder is invalid; call ToSQL for the error.
+func (b *InsertBuilder) DebugSQL(d dialect.Dialect) (string, []interface{}) {
+	c := *b
+	c.conn = withDialect(b.conn, d)
+	if d != nil {
+		c.dialect = d
+	}
+	return debugSQL(&c, d)
+}
+
 // ToSQL generates the SQL query and arguments
 func (b *InsertBuilder) ToSQL() (string, []interface{}, error) {
 	if b.err != nil {
 		return "", nil, b.err
 	}
-	if len(b.values) == 0 {
-		return "", nil, fmt.Errorf("no values to insert")
+	rows := b.values
+	if b.omitZero {
+		rows = withoutZeroValues(rows)
 	}
+	if len(rows) == 0 {
+		if len(b.sets) == 0 {
+			return "", nil, fmt.Errorf("no values to insert")
+		}
+		rows = []map[string]interface{}{{}}
+	}
+	defaults := columnDefaults(b.table.Columns())
 
 	var sql strings.Builder
 	var args []interface{}
+	quote := identifierQuoter(b.conn)
 
 	// Get ignore clause if needed
 	var ignoreClause string
@@ -101,24 +428,30 @@ func (b *InsertBuilder) ToSQL() (string, []interface{}, error) {
 		sql.WriteString(" ")
 	}
 	sql.WriteString("INTO ")
-	sql.WriteString(tableName)
+	sql.WriteString(quote(tableName))
 
-	// Get column names from first row
-	columns := orderedInsertColumns(b.values[0], b.table.Columns())
+	// Get column names from every row, explicit sets and column defaults
+	columns := orderedInsertColumns(insertColumnSet(rows, b.sets, defaults), b.table.Columns())
+	if b.dialect != nil && !b.dialect.Supports(dialect.FeatureDefaultKeyword) {
+		var err error
+		if columns, err = b.omitDefaultColumns(rows, columns, defaults); err != nil {
+			return "", nil, err
+		}
+	}
 	if len(columns) == 0 {
 		return "", nil, fmt.Errorf("no insertable columns found")
 	}
 
 	// (column1, column2, ...)
 	sql.WriteString(" (")
-	sql.WriteString(strings.Join(columns, ", "))
+	sql.WriteString(strings.Join(quoteAll(quote, columns), ", "))
 	sql.WriteString(")")
 
 	// VALUES
 	sql.WriteString(" VALUES ")
 
 	// Add value rows
-	for i, row := range b.values {
+	for i, row := range rows {
 		if i > 0 {
 			sql.WriteString(", ")
 		}
@@ -127,13 +460,17 @@ func (b *InsertBuilder) ToSQL() (string, []interface{}, error) {
 			if j > 0 {
 				sql.WriteString(", ")
 			}
-			sql.WriteString("?")
-			val, ok := row[col]
-			if ok {
-				args = append(args, val)
-			} else {
-				args = append(args, nil)
+			val := b.resolveValue(row, col, defaults)
+			if raw, ok := val.(table.RawDefault); ok {
+				sql.WriteString(string(raw))
+				continue
 			}
+			if val == Default {
+				sql.WriteString("DEFAULT")
+				continue
+			}
+			sql.WriteString("?")
+			args = append(args, bindColumn(b.table, col, val))
 		}
 		sql.WriteString(")")
 	}
@@ -144,14 +481,64 @@ func (b *InsertBuilder) ToSQL() (string, []interface{}, error) {
 		sql.WriteString(ignoreClause)
 	}
 
+	// ON CONFLICT target DO UPDATE/DO NOTHING
+	conflict, err := b.conflictSQL(quote)
+	if err != nil {
+		return "", nil, err
+	}
+	sql.WriteString(conflict)
+
 	// RETURNING
 	if len(b.returning) > 0 {
 		if !b.dialect.SupportsReturning() {
 			return "", nil, fmt.Errorf("driver does not support RETURNING clause")
 		}
 		sql.WriteString(" RETURNING ")
-		sql.WriteString(strings.Join(b.returning, ", "))
+		sql.WriteString(strings.Join(quoteAll(quote, b.returning), ", "))
 	}
 
 	return sql.String(), args, nil
 }
+
+// omitDefaultColumns drops the columns every row sets to Default, for
+// dialects without the DEFAULT keyword in VALUES; a column mixing Default
+// with other values cannot be expressed there
+func (b *InsertBuilder) omitDefaultColumns(rows []map[string]interface{}, columns []string, defaults map[string]interface{}) ([]string, error) {
+	kept := columns[:0:0]
+	for _, col := range columns {
+		n := 0
+		for _, row := range rows {
+			if b.resolveValue(row, col, defaults) == Default {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+			kept = append(kept, col)
+		case len(rows):
+			// the database default applies to an omitted column
+		default:
+			return nil, fmt.Errorf("column %q mixes Default with values: %w", col, dialect.Require(b.dialect, dialect.FeatureDefaultKeyword))
+		}
+	}
+	return kept, nil
+}
+
+// resolveValue picks the value for col: explicit Set, then the row value,
+// then the column default. Missing values are bound as NULL, or render as
+// DEFAULT when OmitZero dropped them.
+func (b *InsertBuilder) resolveValue(row map[string]interface{}, col string, defaults map[string]interface{}) interface{} {
+	if val, ok := b.sets[col]; ok {
+		return val
+	}
+	if val, ok := row[col]; ok {
+		return val
+	}
+	if val, ok := defaults[col]; ok {
+		return val
+	}
+	if b.omitZero {
+		return Default
+	}
+	return nil
+}
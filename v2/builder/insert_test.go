@@ -0,0 +1,835 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type AccountsColumns struct {
+	ID        *table.Column[int64]
+	Name      *table.Column[string]
+	Status    *table.Column[string]
+	CreatedAt *table.Column[time.Time]
+}
+
+type Account struct {
+	Name   string `sql:"name"`
+	Status string `sql:"status"`
+}
+
+func newAccountsTable() *table.Table[AccountsColumns] {
+	return table.NewTable("accounts", AccountsColumns{
+		ID:        table.Col[int64]("id").PrimaryKey().AutoIncrement().Default(0),
+		Name:      table.Col[string]("name").NotNull(),
+		Status:    table.Col[string]("status").Default("active"),
+		CreatedAt: table.Col[time.Time]("created_at").DefaultCurrentTimestamp(),
+	})
+}
+
+func TestInsertColumnDefaultPrecedence(t *testing.T) {
+	accounts := newAccountsTable()
+
+	tests := []struct {
+		name     string
+		build    func() *InsertBuilder
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "column default",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Values(map[string]interface{}{"name": "acme"})
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"acme", "active"},
+		},
+		{
+			name: "struct value over default",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Values(Account{Name: "acme", Status: "trial"})
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"acme", "trial"},
+		},
+		{
+			name: "explicit set over struct value",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Set("status", "suspended").
+					Values(Account{Name: "acme", Status: "trial"})
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"acme", "suspended"},
+		},
+		{
+			name: "explicit set over column default",
+			build: func() *InsertBuilder {
+				created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Set("name", "acme").
+					Set("created_at", created)
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, ?)",
+			wantArgs: []interface{}{"acme", "active", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.build().ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInsertAllReturning(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+
+	mock.ExpectQuery("INSERT INTO users (name, email, age) VALUES (?, ?, ?), (?, ?, ?) RETURNING id, name").
+		WithArgs("john", "john@example.com", 30, "jane", "jane@example.com", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "john").AddRow(int64(2), "jane"))
+
+	var got []User
+	err := NewInsert(conn.Dialect(), users).WithConnection(conn).
+		Values([]map[string]interface{}{
+			{"name": "john", "email": "john@example.com", "age": 30},
+			{"name": "jane", "email": "jane@example.com", "age": 25},
+		}).
+		Returning("id", "name").
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	want := []User{{ID: 1, Name: "john"}, {ID: 2, Name: "jane"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestInsertAllRequiresReturning(t *testing.T) {
+	conn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	users := newUsersTable()
+
+	var got []User
+	if err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").All(context.Background(), &got); err == nil {
+		t.Fatal("expected error without RETURNING")
+	}
+	err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").Returning("id").All(context.Background(), &got)
+	if err == nil || err.Error() != "RETURNING is not supported by the mysql dialect" {
+		t.Fatalf("All() error = %v, want capability error", err)
+	}
+}
+
+// AccountForm marks server-managed columns readonly and lets an empty status
+// fall back to the column default
+type AccountForm struct {
+	ID        int64     `sql:"id,readonly"`
+	Name      string    `sql:"name"`
+	Status    string    `sql:"status,omitempty"`
+	CreatedAt time.Time `sql:"created_at,readonly"`
+}
+
+func TestInsertTagOptions(t *testing.T) {
+	accounts := newAccountsTable()
+
+	tests := []struct {
+		name     string
+		form     AccountForm
+		wantArgs []interface{}
+	}{
+		{
+			name:     "omitempty zero uses default",
+			form:     AccountForm{ID: 5, Name: "acme", CreatedAt: time.Now()},
+			wantArgs: []interface{}{"acme", "active"},
+		},
+		{
+			name:     "omitempty non-zero is inserted",
+			form:     AccountForm{ID: 5, Name: "acme", Status: "trial"},
+			wantArgs: []interface{}{"acme", "trial"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := NewInsert(&postgres.PostgresDialect{}, accounts).Values(tt.form).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			// readonly id and created_at are never taken from the struct
+			if want := "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)"; sql != want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInsertValuesReturning(t *testing.T) {
+	accounts := newAccountsTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery("INSERT INTO accounts (name, status, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP) RETURNING id, name, status, created_at").
+		WithArgs("acme", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "created_at"}).
+			AddRow(int64(7), "acme", "active", created))
+
+	rec := AccountForm{Name: "acme"}
+	res, err := NewInsert(conn.Dialect(), accounts).WithConnection(conn).
+		ValuesReturning(&rec).
+		Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	want := AccountForm{ID: 7, Name: "acme", Status: "active", CreatedAt: created}
+	if rec != want {
+		t.Fatalf("record = %+v, want %+v", rec, want)
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		t.Fatalf("RowsAffected() = %d, %v; want 1", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestInsertValuesReturningKeepsOtherFields(t *testing.T) {
+	type AccountRecord struct {
+		AccountForm
+		Note string // not a table column
+	}
+	accounts := newAccountsTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("INSERT INTO accounts (name, status, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP) RETURNING id").
+		WithArgs("acme", "trial").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	rec := AccountRecord{AccountForm: AccountForm{Name: "acme", Status: "trial"}, Note: "keep"}
+	_, err := NewInsert(conn.Dialect(), accounts).WithConnection(conn).
+		ValuesReturning(&rec).
+		Returning("id").
+		Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	want := AccountRecord{AccountForm: AccountForm{ID: 7, Name: "acme", Status: "trial"}, Note: "keep"}
+	if rec != want {
+		t.Fatalf("record = %+v, want %+v", rec, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestInsertValuesReturningErrors(t *testing.T) {
+	users := newUsersTable()
+	conn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	ctx := context.Background()
+
+	_, err := NewInsert(conn.Dialect(), users).WithConnection(conn).ValuesReturning(User{Name: "john"}).Exec(ctx)
+	if err == nil || !strings.Contains(err.Error(), "pointer to a struct") {
+		t.Fatalf("Exec() error = %v, want pointer error", err)
+	}
+	_, err = NewInsert(conn.Dialect(), users).WithConnection(conn).ValuesReturning(&User{Name: "john"}).Exec(ctx)
+	if err == nil || err.Error() != "RETURNING is not supported by the mysql dialect" {
+		t.Fatalf("Exec() error = %v, want capability error", err)
+	}
+}
+
+func TestInsertOmitZero(t *testing.T) {
+	type AccountRow struct {
+		Name      string    `sql:"name"`
+		Status    string    `sql:"status"`
+		CreatedAt time.Time `sql:"created_at"`
+	}
+	accounts := newAccountsTable()
+	row := AccountRow{Name: "acme"}
+
+	sql, args, err := NewInsert(&postgres.PostgresDialect{}, accounts).Values(row).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, ?)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{"acme", "", time.Time{}}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+
+	sql, args, err = NewInsert(&postgres.PostgresDialect{}, accounts).Values(row).OmitZero().ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{"acme", "active"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+}
+
+func TestInsertOmitZeroMultiRow(t *testing.T) {
+	type Row struct {
+		ID   int64  `sql:"id"`
+		Name string `sql:"name"`
+		Age  int    `sql:"age"`
+	}
+	users := newUsersTable()
+	rows := []Row{{ID: 1, Name: "a", Age: 3}, {ID: 2, Name: "b"}}
+
+	sql, args, err := NewInsert(&postgres.PostgresDialect{}, users).Values(rows).OmitZero().ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "INSERT INTO users (id, name, age) VALUES (?, ?, ?), (?, ?, DEFAULT)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{int64(1), "a", 3, int64(2), "b"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+
+	_, _, err = NewInsert(&sqlite.SQLiteDialect{}, users).Values(rows).OmitZero().ToSQL()
+	if err == nil || !strings.Contains(err.Error(), `column "age" mixes Default with values`) {
+		t.Fatalf("ToSQL() error = %v, want mixed DEFAULT error", err)
+	}
+}
+
+func TestInsertSetAppliesToEveryRow(t *testing.T) {
+	accounts := newAccountsTable()
+
+	sql, args, err := NewInsert(&postgres.PostgresDialect{}, accounts).
+		Values([]Account{{Name: "a", Status: "x"}, {Name: "b", Status: "y"}}).
+		Set("status", "z").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, CURRENT_TIMESTAMP), (?, ?, CURRENT_TIMESTAMP)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{"a", "z", "b", "z"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+}
+
+func TestInsertDefaultKeyword(t *testing.T) {
+	accounts := newAccountsTable()
+
+	tests := []struct {
+		name     string
+		build    func() *InsertBuilder
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "set",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Values(map[string]interface{}{"name": "acme"}).
+					Set("status", Default)
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, DEFAULT, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"acme"},
+		},
+		{
+			name: "per row values",
+			build: func() *InsertBuilder {
+				return NewInsert(&mysql.MySQLDialect{}, accounts).
+					Values([]map[string]interface{}{
+						{"name": "a", "status": Default},
+						{"name": "b", "status": "trial"},
+					})
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, DEFAULT, CURRENT_TIMESTAMP), (?, ?, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"a", "b", "trial"},
+		},
+		{
+			name: "kept by omit zero",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, accounts).
+					Values(map[string]interface{}{"name": "acme", "created_at": Default}).
+					OmitZero()
+			},
+			wantSQL:  "INSERT INTO accounts (name, status, created_at) VALUES (?, ?, DEFAULT)",
+			wantArgs: []interface{}{"acme", "active"},
+		},
+		{
+			name: "sqlite omits the column",
+			build: func() *InsertBuilder {
+				return NewInsert(&sqlite.SQLiteDialect{}, accounts).
+					Values([]Account{{Name: "a"}, {Name: "b"}}).
+					Set("status", Default)
+			},
+			wantSQL:  "INSERT INTO accounts (name, created_at) VALUES (?, CURRENT_TIMESTAMP), (?, CURRENT_TIMESTAMP)",
+			wantArgs: []interface{}{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.build().ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	_, _, err := NewInsert(&sqlite.SQLiteDialect{}, accounts).
+		Values([]map[string]interface{}{
+			{"name": "a", "status": Default},
+			{"name": "b", "status": "trial"},
+		}).
+		ToSQL()
+	if err == nil || err.Error() != `column "status" mixes Default with values: DEFAULT in VALUES is not supported by the sqlite dialect` {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+}
+
+func TestInsertOnConflict(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		build   func() *InsertBuilder
+		wantSQL string
+	}{
+		{
+			name: "columns do update",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).
+					Values(map[string]interface{}{"email": "a@b.c", "name": "ann"}).
+					OnConflict("email").
+					DoUpdate("name")
+			},
+			wantSQL: "INSERT INTO users (name, email) VALUES (?, ?) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+		},
+		{
+			name: "constraint do update",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).
+					Values(map[string]interface{}{"email": "a@b.c", "name": "ann"}).
+					OnConflictConstraint("users_lower_email_key").
+					DoUpdate("name", "email").
+					Returning("id")
+			},
+			wantSQL: "INSERT INTO users (name, email) VALUES (?, ?) ON CONFLICT ON CONSTRAINT users_lower_email_key DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email RETURNING id",
+		},
+		{
+			name: "constraint do nothing",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).
+					Values(map[string]interface{}{"email": "a@b.c", "name": "ann"}).
+					OnConflictConstraint("users_email_key").
+					DoNothing()
+			},
+			wantSQL: "INSERT INTO users (name, email) VALUES (?, ?) ON CONFLICT ON CONSTRAINT users_email_key DO NOTHING",
+		},
+		{
+			name: "sqlite columns",
+			build: func() *InsertBuilder {
+				return NewInsert(&sqlite.SQLiteDialect{}, users).
+					Values(map[string]interface{}{"email": "a@b.c", "name": "ann"}).
+					OnConflict("email").
+					DoUpdate("name")
+			},
+			wantSQL: "INSERT INTO users (name, email) VALUES (?, ?) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.build().ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if want := []interface{}{"ann", "a@b.c"}; !reflect.DeepEqual(args, want) {
+				t.Fatalf("args = %v, want %v", args, want)
+			}
+		})
+	}
+}
+
+func TestInsertOnConflictErrors(t *testing.T) {
+	users := newUsersTable()
+	row := map[string]interface{}{"email": "a@b.c", "name": "ann"}
+
+	tests := []struct {
+		name    string
+		build   func() *InsertBuilder
+		wantErr string
+	}{
+		{
+			name: "both targets",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).Values(row).
+					OnConflict("email").OnConflictConstraint("users_email_key").DoNothing()
+			},
+			wantErr: "OnConflictConstraint cannot be combined with OnConflict",
+		},
+		{
+			name: "both targets reversed",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).Values(row).
+					OnConflictConstraint("users_email_key").OnConflict("email").DoNothing()
+			},
+			wantErr: "OnConflict cannot be combined with OnConflictConstraint",
+		},
+		{
+			name: "missing action",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).Values(row).OnConflict("email")
+			},
+			wantErr: "OnConflict requires DoUpdate or DoNothing",
+		},
+		{
+			name: "update without target",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).Values(row).DoUpdate("name")
+			},
+			wantErr: "DoUpdate requires OnConflict or OnConflictConstraint",
+		},
+		{
+			name: "or ignore",
+			build: func() *InsertBuilder {
+				return NewInsert(&postgres.PostgresDialect{}, users).Values(row).OrIgnore().OnConflict("email").DoNothing()
+			},
+			wantErr: "OrIgnore cannot be combined with an ON CONFLICT clause",
+		},
+		{
+			name: "constraint on sqlite",
+			build: func() *InsertBuilder {
+				return NewInsert(&sqlite.SQLiteDialect{}, users).Values(row).OnConflictConstraint("users_email_key").DoNothing()
+			},
+			wantErr: "ON CONFLICT ON CONSTRAINT is not supported by the sqlite dialect",
+		},
+		{
+			name: "mysql",
+			build: func() *InsertBuilder {
+				return NewInsert(&mysql.MySQLDialect{}, users).Values(row).OnConflict("email").DoUpdate("name")
+			},
+			wantErr: "ON CONFLICT is not supported by the mysql dialect",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.build().ToSQL()
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("ToSQL() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInsertExecGetID(t *testing.T) {
+	users := newUsersTable()
+
+	t.Run("returning", func(t *testing.T) {
+		conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+		mock.ExpectQuery("INSERT INTO users (name) VALUES ($1) RETURNING id").
+			WithArgs("john").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+		id, err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").ExecGetID(context.Background())
+		if err != nil {
+			t.Fatalf("ExecGetID() error = %v", err)
+		}
+		if id != 42 {
+			t.Fatalf("id = %d, want 42", id)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("last insert id", func(t *testing.T) {
+		conn, mock := newTestConn(t, &mysql.MySQLDialect{})
+		mock.ExpectExec("INSERT INTO users (name) VALUES (?)").
+			WithArgs("john").
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		id, err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").ExecGetID(context.Background())
+		if err != nil {
+			t.Fatalf("ExecGetID() error = %v", err)
+		}
+		if id != 7 {
+			t.Fatalf("id = %d, want 7", id)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("table-level primary key", func(t *testing.T) {
+		accounts := table.NewTable("accounts", UsersColumns{
+			ID:   table.Col[int64]("id"),
+			Name: table.Col[string]("name"),
+		}).PrimaryKey("id")
+		conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+		mock.ExpectQuery("INSERT INTO accounts (name) VALUES ($1) RETURNING id").
+			WithArgs("john").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+
+		id, err := NewInsert(conn.Dialect(), accounts).WithConnection(conn).Set("name", "john").ExecGetID(context.Background())
+		if err != nil {
+			t.Fatalf("ExecGetID() error = %v", err)
+		}
+		if id != 9 {
+			t.Fatalf("id = %d, want 9", id)
+		}
+	})
+}
+
+func TestInsertExecResult(t *testing.T) {
+	users := newUsersTable()
+
+	t.Run("last insert id", func(t *testing.T) {
+		conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+		mock.ExpectExec("INSERT INTO users (name) VALUES (?)").
+			WithArgs("john").
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		res, err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").ExecResult(context.Background())
+		if err != nil {
+			t.Fatalf("ExecResult() error = %v", err)
+		}
+		if want := (InsertResult{RowsAffected: 1, LastInsertID: 7, HasID: true}); res != want {
+			t.Fatalf("ExecResult() = %+v, want %+v", res, want)
+		}
+	})
+
+	t.Run("no last insert id", func(t *testing.T) {
+		conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+		mock.ExpectExec("INSERT INTO users (name) VALUES ($1)").
+			WithArgs("john").
+			WillReturnResult(driverResult{rowsAffected: 1})
+
+		res, err := NewInsert(conn.Dialect(), users).WithConnection(conn).Set("name", "john").ExecResult(context.Background())
+		if err != nil {
+			t.Fatalf("ExecResult() error = %v", err)
+		}
+		if want := (InsertResult{RowsAffected: 1}); res != want {
+			t.Fatalf("ExecResult() = %+v, want %+v", res, want)
+		}
+	})
+}
+
+// driverResult mimics a driver (such as lib/pq) without LastInsertId support
+type driverResult struct {
+	rowsAffected int64
+}
+
+func (r driverResult) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported by this driver")
+}
+
+func (r driverResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type Article struct {
+	ID   int64    `sql:"id"`
+	Tags []string `sql:"tags"`
+	Refs []int64  `sql:"refs"`
+}
+
+type ArticlesColumns struct {
+	ID   *table.Column[int64]
+	Tags *table.Column[[]string]
+	Refs *table.Column[[]int64]
+}
+
+func newArticlesTable() *table.Table[ArticlesColumns] {
+	return table.NewTable("articles", ArticlesColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		Tags: table.Col[[]string]("tags"),
+		Refs: table.Col[[]int64]("refs"),
+	})
+}
+
+func TestInsertArrayRoundTrip(t *testing.T) {
+	articles := newArticlesTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("INSERT INTO articles (id, tags, refs) VALUES ($1, $2, $3) RETURNING id, tags, refs").
+		WithArgs(int64(1), `{"go","sql"}`, "{4,5}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "refs"}).AddRow(int64(1), []byte(`{go,sql}`), []byte("{4,5}")))
+	mock.ExpectQuery("SELECT id FROM articles WHERE articles.tags @> ARRAY[$1]").
+		WithArgs("go").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	var got Article
+	err := NewInsert(conn.Dialect(), articles).
+		WithConnection(conn).
+		Values(Article{ID: 1, Tags: []string{"go", "sql"}, Refs: []int64{4, 5}}).
+		Returning("id", "tags", "refs").
+		One(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("One() error = %v", err)
+	}
+	if want := (Article{ID: 1, Tags: []string{"go", "sql"}, Refs: []int64{4, 5}}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("One() = %+v, want %+v", got, want)
+	}
+
+	var ids []int64
+	err = NewSelect(articles).
+		WithConnection(conn).
+		Select("id").
+		Where(expr.ArrayContains(articles.C.Tags, "go")).
+		All(context.Background(), &ids)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+type ProfilesColumns struct {
+	ID       *table.Column[int64]
+	Nickname *table.Column[sql.NullString]
+	Logins   *table.Column[sql.NullInt64]
+	Level    *table.Column[sql.NullInt32]
+	Score    *table.Column[sql.NullFloat64]
+	Verified *table.Column[sql.NullBool]
+	SeenAt   *table.Column[sql.NullTime]
+	Rank     *table.Column[sql.Null[int64]]
+}
+
+type Profile struct {
+	ID       int64           `sql:"id"`
+	Nickname sql.NullString  `sql:"nickname"`
+	Logins   sql.NullInt64   `sql:"logins"`
+	Level    sql.NullInt32   `sql:"level"`
+	Score    sql.NullFloat64 `sql:"score"`
+	Verified sql.NullBool    `sql:"verified"`
+	SeenAt   sql.NullTime    `sql:"seen_at"`
+	Rank     sql.Null[int64] `sql:"rank"`
+}
+
+func TestInsertNullRoundTrip(t *testing.T) {
+	profiles := table.NewTable("profiles", ProfilesColumns{
+		ID:       table.Col[int64]("id").PrimaryKey(),
+		Nickname: table.Col[sql.NullString]("nickname"),
+		Logins:   table.Col[sql.NullInt64]("logins"),
+		Level:    table.Col[sql.NullInt32]("level"),
+		Score:    table.Col[sql.NullFloat64]("score"),
+		Verified: table.Col[sql.NullBool]("verified"),
+		SeenAt:   table.Col[sql.NullTime]("seen_at"),
+		Rank:     table.Col[sql.Null[int64]]("rank"),
+	})
+	columns := []string{"id", "nickname", "logins", "level", "score", "verified", "seen_at", "rank"}
+
+	tests := []struct {
+		name      string
+		dialect   dialect.Dialect
+		insertSQL string
+		selectSQL string
+	}{
+		{
+			name:      "postgres",
+			dialect:   &postgres.PostgresDialect{},
+			insertSQL: "INSERT INTO profiles (id, nickname, logins, level, score, verified, seen_at, rank) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			selectSQL: "SELECT * FROM profiles WHERE profiles.id = $1",
+		},
+		{
+			name:      "sqlite",
+			dialect:   &sqlite.SQLiteDialect{},
+			insertSQL: "INSERT INTO profiles (id, nickname, logins, level, score, verified, seen_at, rank) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			selectSQL: "SELECT * FROM profiles WHERE profiles.id = ?",
+		},
+		{
+			name:      "mysql",
+			dialect:   &mysql.MySQLDialect{},
+			insertSQL: "INSERT INTO profiles (id, nickname, logins, level, score, verified, seen_at, rank) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			selectSQL: "SELECT * FROM profiles WHERE profiles.id = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock := newTestConn(t, tt.dialect)
+			mock.ExpectExec(tt.insertSQL).
+				WithArgs(int64(1), nil, nil, nil, nil, nil, nil, nil).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(tt.selectSQL).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(1), nil, nil, nil, nil, nil, nil, nil))
+
+			in := Profile{ID: 1, Rank: table.NullIf(true, int64(3))}
+			if _, err := NewInsert(conn.Dialect(), profiles).WithConnection(conn).Values(in).Exec(context.Background()); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			out := Profile{
+				Nickname: sql.NullString{String: "stale", Valid: true},
+				Rank:     sql.Null[int64]{V: 9, Valid: true},
+			}
+			err := NewSelect(profiles).
+				WithConnection(conn).
+				Where(expr.Eq(profiles.C.ID, int64(1))).
+				One(context.Background(), &out)
+			if err != nil {
+				t.Fatalf("One() error = %v", err)
+			}
+			if want := (Profile{ID: 1}); !reflect.DeepEqual(out, want) {
+				t.Fatalf("One() = %+v, want %+v", out, want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestInsertArrayRequiresArrayDialect(t *testing.T) {
+	articles := newArticlesTable()
+	conn, _ := newTestConn(t, &sqlite.SQLiteDialect{})
+
+	_, err := NewInsert(conn.Dialect(), articles).
+		WithConnection(conn).
+		Values(Article{ID: 1, Tags: []string{"go"}}).
+		Exec(context.Background())
+	if err == nil || err.Error() != "ARRAY is not supported by the sqlite dialect" {
+		t.Fatalf("Exec() error = %v, want arrays unsupported", err)
+	}
+}
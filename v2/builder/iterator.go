@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RowIterator streams query results one row at a time so large result sets
+// can be processed with bounded memory. Always Close it when done.
+type RowIterator struct {
+	ctx  context.Context
+	rows *sql.Rows
+	stmt *statement
+	scan scanOptions
+	err  error // context error that stopped the iteration
+}
+
+// Next prepares the next row for Scan, returning false when done or on error.
+// Once the statement context is done it closes the rows, releasing the
+// connection, and Err reports the context error.
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		it.Close()
+		return false
+	}
+	return it.rows.Next()
+}
+
+// Scan scans the current row into dest using the same rules as All:
+// structs are mapped by column name, other types are scanned positionally
+func (it *RowIterator) Scan(dest interface{}) error {
+	return scanRow(it.rows, dest, it.scan)
+}
+
+// Err returns the error, if any, encountered during iteration
+func (it *RowIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close releases the underlying rows and reports the finished statement
+func (it *RowIterator) Close() error {
+	err := it.rows.Close()
+	if it.stmt != nil {
+		it.stmt.done(-1, it.Err())
+		it.stmt = nil
+	}
+	return err
+}
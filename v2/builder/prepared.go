@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// Preparer is implemented by connections that can prepare statements, e.g.
+// engine.Connection (on its open transaction, if any)
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// PreparedQuery is a SELECT prepared once and executed many times with new
+// args through Bind. Close it when done.
+type PreparedQuery struct {
+	conn  ConnectionInterface
+	table table.TableInterface
+	cols  []table.ColumnReference // typed columns selected by the builder
+	stmt  *sql.Stmt
+	sql   string        // builder SQL with ? placeholders
+	args  []interface{} // builder args; Bind replaces them
+}
+
+// Prepare renders b and prepares it on conn. The args b was built with fix
+// the number and order of the placeholders; Bind supplies new values for
+// them in the same order, e.g. a query built with
+// Where(expr.Eq(Users.C.Age, 0)).Limit(10) is re-bound as Bind(age, limit).
+// A statement prepared inside a transaction is only valid until it ends.
+func Prepare(ctx context.Context, conn ConnectionInterface, b *SelectBuilder) (*PreparedQuery, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("builder has no connection")
+	}
+	preparer, ok := conn.(Preparer)
+	if !ok {
+		return nil, fmt.Errorf("connection %T cannot prepare statements", conn)
+	}
+	if ctx == nil {
+		ctx = conn.Context()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	bound := *b
+	bound.conn = conn
+	rawSQL, args, err := bound.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := preparer.PrepareContext(ctx, FormatPlaceholders(rawSQL, conn.Dialect()))
+	if err != nil {
+		return nil, err
+	}
+	return &PreparedQuery{conn: conn, table: b.table, cols: b.typedCols, stmt: stmt, sql: rawSQL, args: args}, nil
+}
+
+// NumArgs returns the number of args Bind expects
+func (p *PreparedQuery) NumArgs() int {
+	return len(p.args)
+}
+
+// Bind returns the query with args in place of the builder's args, in
+// placeholder order. Without args the builder's own args are used. Values
+// for Sensitive columns stay redacted in logs.
+func (p *PreparedQuery) Bind(args ...interface{}) *BoundQuery {
+	if len(args) == 0 {
+		return &BoundQuery{query: p, args: p.args}
+	}
+	if len(args) != len(p.args) {
+		return &BoundQuery{query: p, err: fmt.Errorf("Bind got %d args, the prepared query has %d placeholders", len(args), len(p.args))}
+	}
+	bound := make([]interface{}, len(args))
+	for i, arg := range args {
+		if _, ok := p.args[i].(table.SensitiveValue); ok {
+			arg = table.SensitiveValue{Val: arg}
+		}
+		bound[i] = arg
+	}
+	return &BoundQuery{query: p, args: bound}
+}
+
+// Close releases the prepared statement
+func (p *PreparedQuery) Close() error {
+	return p.stmt.Close()
+}
+
+// BoundQuery is a prepared query with its args, ready to execute
+type BoundQuery struct {
+	query *PreparedQuery
+	args  []interface{}
+	err   error
+}
+
+// ToSQL returns the prepared SQL and the bound args
+func (b *BoundQuery) ToSQL() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.query.sql, b.args, nil
+}
+
+// targetTable returns the table the statement operates on
+func (b *BoundQuery) targetTable() table.TableInterface {
+	return b.query.table
+}
+
+// typedColumns returns the typed columns the prepared query selects
+func (b *BoundQuery) typedColumns() []table.ColumnReference {
+	return b.query.cols
+}
+
+// All executes the prepared statement and scans every row into dest
+func (b *BoundQuery) All(ctx context.Context, dest interface{}) (err error) {
+	st, err := prepare(ctx, b.query.conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := b.query.stmt.QueryContext(st.ctx, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanAll(st.ctx, rows, dest, newScanOptions(b.query.conn, b))
+}
+
+// One executes the prepared statement and scans exactly one row into dest
+func (b *BoundQuery) One(ctx context.Context, dest interface{}) (err error) {
+	st, err := prepare(ctx, b.query.conn, b)
+	if err != nil {
+		return err
+	}
+	defer func() { st.done(-1, err) }()
+
+	rows, err := b.query.stmt.QueryContext(st.ctx, st.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanOne(rows, dest, newScanOptions(b.query.conn, b))
+}
@@ -0,0 +1,56 @@
+package builder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+)
+
+func TestPreparedQueryBind(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	prep := mock.ExpectPrepare("SELECT id, name FROM users WHERE users.age > $1 LIMIT $2")
+	prep.ExpectQuery().WithArgs(18, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "john"))
+	prep.ExpectQuery().WithArgs(40, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "jane"))
+	prep.WillBeClosed()
+
+	q := NewSelect(users).Select("id", "name").Where(expr.Gt(users.C.Age, 18)).Limit(10)
+	p, err := Prepare(context.Background(), conn, q)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if p.NumArgs() != 2 {
+		t.Fatalf("NumArgs() = %d, want 2", p.NumArgs())
+	}
+
+	var got []User
+	if err := p.Bind().All(context.Background(), &got); err != nil {
+		t.Fatalf("Bind().All() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "john" {
+		t.Fatalf("Bind().All() = %+v", got)
+	}
+	var u User
+	if err := p.Bind(40, 2).One(context.Background(), &u); err != nil {
+		t.Fatalf("Bind(40, 2).One() error = %v", err)
+	}
+	if u.Name != "jane" {
+		t.Fatalf("Bind(40, 2).One() = %+v", u)
+	}
+
+	if err := p.Bind(40).All(context.Background(), &got); err == nil {
+		t.Fatalf("Bind with a wrong arg count should fail")
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
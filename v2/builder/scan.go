@@ -1,16 +1,62 @@
 package builder
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
 	"github.com/kisielk/sqlstruct"
 )
 
+// scanOptions carries the connection settings that affect scanning
+type scanOptions struct {
+	lenient bool                    // discard columns without a matching field
+	merge   bool                    // keep fields without a column instead of zeroing the struct
+	arrays  dialect.ArrayConverter  // decodes array columns (nil without FeatureArrays)
+	mapper  fieldMapping            // names untagged struct fields
+	columns []table.ColumnReference // typed columns behind the leading result columns
+}
+
+// typedColumnsBuilder is implemented by queries selecting typed columns
+type typedColumnsBuilder interface {
+	typedColumns() []table.ColumnReference
+}
+
+// fieldMapping is a table's field name mapper; the table keys the struct
+// field cache, as mapper funcs are not comparable
+type fieldMapping struct {
+	table  table.TableInterface
+	mapper func(string) string
+}
+
+// newScanOptions reads the scan settings of conn and the field mapper of
+// the table b targets
+func newScanOptions(conn ConnectionInterface, b Builder) scanOptions {
+	opts := scanOptions{lenient: conn.LenientScan()}
+	if tb, ok := b.(tableBuilder); ok && tb.targetTable() != nil {
+		if mapper := fieldMapper(tb.targetTable()); mapper != nil {
+			opts.mapper = fieldMapping{table: tb.targetTable(), mapper: mapper}
+		}
+	}
+	if tb, ok := b.(typedColumnsBuilder); ok {
+		opts.columns = tb.typedColumns()
+	}
+	if d := conn.Dialect(); d != nil && d.Supports(dialect.FeatureArrays) {
+		opts.arrays, _ = d.(dialect.ArrayConverter)
+	}
+	return opts
+}
+
 // scanAll reads every row and appends it to the destination slice.
 // dest must be a pointer to a slice of structs, pointers to structs, or basic types.
-func scanAll(rows *sql.Rows, dest interface{}) error {
+// It stops with the context error once ctx is done; the caller closes rows.
+func scanAll(ctx context.Context, rows *sql.Rows, dest interface{}, opts scanOptions) error {
 	rv := reflect.ValueOf(dest)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("dest must be a non-nil pointer to a slice")
@@ -24,9 +70,12 @@ func scanAll(rows *sql.Rows, dest interface{}) error {
 	elemType := sliceVal.Type().Elem()
 
 	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// Allocate a new element and pick an addressable scan target.
 		elemVal, scanTarget := newScanTarget(elemType)
-		if err := scanRow(rows, scanTarget); err != nil {
+		if err := scanRow(rows, scanTarget, opts); err != nil {
 			return err
 		}
 
@@ -48,7 +97,7 @@ func scanAll(rows *sql.Rows, dest interface{}) error {
 
 // scanOne reads exactly one row into dest, erroring on zero or multiple rows.
 // dest must be a non-nil pointer to a struct, pointer-to-struct, or basic type.
-func scanOne(rows *sql.Rows, dest interface{}) error {
+func scanOne(rows *sql.Rows, dest interface{}, opts scanOptions) error {
 	if !rows.Next() {
 		if err := rows.Err(); err != nil {
 			return err
@@ -56,7 +105,7 @@ func scanOne(rows *sql.Rows, dest interface{}) error {
 		return sql.ErrNoRows
 	}
 
-	if err := scanRow(rows, dest); err != nil {
+	if err := scanRow(rows, dest, opts); err != nil {
 		return err
 	}
 
@@ -68,26 +117,29 @@ func scanOne(rows *sql.Rows, dest interface{}) error {
 }
 
 // scanRow routes scanning based on the destination type.
-// Structs use sqlstruct to map columns; non-structs fall back to rows.Scan.
-func scanRow(rows *sql.Rows, dest interface{}) error {
+// Structs map columns to fields (see structFields); non-structs fall back to rows.Scan.
+func scanRow(rows *sql.Rows, dest interface{}, opts scanOptions) error {
 	rv := reflect.ValueOf(dest)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("dest must be a non-nil pointer")
 	}
 
 	elem := rv.Elem()
-	if elem.Kind() == reflect.Struct {
-		return sqlstruct.Scan(dest, rows)
+	if elem.Kind() == reflect.Struct && !isScalarStruct(elem.Type()) {
+		return scanStruct(rows, elem, opts)
 	}
 
-	if elem.Kind() == reflect.Ptr && elem.Type().Elem().Kind() == reflect.Struct {
+	if elem.Kind() == reflect.Ptr && elem.Type().Elem().Kind() == reflect.Struct && !isScalarStruct(elem.Type().Elem()) {
 		// Ensure the pointer is initialized before scanning.
 		if elem.IsNil() {
 			elem.Set(reflect.New(elem.Type().Elem()))
 		}
-		return sqlstruct.Scan(elem.Interface(), rows)
+		return scanStruct(rows, elem.Elem(), opts)
 	}
 
+	if opts.arrays != nil && isArrayValue(elem.Type()) {
+		return rows.Scan(opts.arrays.Array(dest))
+	}
 	return rows.Scan(dest)
 }
 
@@ -102,3 +154,281 @@ func newScanTarget(elemType reflect.Type) (reflect.Value, interface{}) {
 	elemVal := reflect.New(elemType)
 	return elemVal, elemVal.Interface()
 }
+
+// scanStruct scans the current row into the struct v, matching columns to
+// fields with the same rules as inserts (sql tag, else snake_case name).
+// A column without a matching field is an error listing every unmatched
+// column, unless lenient is set, in which case it is discarded. Fields
+// without a column are left at their zero value, so a query selecting a
+// subset of columns scans into the full struct.
+func scanStruct(rows *sql.Rows, v reflect.Value, opts scanOptions) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	// Reset a reused destination so unselected fields don't keep old values
+	if !opts.merge {
+		v.Set(reflect.Zero(v.Type()))
+	}
+
+	fields := structFields(v.Type(), opts.mapper)
+	targets := make([]interface{}, len(cols))
+	var unmatched []string
+	for i, col := range cols {
+		path, ok := fields[strings.ToLower(col)]
+		// A typed column first matches a field tagged with its table, which
+		// tells apart same-named columns of joined tables
+		if i < len(opts.columns) {
+			if p, found := fields[strings.ToLower(opts.columns[i].FullName())]; found {
+				path, ok = p, true
+			}
+		}
+		if !ok {
+			unmatched = append(unmatched, col)
+			targets[i] = new(interface{})
+			continue
+		}
+		field := fieldByPath(v, path)
+		targets[i] = field.Addr().Interface()
+		if opts.arrays != nil && isArrayValue(field.Type()) {
+			targets[i] = opts.arrays.Array(targets[i])
+		}
+	}
+	if len(unmatched) > 0 && !opts.lenient {
+		err := fmt.Errorf("columns %s have no matching field in %s", strings.Join(unmatched, ", "), v.Type())
+		for _, col := range unmatched {
+			if strings.ContainsAny(col, "(?") {
+				return fmt.Errorf("%w; alias computed columns to a field name, e.g. COUNT(*) AS total", err)
+			}
+		}
+		return err
+	}
+	return rows.Scan(targets...)
+}
+
+var structFieldsCache sync.Map // structFieldsKey -> map[string][]int
+
+// structFieldsKey caches the fields of a type per field mapping table
+type structFieldsKey struct {
+	typ   reflect.Type
+	table table.TableInterface
+}
+
+// structFields maps lower-cased column names to field index paths of typ.
+// Embedded structs are inlined. A struct (or pointer to struct) field tagged
+// with a trailing dot, e.g. `sql:"user."`, is a nested object populated from
+// columns prefixed with its name: user_id, user_name (or "user.id").
+func structFields(typ reflect.Type, mapping fieldMapping) map[string][]int {
+	key := structFieldsKey{typ: typ, table: mapping.table}
+	if cached, ok := structFieldsCache.Load(key); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	collectStructFields(typ, "", nil, fields, mapping.mapper)
+	structFieldsCache.Store(key, fields)
+	return fields
+}
+
+func collectStructFields(typ reflect.Type, prefix string, index []int, fields map[string][]int, mapper func(string) string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag, _ := parseFieldTag(field.Tag.Get(sqlstruct.TagName))
+		if tag == "-" {
+			continue
+		}
+		path := append(append([]int(nil), index...), i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectStructFields(field.Type, prefix, path, fields, mapper)
+			continue
+		}
+
+		if nested, ok := nestedPrefix(tag); ok {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectStructFields(ft, prefix+nested+"_", path, fields, mapper)
+				collectStructFields(ft, prefix+nested+".", path, fields, mapper)
+				continue
+			}
+		}
+
+		if tag == "" {
+			tag = mapFieldName(mapper, field.Name)
+		}
+		name := strings.ToLower(prefix + tag)
+		if _, exists := fields[name]; !exists {
+			fields[name] = path
+		}
+	}
+}
+
+// nestedPrefix reports whether tag declares a nested struct prefix ("user.")
+func nestedPrefix(tag string) (string, bool) {
+	if len(tag) > 1 && strings.HasSuffix(tag, ".") {
+		return strings.TrimSuffix(tag, "."), true
+	}
+	return "", false
+}
+
+// fieldByPath returns the field at path, allocating nil struct pointers on the way
+func fieldByPath(v reflect.Value, path []int) reflect.Value {
+	for i, idx := range path {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// isScalarStruct reports whether the struct type scans as a single value
+// (time.Time, or an sql.Scanner such as sql.NullString) rather than by column
+func isScalarStruct(typ reflect.Type) bool {
+	return typ == timeType || reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// PrefixColumns returns select expressions aliasing every column of tbl with
+// prefix, e.g. "users.name AS author_name", to populate a nested struct field
+// tagged `sql:"author."` from a JOIN.
+func PrefixColumns(tbl table.TableInterface, prefix string) []string {
+	cols := tbl.Columns()
+	exprs := make([]string, len(cols))
+	for i, col := range cols {
+		exprs[i] = tbl.Name() + "." + col.Name + " AS " + prefix + "_" + col.Name
+	}
+	return exprs
+}
+
+// checkScalarDest checks dest points to a single value rather than a struct
+// scanned by column
+func checkScalarDest(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("dest must be a non-nil pointer")
+	}
+	typ := rv.Elem().Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Struct && !isScalarStruct(typ) {
+		return fmt.Errorf("Scalar dest must point to a single value, got %T", dest)
+	}
+	return nil
+}
+
+// scanScalar scans the single value of a one-column, one-row result into
+// dest. Timestamps returned as text, as SQLite does for expressions such as
+// MAX(created_at), are parsed for time.Time and sql.NullTime destinations.
+func scanScalar(rows *sql.Rows, dest interface{}, opts scanOptions) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) != 1 {
+		return fmt.Errorf("Scalar expects exactly one column, got %d", len(columns))
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	target := dest
+	switch dest.(type) {
+	case *time.Time, **time.Time, *sql.NullTime:
+		target = timeScanner{dest: dest}
+	default:
+		if opts.arrays != nil && isArrayValue(reflect.TypeOf(dest).Elem()) {
+			target = opts.arrays.Array(dest)
+		}
+	}
+	if err := rows.Scan(target); err != nil {
+		return err
+	}
+
+	if rows.Next() {
+		return fmt.Errorf("Scalar expects exactly one row")
+	}
+	return rows.Err()
+}
+
+// sqliteTimestampFormats are the text layouts SQLite drivers store time.Time
+// values in
+var sqliteTimestampFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// timeScanner scans into a *time.Time, **time.Time or *sql.NullTime,
+// parsing text timestamps
+type timeScanner struct {
+	dest interface{}
+}
+
+func (s timeScanner) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	if text, ok := src.(string); ok {
+		t, err := parseTimestamp(text)
+		if err != nil {
+			return err
+		}
+		src = t
+	}
+
+	switch d := s.dest.(type) {
+	case *sql.NullTime:
+		return d.Scan(src)
+	case **time.Time:
+		if src == nil {
+			*d = nil
+			return nil
+		}
+		t, ok := src.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot scan %T into %T", src, s.dest)
+		}
+		*d = &t
+	case *time.Time:
+		t, ok := src.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot scan %T into %T; pass a **time.Time or *sql.NullTime to allow NULL", src, s.dest)
+		}
+		*d = t
+	}
+	return nil
+}
+
+// parseTimestamp parses a text timestamp in one of sqliteTimestampFormats,
+// with or without a trailing Z
+func parseTimestamp(text string) (time.Time, error) {
+	text = strings.TrimSuffix(text, "Z")
+	for _, layout := range sqliteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a timestamp", text)
+}
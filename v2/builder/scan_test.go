@@ -0,0 +1,204 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type Timestamps struct {
+	CreatedAt sql.NullTime
+}
+
+type Post struct {
+	ID    int64  `sql:"id"`
+	Title string `sql:"title"`
+	Timestamps
+	Author   User  `sql:"author."`
+	Reviewer *User `sql:"reviewer."`
+}
+
+func TestScanNestedPrefixedStructs(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.lenient = true
+
+	mock.ExpectQuery("SELECT * FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at", "author_id", "author_name", "reviewer.id", "reviewer.name", "extra"}).
+			AddRow(int64(1), "hello", nil, int64(7), "john", int64(8), "jane", "ignored"))
+
+	var posts []Post
+	if err := NewSelect(newUsersTable()).WithConnection(conn).All(context.Background(), &posts); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("got %d rows, want 1", len(posts))
+	}
+	got := posts[0]
+	if got.ID != 1 || got.Title != "hello" || got.CreatedAt.Valid {
+		t.Fatalf("unexpected top-level fields %+v", got)
+	}
+	if got.Author.ID != 7 || got.Author.Name != "john" {
+		t.Fatalf("Author = %+v, want id 7 john", got.Author)
+	}
+	if got.Reviewer == nil || got.Reviewer.ID != 8 || got.Reviewer.Name != "jane" {
+		t.Fatalf("Reviewer = %+v, want id 8 jane", got.Reviewer)
+	}
+}
+
+func TestScanUnmatchedReturningColumns(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("INSERT INTO users (name) VALUES ($1) RETURNING id, crated_at, name").
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "crated_at", "name"}).AddRow(int64(1), nil, "john"))
+
+	var dest User
+	err := NewInsert(&postgres.PostgresDialect{}, newUsersTable()).
+		WithConnection(conn).
+		Values(map[string]interface{}{"name": "john"}).
+		Returning("id", "crated_at", "name").
+		One(context.Background(), &dest)
+	if err == nil || err.Error() != "columns crated_at have no matching field in builder.User" {
+		t.Fatalf("One() error = %v, want unmatched column error", err)
+	}
+}
+
+func TestScanLenientDiscardsUnmatchedColumns(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	conn.lenient = true
+
+	mock.ExpectQuery("SELECT * FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "extra"}).AddRow(int64(1), "john", "ignored"))
+
+	var users []User
+	if err := NewSelect(newUsersTable()).WithConnection(conn).All(context.Background(), &users); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID != 1 || users[0].Name != "john" {
+		t.Fatalf("unexpected users %+v", users)
+	}
+}
+
+type UserProfile struct {
+	ID    int64          `sql:"id"`
+	Name  string         `sql:"name"`
+	Email string         `sql:"email"`
+	Age   int            `sql:"age"`
+	Bio   sql.NullString `sql:"bio"`
+}
+
+func TestScanColumnSubsetLeavesOtherFieldsZero(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	users := newUsersTable()
+
+	mock.ExpectQuery("SELECT users.id, users.name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "john").AddRow(int64(2), "jane"))
+	var all []UserProfile
+	if err := NewSelect(users).WithConnection(conn).Select("users.id", "users.name").All(context.Background(), &all); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	want := []UserProfile{{ID: 1, Name: "john"}, {ID: 2, Name: "jane"}}
+	if len(all) != 2 || all[0] != want[0] || all[1] != want[1] {
+		t.Fatalf("All() = %+v, want %+v", all, want)
+	}
+
+	// A reused destination is reset, not merged with the previous row
+	mock.ExpectQuery("SELECT users.id, users.name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(3), "joe"))
+	dest := UserProfile{ID: 9, Name: "old", Email: "old@example.com", Age: 40, Bio: sql.NullString{String: "old", Valid: true}}
+	if err := NewSelect(users).WithConnection(conn).Select("users.id", "users.name").One(context.Background(), &dest); err != nil {
+		t.Fatalf("One() error = %v", err)
+	}
+	if dest != (UserProfile{ID: 3, Name: "joe"}) {
+		t.Fatalf("One() = %+v, want only id and name set", dest)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScanReadonlyTaggedFields(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT * FROM accounts").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "created_at"}).AddRow(int64(5), "acme", "active", created))
+
+	var got AccountForm
+	if err := NewSelect(newAccountsTable()).WithConnection(conn).One(context.Background(), &got); err != nil {
+		t.Fatalf("One() error = %v", err)
+	}
+	if want := (AccountForm{ID: 5, Name: "acme", Status: "active", CreatedAt: created}); got != want {
+		t.Fatalf("One() = %+v, want %+v", got, want)
+	}
+}
+
+type APIClientsColumns struct {
+	ID         *table.Column[int64]
+	APIKey     *table.Column[string]
+	OAuthToken *table.Column[string]
+}
+
+type APIClient struct {
+	ID         int64
+	APIKey     string
+	OAuthToken string
+}
+
+func TestFieldMapperInsertAndScan(t *testing.T) {
+	clients := table.NewTable("api_clients", APIClientsColumns{
+		ID:         table.Col[int64]("id").PrimaryKey(),
+		APIKey:     table.Col[string]("api_key"),
+		OAuthToken: table.Col[string]("oauth_token"),
+	}).MapFields(func(name string) string {
+		if name == "OAuthToken" {
+			return "oauth_token"
+		}
+		return table.AcronymSnakeCase(name)
+	})
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("INSERT INTO api_clients (id, api_key, oauth_token) VALUES ($1, $2, $3) RETURNING id, api_key, oauth_token").
+		WithArgs(int64(1), "k-1", "t-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "api_key", "oauth_token"}).AddRow(int64(1), "k-1", "t-1"))
+
+	var got APIClient
+	err := NewInsert(conn.Dialect(), clients).
+		WithConnection(conn).
+		Values(APIClient{ID: 1, APIKey: "k-1", OAuthToken: "t-1"}).
+		Returning("id", "api_key", "oauth_token").
+		One(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("One() error = %v", err)
+	}
+	if want := (APIClient{ID: 1, APIKey: "k-1", OAuthToken: "t-1"}); got != want {
+		t.Fatalf("One() = %+v, want %+v", got, want)
+	}
+
+	// Without a mapper the default snake_case keeps acronyms together.
+	_, args, err := NewInsert(conn.Dialect(), newUsersTable()).Values(APIClient{ID: 2, APIKey: "k-2"}).ToSQL()
+	if err != nil || len(args) != 1 || args[0] != int64(2) {
+		t.Fatalf("ToSQL() args = %v, err = %v; want only id", args, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPrefixColumns(t *testing.T) {
+	got := PrefixColumns(newUsersTable(), "author")
+	want := []string{"users.id AS author_id", "users.name AS author_name", "users.email AS author_email", "users.age AS author_age"}
+	if len(got) != len(want) {
+		t.Fatalf("PrefixColumns() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("PrefixColumns() = %v, want %v", got, want)
+		}
+	}
+}
@@ -1,30 +1,50 @@
 package builder
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
 	"github.com/guadalsistema/go-compose-sql/v2/expr"
 	"github.com/guadalsistema/go-compose-sql/v2/table"
 )
 
 // SelectBuilder builds SELECT queries
 type SelectBuilder struct {
-	table      table.TableInterface
-	columns    []string
-	whereExprs []expr.Expr
-	joins      []*JoinClause
-	orderBy    []OrderByClause
-	groupBy    []string
-	having     []expr.Expr
-	limit      *int
-	offset     *int
-	distinct   bool
+	conn        ConnectionInterface
+	table       table.TableInterface
+	columns     []string
+	typedCols   []table.ColumnReference // set by SelectColumns
+	columnExprs []columnExpr
+	whereExprs  []expr.Expr
+	joins       []*JoinClause
+	orderBy     []OrderByClause
+	groupBy     []string
+	grouping    groupingMode
+	groupSets   [][]string
+	having      []expr.Expr
+	limit       *int
+	offset      *int
+	fetch       bool // render limit as FETCH FIRST
+	withTies    bool
+	distinct    bool
+	withDeleted bool
+	strict      bool
+	fromSQL     string // raw FROM source replacing the table, see NewSelectFrom
+	noCache     bool
+	fromArgs    []interface{}
+	lock        string        // row-locking clause, e.g. FOR NO KEY UPDATE
+	lockTimeout time.Duration // SET LOCAL lock_timeout when positive
+	err         error
 }
 
 // JoinClause represents a JOIN operation
 type JoinClause struct {
-	Type      string // "INNER", "LEFT", "RIGHT", "FULL"
+	Type      string // "INNER JOIN", "LEFT JOIN", "RIGHT JOIN", "FULL OUTER JOIN"
 	Table     table.TableInterface
 	Condition expr.Expr
 }
@@ -33,6 +53,14 @@ type JoinClause struct {
 type OrderByClause struct {
 	Column    string
 	Direction string // "ASC" or "DESC"
+	Expr      bool   // Column is an expression: not quoted or validated
+	Nulls     string // "FIRST", "LAST" or "" for the dialect default
+}
+
+// columnExpr is an expression selected under an alias
+type columnExpr struct {
+	expr  expr.Expr
+	alias string
 }
 
 // NewSelect creates a new SELECT builder
@@ -42,9 +70,79 @@ func NewSelect(tbl table.TableInterface) *SelectBuilder {
 	}
 }
 
+// NewSelectFrom creates a SELECT builder reading from a raw FROM source
+// instead of a table, e.g. a set-returning function or a VALUES list:
+// NewSelectFrom("generate_series(?, ?) AS n", 1, 10). The fragment is
+// written as-is with ? placeholders, so it must not contain user input.
+// With no table there are no known columns, so WhereEq and strict column
+// validation have nothing to match against.
+func NewSelectFrom(fromSQL string, args ...interface{}) *SelectBuilder {
+	return &SelectBuilder{
+		table:    rawSource{},
+		fromSQL:  fromSQL,
+		fromArgs: args,
+	}
+}
+
+// rawSource stands in for the table of a NewSelectFrom builder
+type rawSource struct{}
+
+func (rawSource) Name() string                { return "" }
+func (rawSource) Columns() []*table.ColumnRef { return nil }
+func (rawSource) PrimaryKeyColumns() []string { return nil }
+
+// WithConnection binds the builder to a connection so it can be executed
+func (b *SelectBuilder) WithConnection(conn ConnectionInterface) *SelectBuilder {
+	b.conn = conn
+	return b
+}
+
+// targetTable returns the table the statement operates on
+func (b *SelectBuilder) targetTable() table.TableInterface {
+	if b.fromSQL != "" {
+		return nil
+	}
+	return b.table
+}
+
+// typedColumns returns the columns passed to SelectColumns
+func (b *SelectBuilder) typedColumns() []table.ColumnReference {
+	return b.typedCols
+}
+
 // Select specifies which columns to select (defaults to all)
 func (b *SelectBuilder) Select(columns ...string) *SelectBuilder {
 	b.columns = columns
+	b.typedCols = nil
+	return b
+}
+
+// SelectColumns selects typed table columns by their qualified name, e.g.
+// SelectColumns(Users.C.Name, Orders.C.Total) renders users.name,
+// orders.total; it replaces columns passed to Select like a second Select.
+// A struct field tagged with the qualified name (`sql:"orders.id"`), or a
+// nested struct tagged `sql:"orders."`, takes that column when joined tables
+// share a column name; other fields match the bare name.
+func (b *SelectBuilder) SelectColumns(columns ...table.ColumnReference) *SelectBuilder {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.FullName()
+	}
+	b.Select(names...)
+	b.typedCols = columns
+	return b
+}
+
+// SelectExpr adds an expression to the selected columns as alias, e.g.
+// SelectExpr(expr.Coalesce(Users.C.Nickname, Users.C.Name), "display_name").
+// Expressions follow the columns passed to Select; with no Select call only
+// the expressions are selected. Their bound values precede the WHERE args.
+func (b *SelectBuilder) SelectExpr(e expr.Expr, alias string) *SelectBuilder {
+	if !isPlainIdentifier(alias) {
+		b.err = fmt.Errorf("invalid alias %q in SelectExpr", alias)
+		return b
+	}
+	b.columnExprs = append(b.columnExprs, columnExpr{expr: e, alias: alias})
 	return b
 }
 
@@ -54,6 +152,72 @@ func (b *SelectBuilder) Where(condition expr.Expr) *SelectBuilder {
 	return b
 }
 
+// WhereEq adds a column = value condition per filter, ANDed in sorted key
+// order so the SQL and args are stable; a nil value matches IS NULL. Keys
+// name columns of the queried table, or table-qualified columns of joined
+// tables; an unknown key fails the query instead of reaching the SQL.
+func (b *SelectBuilder) WhereEq(filters map[string]interface{}) *SelectBuilder {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		column, err := b.filterColumn(key)
+		if err != nil {
+			b.err = err
+			return b
+		}
+		if filters[key] == nil {
+			b.whereExprs = append(b.whereExprs, &expr.UnaryExpr{Column: column, Operator: "IS NULL"})
+			continue
+		}
+		b.whereExprs = append(b.whereExprs, &expr.CompareExpr{Left: column, Operator: "=", Right: expr.V(filters[key])})
+	}
+	return b
+}
+
+// filterColumn resolves a WhereEq key to the qualified column name
+func (b *SelectBuilder) filterColumn(key string) (string, error) {
+	for _, col := range b.table.Columns() {
+		if col.Name == key {
+			return col.FullName, nil
+		}
+	}
+	if _, ok := b.knownColumns()[key]; ok && strings.Contains(key, ".") {
+		return key, nil
+	}
+	return "", fmt.Errorf("unknown column %q in WhereEq", key)
+}
+
+// WhereOr adds one WHERE condition that holds when any of conditions does;
+// it is ANDed with the other conditions like Where
+func (b *SelectBuilder) WhereOr(conditions ...expr.Expr) *SelectBuilder {
+	b.whereExprs = append(b.whereExprs, expr.Or(conditions...))
+	return b
+}
+
+// WhereAny is WhereOr, named to pair with WhereAll
+func (b *SelectBuilder) WhereAny(conditions ...expr.Expr) *SelectBuilder {
+	return b.WhereOr(conditions...)
+}
+
+// WhereAll adds one WHERE condition that holds when every one of
+// conditions does, grouped like WhereOr
+func (b *SelectBuilder) WhereAll(conditions ...expr.Expr) *SelectBuilder {
+	b.whereExprs = append(b.whereExprs, expr.And(conditions...))
+	return b
+}
+
+// OrWhere ORs condition with every condition added so far, so
+// Where(a).Where(b).OrWhere(c) renders ((a AND b) OR c); later Where calls
+// are ANDed with that whole group
+func (b *SelectBuilder) OrWhere(condition expr.Expr) *SelectBuilder {
+	b.whereExprs = orWhere(b.whereExprs, condition)
+	return b
+}
+
 // Join adds an INNER JOIN
 func (b *SelectBuilder) Join(tbl table.TableInterface, condition expr.Expr) *SelectBuilder {
 	b.joins = append(b.joins, &JoinClause{
@@ -74,7 +238,8 @@ func (b *SelectBuilder) LeftJoin(tbl table.TableInterface, condition expr.Expr)
 	return b
 }
 
-// RightJoin adds a RIGHT JOIN
+// RightJoin adds a RIGHT JOIN. SQLite only supports it from 3.39; building
+// for an older SQLiteDialect.Version fails with an error.
 func (b *SelectBuilder) RightJoin(tbl table.TableInterface, condition expr.Expr) *SelectBuilder {
 	b.joins = append(b.joins, &JoinClause{
 		Type:      "RIGHT JOIN",
@@ -84,6 +249,17 @@ func (b *SelectBuilder) RightJoin(tbl table.TableInterface, condition expr.Expr)
 	return b
 }
 
+// FullJoin adds a FULL OUTER JOIN. MySQL has none, and SQLite only from
+// 3.39; building for those dialects fails with an error.
+func (b *SelectBuilder) FullJoin(tbl table.TableInterface, condition expr.Expr) *SelectBuilder {
+	b.joins = append(b.joins, &JoinClause{
+		Type:      "FULL OUTER JOIN",
+		Table:     tbl,
+		Condition: condition,
+	})
+	return b
+}
+
 // OrderBy adds an ORDER BY clause (default ASC)
 func (b *SelectBuilder) OrderBy(column string) *SelectBuilder {
 	b.orderBy = append(b.orderBy, OrderByClause{
@@ -102,21 +278,124 @@ func (b *SelectBuilder) OrderByDesc(column string) *SelectBuilder {
 	return b
 }
 
+// NullsFirst sorts NULLs before other values in the ORDER BY clause added
+// last, e.g. OrderBy("deleted_at").NullsFirst(). MySQL has no NULLS FIRST,
+// so there it is emulated by ordering on ISNULL(column) first.
+func (b *SelectBuilder) NullsFirst() *SelectBuilder {
+	return b.orderNulls("FIRST")
+}
+
+// NullsLast sorts NULLs after other values in the ORDER BY clause added
+// last, e.g. OrderByDesc("score").NullsLast(); emulated on MySQL like
+// NullsFirst
+func (b *SelectBuilder) NullsLast() *SelectBuilder {
+	return b.orderNulls("LAST")
+}
+
+// orderNulls sets the NULL placement of the last ORDER BY clause
+func (b *SelectBuilder) orderNulls(nulls string) *SelectBuilder {
+	if len(b.orderBy) == 0 {
+		b.err = fmt.Errorf("NULLS %s requires ORDER BY", nulls)
+		return b
+	}
+	b.orderBy[len(b.orderBy)-1].Nulls = nulls
+	return b
+}
+
+// OrderByExpr orders by an expression such as an aggregate, e.g.
+// OrderByExpr("SUM(amount)", "DESC"). direction is ASC (the default when
+// empty) or DESC. The expression is emitted as written and exempt from
+// StrictColumns validation, so it must not come from user input.
+func (b *SelectBuilder) OrderByExpr(fragment string, direction string) *SelectBuilder {
+	direction = strings.ToUpper(direction)
+	if direction == "" {
+		direction = "ASC"
+	}
+	if direction != "ASC" && direction != "DESC" {
+		b.err = fmt.Errorf("invalid direction %q in OrderByExpr", direction)
+		return b
+	}
+	b.orderBy = append(b.orderBy, OrderByClause{
+		Column:    fragment,
+		Direction: direction,
+		Expr:      true,
+	})
+	return b
+}
+
 // GroupBy adds a GROUP BY clause
 func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
 	b.groupBy = append(b.groupBy, columns...)
 	return b
 }
 
+// groupingMode selects how the GROUP BY columns are rendered
+type groupingMode int
+
+const (
+	groupingPlain groupingMode = iota
+	groupingRollup
+	groupingSets
+)
+
+// GroupByRollup groups by ROLLUP (columns...), adding subtotal rows for each
+// prefix of columns and a grand total. Rendered as GROUP BY a, b WITH ROLLUP
+// on MySQL; SQLite does not support it.
+func (b *SelectBuilder) GroupByRollup(columns ...string) *SelectBuilder {
+	b.groupBy = columns
+	b.grouping = groupingRollup
+	b.groupSets = nil
+	return b
+}
+
+// GroupByGroupingSets groups by GROUPING SETS, one aggregate level per set;
+// an empty set is the grand total. Postgres only.
+func (b *SelectBuilder) GroupByGroupingSets(sets [][]string) *SelectBuilder {
+	b.groupBy = nil
+	seen := make(map[string]struct{})
+	for _, set := range sets {
+		for _, col := range set {
+			if _, ok := seen[col]; !ok {
+				seen[col] = struct{}{}
+				b.groupBy = append(b.groupBy, col)
+			}
+		}
+	}
+	b.grouping = groupingSets
+	b.groupSets = sets
+	return b
+}
+
 // Having adds a HAVING condition
 func (b *SelectBuilder) Having(condition expr.Expr) *SelectBuilder {
 	b.having = append(b.having, condition)
 	return b
 }
 
+// HavingExpr adds a parameterized HAVING condition written as SQL, e.g.
+// HavingExpr("SUM(amount) > ?", 1000); shorthand for Having(expr.Raw(...)).
+// Its args follow the WHERE args and precede LIMIT/OFFSET.
+func (b *SelectBuilder) HavingExpr(fragment string, args ...interface{}) *SelectBuilder {
+	return b.Having(expr.Raw(fragment, args...))
+}
+
 // Limit sets the LIMIT
 func (b *SelectBuilder) Limit(limit int) *SelectBuilder {
 	b.limit = &limit
+	b.fetch = false
+	b.withTies = false
+	return b
+}
+
+// FetchFirst limits the result to n rows using the standard
+// OFFSET ... ROWS FETCH FIRST n ROWS ONLY syntax, falling back to LIMIT n on
+// dialects without it. withTies also returns the rows tied with the last one
+// on the ORDER BY columns (FETCH FIRST n ROWS WITH TIES); it requires ORDER BY
+// and fails on dialects that don't support it.
+func (b *SelectBuilder) FetchFirst(n int, withTies bool) *SelectBuilder {
+	b.limit = &n
+	b.fetch = true
+	b.withTies = withTies
 	return b
 }
 
@@ -132,10 +411,327 @@ func (b *SelectBuilder) Distinct() *SelectBuilder {
 	return b
 }
 
+// WithDeleted includes soft-deleted rows in the result
+func (b *SelectBuilder) WithDeleted() *SelectBuilder {
+	b.withDeleted = true
+	return b
+}
+
+// ForNoKeyUpdate locks the selected rows with FOR NO KEY UPDATE, which
+// unlike FOR UPDATE doesn't block inserts referencing them through foreign
+// keys. Postgres only.
+func (b *SelectBuilder) ForNoKeyUpdate() *SelectBuilder {
+	b.lock = "FOR NO KEY UPDATE"
+	return b
+}
+
+// LockTimeout runs SET LOCAL lock_timeout before the query, so waiting for a
+// row lock fails after d instead of blocking. The connection must be in a
+// transaction, and the timeout stays in effect until it ends. Postgres only.
+func (b *SelectBuilder) LockTimeout(d time.Duration) *SelectBuilder {
+	if d < time.Millisecond {
+		b.err = fmt.Errorf("LockTimeout must be at least 1ms, got %s", d)
+		return b
+	}
+	b.lockTimeout = d
+	return b
+}
+
+// setupSQL returns the SET LOCAL lock_timeout statement run before the query
+func (b *SelectBuilder) setupSQL(conn ConnectionInterface) (string, error) {
+	if b.lockTimeout <= 0 {
+		return "", nil
+	}
+	if err := dialect.Require(conn.Dialect(), dialect.FeatureLockTimeout); err != nil {
+		return "", err
+	}
+	if tc, ok := conn.(txConnection); !ok || !tc.InTransaction() {
+		return "", fmt.Errorf("LockTimeout requires a transaction")
+	}
+	return fmt.Sprintf("SET LOCAL lock_timeout = %d", b.lockTimeout.Milliseconds()), nil
+}
+
+// StrictColumns rejects Select, GroupBy and OrderBy identifiers that are not
+// columns of the queried or joined tables. Enable it when those identifiers
+// come from user input, e.g. sortable columns in an API.
+func (b *SelectBuilder) StrictColumns(strict bool) *SelectBuilder {
+	b.strict = strict
+	return b
+}
+
+// All executes the query and scans every row into dest (a pointer to a slice)
+//
+// With a ResultCache configured, a repeated query with the same SQL, args
+// and destination type is answered from the cache. Queries inside a
+// transaction, with a row lock or marked NoCache always hit the database.
+func (b *SelectBuilder) All(ctx context.Context, dest interface{}) error {
+	if cache := b.resultCache(); cache != nil {
+		return cachedAll(ctx, b.conn, b, cache, dest)
+	}
+	return queryAll(ctx, b.conn, b, dest)
+}
+
+// NoCache makes All bypass the connection's ResultCache, e.g. to read
+// data just written
+func (b *SelectBuilder) NoCache() *SelectBuilder {
+	b.noCache = true
+	return b
+}
+
+// resultCache returns the cache All may use for this query, or nil
+func (b *SelectBuilder) resultCache() ResultCache {
+	if b.conn == nil || b.noCache || b.lock != "" || b.lockTimeout > 0 {
+		return nil
+	}
+	if tc, ok := b.conn.(txConnection); ok && tc.InTransaction() {
+		return nil
+	}
+	return b.conn.ResultCache()
+}
+
+// Pluck selects only column and scans its values into dest, a pointer to a
+// slice such as *[]int64 or *[]string. Values are converted by database/sql
+// (and sql.Scanner element types), as for a single-column All.
+func (b *SelectBuilder) Pluck(ctx context.Context, column string, dest interface{}) error {
+	c := *b
+	c.columns = []string{column}
+	c.columnExprs = nil
+	c.typedCols = nil
+	return queryAll(ctx, c.conn, &c, dest)
+}
+
+// Scalar runs a query selecting a single value, such as
+// Select("MAX(created_at)"), and scans it into dest, e.g. a *time.Time,
+// *int64 or *string. It fails unless the result has exactly one column and
+// one row; NULL needs a pointer or sql.Null* destination. Text timestamps
+// (SQLite) are parsed into time.Time and sql.NullTime.
+func (b *SelectBuilder) Scalar(ctx context.Context, dest interface{}) error {
+	return queryScalar(ctx, b.conn, b, dest)
+}
+
+// Count returns the number of rows the query matches, ignoring ORDER BY,
+// LIMIT, OFFSET and row locks. Grouped and DISTINCT queries are wrapped in a subquery
+// so the result is the number of groups or distinct rows.
+func (b *SelectBuilder) Count(ctx context.Context) (int64, error) {
+	query, err := b.countQuery()
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if err := queryOne(ctx, b.conn, query, &n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// countQuery builds the statement behind Count
+func (b *SelectBuilder) countQuery() (Builder, error) {
+	if b.strict {
+		if err := b.validateColumns(); err != nil {
+			return nil, err
+		}
+	}
+	c := *b
+	c.orderBy = nil
+	c.limit = nil
+	c.offset = nil
+	c.strict = false
+	c.lock = ""
+
+	if len(b.groupBy) > 0 || b.distinct {
+		return &countSubquery{inner: &c}, nil
+	}
+	c.columns = []string{"COUNT(*)"}
+	c.columnExprs = nil
+	c.typedCols = nil
+	return &c, nil
+}
+
+// Exists reports whether the query matches any row, using
+// SELECT EXISTS(SELECT 1 FROM ...), which stops at the first match
+func (b *SelectBuilder) Exists(ctx context.Context) (bool, error) {
+	query, err := b.existsQuery()
+	if err != nil {
+		return false, err
+	}
+	var found existsResult
+	if err := queryOne(ctx, b.conn, query, &found); err != nil {
+		return false, err
+	}
+	return bool(found), nil
+}
+
+// existsQuery builds the statement behind Exists
+func (b *SelectBuilder) existsQuery() (Builder, error) {
+	if b.strict {
+		if err := b.validateColumns(); err != nil {
+			return nil, err
+		}
+	}
+	c := *b
+	c.columns = []string{"1"}
+	c.columnExprs = nil
+	c.typedCols = nil
+	c.orderBy = nil
+	c.strict = false
+	return &existsSubquery{inner: &c}, nil
+}
+
+// existsSubquery wraps a query in SELECT EXISTS(<inner>)
+type existsSubquery struct {
+	inner *SelectBuilder
+}
+
+func (q *existsSubquery) ToSQL() (string, []interface{}, error) {
+	sql, args, err := q.inner.ToSQL()
+	if err != nil {
+		return "", nil, err
+	}
+	return "SELECT EXISTS(" + sql + ")", args, nil
+}
+
+func (q *existsSubquery) targetTable() table.TableInterface {
+	return q.inner.table
+}
+
+// existsResult scans the EXISTS result: a boolean on Postgres, 0/1 on
+// SQLite and MySQL
+type existsResult bool
+
+func (r *existsResult) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case bool:
+		*r = existsResult(v)
+	case int64:
+		*r = v != 0
+	case []byte:
+		return r.Scan(string(v))
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q as EXISTS result", v)
+		}
+		*r = existsResult(b)
+	default:
+		return fmt.Errorf("cannot scan %T as EXISTS result", src)
+	}
+	return nil
+}
+
+// CountDistinct returns the number of distinct non-NULL values of column
+// among the rows the query matches
+func (b *SelectBuilder) CountDistinct(ctx context.Context, column string) (int64, error) {
+	query, err := b.countDistinctQuery(column)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if err := queryOne(ctx, b.conn, query, &n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// countDistinctQuery builds the statement behind CountDistinct
+func (b *SelectBuilder) countDistinctQuery(column string) (Builder, error) {
+	if len(b.groupBy) > 0 {
+		return nil, fmt.Errorf("CountDistinct does not support GROUP BY")
+	}
+	if b.strict {
+		if err := b.validateColumns(); err != nil {
+			return nil, err
+		}
+		if _, ok := b.knownColumns()[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q in COUNT(DISTINCT)", column)
+		}
+	}
+	c := *b
+	c.orderBy = nil
+	c.limit = nil
+	c.offset = nil
+	c.strict = false
+	c.distinct = false
+	c.lock = ""
+	c.columns = []string{"COUNT(DISTINCT " + identifierQuoter(b.conn)(column) + ")"}
+	c.columnExprs = nil
+	c.typedCols = nil
+	return &c, nil
+}
+
+// countSubquery counts the rows of a wrapped query:
+// SELECT COUNT(*) FROM (<inner>) t
+type countSubquery struct {
+	inner *SelectBuilder
+}
+
+func (q *countSubquery) ToSQL() (string, []interface{}, error) {
+	sql, args, err := q.inner.ToSQL()
+	if err != nil {
+		return "", nil, err
+	}
+	return "SELECT COUNT(*) FROM (" + sql + ") t", args, nil
+}
+
+func (q *countSubquery) targetTable() table.TableInterface {
+	return q.inner.table
+}
+
+// Iterate executes the query and returns an iterator over the result rows.
+// The caller must Close the iterator.
+func (b *SelectBuilder) Iterate(ctx context.Context) (*RowIterator, error) {
+	return queryRows(ctx, b.conn, b)
+}
+
+// One executes the query and scans exactly one row into dest
+func (b *SelectBuilder) One(ctx context.Context, dest interface{}) error {
+	return queryOne(ctx, b.conn, b, dest)
+}
+
+// First scans the row with the lowest orderCol into dest
+// (ORDER BY orderCol ASC LIMIT 1), returning sql.ErrNoRows if none match
+func (b *SelectBuilder) First(ctx context.Context, dest interface{}, orderCol string) error {
+	return b.firstBy(ctx, dest, orderCol, "ASC")
+}
+
+// Last scans the row with the highest orderCol into dest
+// (ORDER BY orderCol DESC LIMIT 1), returning sql.ErrNoRows if none match
+func (b *SelectBuilder) Last(ctx context.Context, dest interface{}, orderCol string) error {
+	return b.firstBy(ctx, dest, orderCol, "DESC")
+}
+
+// firstBy runs a copy of the query ordered by orderCol first (existing
+// ORDER BY columns break ties) and limited to one row
+func (b *SelectBuilder) firstBy(ctx context.Context, dest interface{}, orderCol, direction string) error {
+	c := *b
+	c.orderBy = append([]OrderByClause{{Column: orderCol, Direction: direction}}, b.orderBy...)
+	c.Limit(1)
+	return queryOne(ctx, c.conn, &c, dest)
+}
+
+// DebugSQL returns the SQL and args exactly as executed on dialect d
+// (placeholders formatted), for logging or sqlmock expectations.
+// It returns empty SQL if the builder is invalid; call ToSQL for the error.
+func (b *SelectBuilder) DebugSQL(d dialect.Dialect) (string, []interface{}) {
+	c := *b
+	c.conn = withDialect(b.conn, d)
+	return debugSQL(&c, d)
+}
+
 // ToSQL generates the SQL query and arguments
 func (b *SelectBuilder) ToSQL() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	if b.strict {
+		if err := b.validateColumns(); err != nil {
+			return "", nil, err
+		}
+	}
+
 	var sql strings.Builder
 	var args []interface{}
+	quote := identifierQuoter(b.conn)
+	d := b.renderDialect()
 
 	// SELECT [DISTINCT]
 	sql.WriteString("SELECT")
@@ -145,52 +741,84 @@ func (b *SelectBuilder) ToSQL() (string, []interface{}, error) {
 	sql.WriteString(" ")
 
 	// Columns
-	if len(b.columns) > 0 {
-		sql.WriteString(strings.Join(b.columns, ", "))
+	columns := quoteAll(quote, b.columns)
+	for _, ce := range b.columnExprs {
+		exprSQL, exprArgs, err := renderExpr(b.conn, ce.expr, d)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, exprSQL+" AS "+quote(ce.alias))
+		args = append(args, exprArgs...)
+	}
+	if len(columns) > 0 {
+		sql.WriteString(strings.Join(columns, ", "))
 	} else {
 		sql.WriteString("*")
 	}
 
 	// FROM
-	tableName := b.table.Name()
-	if tableName == "" {
-		return "", nil, fmt.Errorf("invalid table")
+	if b.fromSQL != "" {
+		sql.WriteString(" FROM ")
+		sql.WriteString(b.fromSQL)
+		args = append(args, b.fromArgs...)
+	} else {
+		tableName := b.table.Name()
+		if tableName == "" {
+			return "", nil, fmt.Errorf("invalid table")
+		}
+		sql.WriteString(" FROM ")
+		sql.WriteString(quote(tableName))
 	}
-	sql.WriteString(" FROM ")
-	sql.WriteString(tableName)
 
 	// JOINs
 	for _, join := range b.joins {
+		if err := joinSupported(d, join.Type); err != nil {
+			return "", nil, err
+		}
 		joinTableName := join.Table.Name()
 		sql.WriteString(" ")
 		sql.WriteString(join.Type)
 		sql.WriteString(" ")
-		sql.WriteString(joinTableName)
+		sql.WriteString(quote(joinTableName))
 		sql.WriteString(" ON ")
 
-		joinSQL, joinArgs := join.Condition.ToSQL()
+		joinSQL, joinArgs, err := renderExpr(b.conn, join.Condition, d)
+		if err != nil {
+			return "", nil, err
+		}
 		sql.WriteString(joinSQL)
 		args = append(args, joinArgs...)
 	}
 
 	// WHERE
-	if len(b.whereExprs) > 0 {
+	whereExprs := b.whereExprs
+	if col := softDeleteColumn(b.table); col != nil && !b.withDeleted {
+		whereExprs = append(whereExprs[:len(whereExprs):len(whereExprs)], &expr.UnaryExpr{
+			Column:   quote(col.FullName),
+			Operator: "IS NULL",
+		})
+	}
+	if len(whereExprs) > 0 {
 		sql.WriteString(" WHERE ")
-		for i, whereExpr := range b.whereExprs {
+		for i, whereExpr := range whereExprs {
 			if i > 0 {
 				sql.WriteString(" AND ")
 			}
-			whereSQL, whereArgs := whereExpr.ToSQL()
+			whereSQL, whereArgs, err := renderExpr(b.conn, whereExpr, d)
+			if err != nil {
+				return "", nil, err
+			}
 			sql.WriteString(whereSQL)
 			args = append(args, whereArgs...)
 		}
 	}
 
 	// GROUP BY
-	if len(b.groupBy) > 0 {
-		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(b.groupBy, ", "))
+	groupBySQL, err := b.groupBySQL(d, quote)
+	if err != nil {
+		return "", nil, err
 	}
+	sql.WriteString(groupBySQL)
 
 	// HAVING
 	if len(b.having) > 0 {
@@ -199,7 +827,10 @@ func (b *SelectBuilder) ToSQL() (string, []interface{}, error) {
 			if i > 0 {
 				sql.WriteString(" AND ")
 			}
-			havingSQL, havingArgs := havingExpr.ToSQL()
+			havingSQL, havingArgs, err := renderExpr(b.conn, havingExpr, d)
+			if err != nil {
+				return "", nil, err
+			}
 			sql.WriteString(havingSQL)
 			args = append(args, havingArgs...)
 		}
@@ -208,22 +839,229 @@ func (b *SelectBuilder) ToSQL() (string, []interface{}, error) {
 	// ORDER BY
 	if len(b.orderBy) > 0 {
 		sql.WriteString(" ORDER BY ")
-		orderParts := make([]string, len(b.orderBy))
-		for i, order := range b.orderBy {
-			orderParts[i] = order.Column + " " + order.Direction
+		sql.WriteString(b.orderBySQL(d, quote))
+	}
+
+	// OFFSET ... FETCH FIRST
+	if b.limit != nil && b.fetch {
+		fetchSQL, fetchArgs, err := b.fetchSQL(d)
+		if err != nil {
+			return "", nil, err
+		}
+		if fetchSQL != "" {
+			sql.WriteString(fetchSQL)
+			lockSQL, err := b.lockSQL(d)
+			if err != nil {
+				return "", nil, err
+			}
+			sql.WriteString(lockSQL)
+			return sql.String(), append(args, fetchArgs...), nil
 		}
-		sql.WriteString(strings.Join(orderParts, ", "))
 	}
 
-	// LIMIT
+	// LIMIT (dialects that reject a bare OFFSET get their "all rows" limit)
 	if b.limit != nil {
-		sql.WriteString(fmt.Sprintf(" LIMIT %d", *b.limit))
+		sql.WriteString(" LIMIT ?")
+		args = append(args, *b.limit)
+	} else if b.offset != nil && d != nil {
+		if noLimit := d.FormatNoLimit(); noLimit != "" {
+			sql.WriteString(" " + noLimit)
+		}
 	}
 
 	// OFFSET
 	if b.offset != nil {
-		sql.WriteString(fmt.Sprintf(" OFFSET %d", *b.offset))
+		sql.WriteString(" OFFSET ?")
+		args = append(args, *b.offset)
+	}
+
+	// FOR NO KEY UPDATE
+	lockSQL, err := b.lockSQL(d)
+	if err != nil {
+		return "", nil, err
 	}
+	sql.WriteString(lockSQL)
 
 	return sql.String(), args, nil
 }
+
+// joinSupported checks the dialect can render a RIGHT or FULL OUTER JOIN
+func joinSupported(d dialect.Dialect, joinType string) error {
+	if d == nil {
+		return nil
+	}
+	switch joinType {
+	case "RIGHT JOIN":
+		return dialect.Require(d, dialect.FeatureRightJoin)
+	case "FULL OUTER JOIN":
+		return dialect.Require(d, dialect.FeatureFullOuterJoin)
+	}
+	return nil
+}
+
+// lockSQL renders the row-locking clause, checking the dialect supports it
+func (b *SelectBuilder) lockSQL(d dialect.Dialect) (string, error) {
+	if b.lock == "" {
+		return "", nil
+	}
+	if d != nil {
+		if err := dialect.Require(d, dialect.FeatureNoKeyUpdate); err != nil {
+			return "", err
+		}
+	}
+	return " " + b.lock, nil
+}
+
+// orderBySQL renders the ORDER BY list. NULLS FIRST/LAST is emulated on
+// dialects without it by ordering on ISNULL(column) first.
+func (b *SelectBuilder) orderBySQL(d dialect.Dialect, quote func(string) string) string {
+	parts := make([]string, 0, len(b.orderBy))
+	for _, order := range b.orderBy {
+		column := order.Column
+		if !order.Expr {
+			column = quote(column)
+		}
+		part := column + " " + order.Direction
+		switch {
+		case order.Nulls == "":
+		case d == nil || d.Supports(dialect.FeatureNullsOrdering):
+			part += " NULLS " + order.Nulls
+		case order.Nulls == "FIRST":
+			parts = append(parts, "ISNULL("+column+") DESC")
+		default:
+			parts = append(parts, "ISNULL("+column+") ASC")
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderDialect returns the dialect expressions are rendered for: the bound
+// connection's, or nil (dialect-neutral output) for an unbound builder
+func (b *SelectBuilder) renderDialect() dialect.Dialect {
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Dialect()
+}
+
+// fetchSQL renders OFFSET ... FETCH FIRST, or returns empty SQL when the
+// dialect has no FETCH FIRST and a plain LIMIT should be used instead
+func (b *SelectBuilder) fetchSQL(d dialect.Dialect) (string, []interface{}, error) {
+	if b.withTies {
+		if len(b.orderBy) == 0 {
+			return "", nil, fmt.Errorf("FetchFirst WITH TIES requires ORDER BY")
+		}
+		if d != nil {
+			if err := dialect.Require(d, dialect.FeatureFetchWithTies); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if d != nil && !d.Supports(dialect.FeatureFetchFirst) {
+		return "", nil, nil
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+	if b.offset != nil {
+		sql.WriteString(" OFFSET ? ROWS")
+		args = append(args, *b.offset)
+	}
+	sql.WriteString(" FETCH FIRST ? ROWS")
+	args = append(args, *b.limit)
+	if b.withTies {
+		sql.WriteString(" WITH TIES")
+	} else {
+		sql.WriteString(" ONLY")
+	}
+	return sql.String(), args, nil
+}
+
+// groupBySQL renders the GROUP BY clause for the grouping mode
+func (b *SelectBuilder) groupBySQL(d dialect.Dialect, quote func(string) string) (string, error) {
+	switch b.grouping {
+	case groupingRollup:
+		if len(b.groupBy) == 0 {
+			return "", fmt.Errorf("GroupByRollup requires at least one column")
+		}
+		if d != nil {
+			if err := dialect.Require(d, dialect.FeatureRollup); err != nil {
+				return "", err
+			}
+		}
+		cols := strings.Join(quoteAll(quote, b.groupBy), ", ")
+		if d != nil && d.Name() == "mysql" {
+			return " GROUP BY " + cols + " WITH ROLLUP", nil
+		}
+		return " GROUP BY ROLLUP (" + cols + ")", nil
+	case groupingSets:
+		if len(b.groupSets) == 0 {
+			return "", fmt.Errorf("GroupByGroupingSets requires at least one set")
+		}
+		if d != nil {
+			if err := dialect.Require(d, dialect.FeatureGroupingSets); err != nil {
+				return "", err
+			}
+		}
+		sets := make([]string, len(b.groupSets))
+		for i, set := range b.groupSets {
+			sets[i] = "(" + strings.Join(quoteAll(quote, set), ", ") + ")"
+		}
+		return " GROUP BY GROUPING SETS (" + strings.Join(sets, ", ") + ")", nil
+	default:
+		if len(b.groupBy) == 0 {
+			return "", nil
+		}
+		return " GROUP BY " + strings.Join(quoteAll(quote, b.groupBy), ", "), nil
+	}
+}
+
+// validateColumns checks every identifier interpolated into the query against
+// the column names (plain or table-qualified) of the queried and joined tables
+func (b *SelectBuilder) validateColumns() error {
+	known := b.knownColumns()
+
+	check := func(clause string, column string) error {
+		if _, ok := known[column]; !ok {
+			return fmt.Errorf("unknown column %q in %s", column, clause)
+		}
+		return nil
+	}
+	for _, col := range b.columns {
+		if err := check("SELECT", col); err != nil {
+			return err
+		}
+	}
+	for _, col := range b.groupBy {
+		if err := check("GROUP BY", col); err != nil {
+			return err
+		}
+	}
+	for _, order := range b.orderBy {
+		if order.Expr {
+			continue
+		}
+		if err := check("ORDER BY", order.Column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// knownColumns returns the plain and table-qualified column names of the
+// queried and joined tables
+func (b *SelectBuilder) knownColumns() map[string]struct{} {
+	known := make(map[string]struct{})
+	tables := []table.TableInterface{b.table}
+	for _, join := range b.joins {
+		tables = append(tables, join.Table)
+	}
+	for _, tbl := range tables {
+		for _, col := range tbl.Columns() {
+			known[col.Name] = struct{}{}
+			known[tbl.Name()+"."+col.Name] = struct{}{}
+		}
+	}
+	return known
+}
@@ -0,0 +1,1590 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+func TestSelectSoftDeleteFilter(t *testing.T) {
+	users := newSoftDeleteUsersTable()
+
+	sql, args, err := NewSelect(users).Where(expr.Eq(users.C.Name, "john")).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE users.name = ? AND users.deleted_at IS NULL"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 1 {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	sql, _, err = NewSelect(users).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE users.deleted_at IS NULL"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestSelectWithDeleted(t *testing.T) {
+	users := newSoftDeleteUsersTable()
+
+	sql, _, err := NewSelect(users).WithDeleted().ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestSelectAll(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT id, name FROM users WHERE users.age > $1").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(int64(1), "john").
+			AddRow(int64(2), "jane"))
+
+	var got []User
+	err := NewSelect(users).
+		WithConnection(conn).
+		Select("id", "name").
+		Where(expr.Gt(users.C.Age, 18)).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "john" || got[1].ID != 2 {
+		t.Fatalf("unexpected rows %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectIterate(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(int64(1), "john").
+			AddRow(int64(2), "jane")).
+		RowsWillBeClosed()
+
+	it, err := NewSelect(users).WithConnection(conn).Select("id", "name").Iterate(context.Background())
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+
+	var names []string
+	for it.Next() {
+		var u User
+		if err := it.Scan(&u); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		names = append(names, u.Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if err := it.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(names) != 2 || names[0] != "john" || names[1] != "jane" {
+		t.Fatalf("unexpected names %v", names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectIterateStopsOnCancel(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(int64(1), "john").
+			AddRow(int64(2), "jane").
+			AddRow(int64(3), "joe")).
+		RowsWillBeClosed()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it, err := NewSelect(users).WithConnection(conn).Select("id", "name").Iterate(ctx)
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	defer it.Close()
+
+	scanned := 0
+	for it.Next() {
+		var u User
+		if err := it.Scan(&u); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		scanned++
+		cancel()
+	}
+	if scanned != 1 {
+		t.Fatalf("scanned %d rows after cancel, want 1", scanned)
+	}
+	if err := it.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Err() = %v, want context.Canceled", err)
+	}
+	if inUse := conn.db.Stats().InUse; inUse != 0 {
+		t.Fatalf("%d connections still in use after cancel", inUse)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// cancelingID cancels cancelScan when scanned, to cancel a query mid-scan
+type cancelingID int64
+
+var cancelScan context.CancelFunc
+
+func (c *cancelingID) Scan(src interface{}) error {
+	cancelScan()
+	*c = cancelingID(src.(int64))
+	return nil
+}
+
+func TestSelectAllStopsOnCancel(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(2))).
+		RowsWillBeClosed()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelScan = cancel
+
+	var ids []cancelingID
+	err := NewSelect(users).WithConnection(conn).Select("id").All(ctx, &ids)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("All() error = %v, want context.Canceled", err)
+	}
+	if ids != nil {
+		t.Fatalf("All() filled dest with %v despite the error", ids)
+	}
+	if inUse := conn.db.Stats().InUse; inUse != 0 {
+		t.Fatalf("%d connections still in use after cancel", inUse)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectStrictColumns(t *testing.T) {
+	users := newUsersTable()
+
+	sql, _, err := NewSelect(users).
+		StrictColumns(true).
+		Select("id", "users.name").
+		GroupBy("id").
+		OrderByDesc("age").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT id, users.name FROM users GROUP BY id ORDER BY age DESC"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+
+	tests := []struct {
+		name    string
+		builder *SelectBuilder
+	}{
+		{"select", NewSelect(users).StrictColumns(true).Select("id, password")},
+		{"group by", NewSelect(users).StrictColumns(true).GroupBy("1; DROP TABLE users")},
+		{"order by", NewSelect(users).StrictColumns(true).OrderBy("(SELECT 1)")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.builder.ToSQL(); err == nil {
+				t.Fatal("expected unknown column error")
+			}
+		})
+	}
+
+	if _, _, err := NewSelect(users).OrderBy("(SELECT 1)").ToSQL(); err != nil {
+		t.Fatalf("non-strict ToSQL() error = %v", err)
+	}
+}
+
+func TestSelectLimitOffsetPlaceholders(t *testing.T) {
+	users := newUsersTable()
+
+	sql, args, err := NewSelect(users).
+		Where(expr.Gt(users.C.Age, 18)).
+		GroupBy("age").
+		Having(expr.Raw("COUNT(*) > ?", 2)).
+		Limit(10).
+		Offset(20).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE users.age > ? GROUP BY age HAVING COUNT(*) > ? LIMIT ? OFFSET ?"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{18, 2, 10, 20}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	if got := FormatPlaceholders(sql, &postgres.PostgresDialect{}); !strings.HasSuffix(got, "LIMIT $3 OFFSET $4") {
+		t.Fatalf("FormatPlaceholders() = %q", got)
+	}
+}
+
+func TestSelectHavingExprArgOrder(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT age, SUM(id) AS total FROM users WHERE users.name != $1 GROUP BY age HAVING SUM(id) > $2 AND COUNT(*) BETWEEN $3 AND $4 AND (MAX(id) < $5) LIMIT $6").
+		WithArgs("bot", 1000, 2, 9, 50, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"age", "total"}).AddRow(30, int64(1200)))
+
+	type AgeTotal struct {
+		Age   int   `sql:"age"`
+		Total int64 `sql:"total"`
+	}
+	var got []AgeTotal
+	err := NewSelect(users).
+		WithConnection(conn).
+		Select("age", "SUM(id) AS total").
+		Where(expr.Ne(users.C.Name, "bot")).
+		GroupBy("age").
+		HavingExpr("SUM(id) > ?", 1000).
+		HavingExpr("COUNT(*) BETWEEN ? AND ?", 2, 9).
+		Having(expr.Parens(expr.Raw("MAX(id) < ?", 50))).
+		Limit(10).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if want := []AgeTotal{{Age: 30, Total: 1200}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectFromFunction(t *testing.T) {
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT n, n * $1 AS scaled FROM generate_series($2, $3) AS n WHERE n % $4 = 0 ORDER BY n DESC LIMIT $5").
+		WithArgs(10, 1, 20, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"n", "scaled"}).AddRow(18, 180).AddRow(15, 150))
+
+	type Row struct {
+		N      int `sql:"n"`
+		Scaled int `sql:"scaled"`
+	}
+	var got []Row
+	err := NewSelectFrom("generate_series(?, ?) AS n", 1, 20).
+		WithConnection(conn).
+		Select("n").
+		SelectExpr(expr.Raw("n * ?", 10), "scaled").
+		Where(expr.Raw("n % ? = 0", 3)).
+		OrderByExpr("n", "desc").
+		Limit(2).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if want := []Row{{18, 180}, {15, 150}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	// Unbound builders render the fragment with ? placeholders as written
+	sql, args, err := NewSelectFrom("(VALUES (?), (?)) AS v(id)", 1, 2).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM (VALUES (?), (?)) AS v(id)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSelectWhereFromColumnRefs(t *testing.T) {
+	users := newUsersTable()
+
+	// Generic filtering over column metadata: match every filter key that
+	// names a column of the table
+	filters := map[string]interface{}{"email": "john@example.com", "age": 30, "unknown": 1}
+	var conds []expr.Expr
+	for _, col := range users.Columns() {
+		if value, ok := filters[col.Name]; ok {
+			conds = append(conds, expr.EqCol(col, value))
+		}
+	}
+
+	sql, args, err := NewSelect(users).WhereAll(conds...).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE ((users.email = ?) AND (users.age = ?))"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{"john@example.com", 30}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+}
+
+func TestSelectOrderByExpr(t *testing.T) {
+	users := newUsersTable()
+	conn, _ := newTestConn(t, &postgres.PostgresDialect{})
+	conn.quote = true
+
+	sql, args, err := NewSelect(users).
+		WithConnection(conn).
+		StrictColumns(true).
+		Select("age").
+		Where(expr.Gt(users.C.ID, int64(0))).
+		GroupBy("age").
+		HavingExpr("COUNT(*) > ?", 1).
+		OrderByExpr("SUM(id)", "desc").
+		OrderBy("age").
+		Limit(5).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	want := `SELECT "age" FROM "users" WHERE "users"."id" > ? GROUP BY "age" HAVING COUNT(*) > ? ORDER BY SUM(id) DESC, "age" ASC LIMIT ?`
+	if sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{int64(0), 1, 5}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+
+	_, _, err = NewSelect(users).OrderByExpr("SUM(id)", "sideways").ToSQL()
+	if err == nil || err.Error() != `invalid direction "SIDEWAYS" in OrderByExpr` {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+}
+
+func TestSelectOrderByNulls(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    string
+	}{
+		{"postgres", &postgres.PostgresDialect{}, "SELECT * FROM users ORDER BY age DESC NULLS LAST, email ASC NULLS FIRST, id ASC"},
+		{"sqlite", &sqlite.SQLiteDialect{}, "SELECT * FROM users ORDER BY age DESC NULLS LAST, email ASC NULLS FIRST, id ASC"},
+		{"mysql", &mysql.MySQLDialect{}, "SELECT * FROM users ORDER BY ISNULL(age) ASC, age DESC, ISNULL(email) DESC, email ASC, id ASC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			sql, _, err := NewSelect(users).
+				WithConnection(conn).
+				OrderByDesc("age").NullsLast().
+				OrderBy("email").NullsFirst().
+				OrderBy("id").
+				ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+
+	_, _, err := NewSelect(users).NullsLast().ToSQL()
+	if err == nil || err.Error() != "NULLS LAST requires ORDER BY" {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+}
+
+func TestFormatPlaceholdersSkipsLiterals(t *testing.T) {
+	pg := &postgres.PostgresDialect{}
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"string literal", "SELECT * FROM notes WHERE note LIKE '%?%' AND id = ?", "SELECT * FROM notes WHERE note LIKE '%?%' AND id = $1"},
+		{"doubled quote", "SELECT 'it''s ?' WHERE id = ?", "SELECT 'it''s ?' WHERE id = $1"},
+		{"quoted identifier", `SELECT "why?" FROM t WHERE id = ?`, `SELECT "why?" FROM t WHERE id = $1`},
+		{"json any keys", "SELECT * FROM t WHERE data ?| array['a'] AND id = ?", "SELECT * FROM t WHERE data ?| array['a'] AND id = $1"},
+		{"json all keys", "SELECT * FROM t WHERE data ?& ? AND id = ?", "SELECT * FROM t WHERE data ?& $1 AND id = $2"},
+		{"escaped json key", "SELECT * FROM t WHERE data ?? ?", "SELECT * FROM t WHERE data ? $1"},
+		{"concat", "SELECT ?||name FROM t", "SELECT $1||name FROM t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPlaceholders(tt.sql, pg); got != tt.want {
+				t.Fatalf("FormatPlaceholders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPlaceholdersFrom(t *testing.T) {
+	fragments := []string{
+		"SELECT id FROM users WHERE age > ? AND name = ?",
+		"SELECT id FROM admins WHERE note <> '?'",
+		"SELECT id FROM guests WHERE age < ?",
+	}
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    []string
+		next    int
+	}{
+		{
+			name:    "postgres",
+			dialect: &postgres.PostgresDialect{},
+			want: []string{
+				"SELECT id FROM users WHERE age > $1 AND name = $2",
+				"SELECT id FROM admins WHERE note <> '?'",
+				"SELECT id FROM guests WHERE age < $3",
+			},
+			next: 4,
+		},
+		{
+			name:    "sqlite",
+			dialect: &sqlite.SQLiteDialect{},
+			want:    fragments,
+			next:    4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position := 1
+			for i, fragment := range fragments {
+				var got string
+				got, position = FormatPlaceholdersFrom(fragment, tt.dialect, position)
+				if got != tt.want[i] {
+					t.Fatalf("fragment %d = %q, want %q", i, got, tt.want[i])
+				}
+			}
+			if position != tt.next {
+				t.Fatalf("next position = %d, want %d", position, tt.next)
+			}
+		})
+	}
+
+	if got, next := FormatPlaceholdersFrom("a = ?", &postgres.PostgresDialect{}, 7); got != "a = $7" || next != 8 {
+		t.Fatalf("FormatPlaceholdersFrom() = %q, %d; want \"a = $7\", 8", got, next)
+	}
+}
+
+func TestSelectRawLiteralQuestionMark(t *testing.T) {
+	users := newUsersTable()
+
+	sql, args := NewSelect(users).
+		Where(expr.Raw("users.name LIKE '%?%'")).
+		Where(expr.Eq(users.C.Age, 30)).
+		DebugSQL(&postgres.PostgresDialect{})
+	if want := "SELECT * FROM users WHERE users.name LIKE '%?%' AND users.age = $1"; sql != want {
+		t.Fatalf("DebugSQL() = %q, want %q", sql, want)
+	}
+	if want := []interface{}{30}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+}
+
+func TestSelectForNoKeyUpdate(t *testing.T) {
+	users := newUsersTable()
+	base, mock := newTestConn(t, &postgres.PostgresDialect{})
+	txConn := &txTestConn{testConn: base, inTx: true}
+
+	mock.ExpectExec("SET LOCAL lock_timeout = 1500").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT * FROM users WHERE users.id = $1 LIMIT $2 FOR NO KEY UPDATE").
+		WithArgs(int64(7), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "age"}).AddRow(int64(7), "ann", "ann@example.com", 30))
+
+	var got User
+	err := NewSelect(users).WithConnection(txConn).
+		Where(expr.Eq(users.C.ID, int64(7))).
+		Limit(1).
+		ForNoKeyUpdate().
+		LockTimeout(1500*time.Millisecond).
+		One(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("One() error = %v", err)
+	}
+	if got.ID != 7 {
+		t.Fatalf("One() = %+v", got)
+	}
+
+	fetchSQL, _ := NewSelect(users).OrderBy("age").FetchFirst(5, false).ForNoKeyUpdate().
+		DebugSQL(&postgres.PostgresDialect{})
+	if want := "SELECT * FROM users ORDER BY age ASC FETCH FIRST $1 ROWS ONLY FOR NO KEY UPDATE"; fetchSQL != want {
+		t.Fatalf("DebugSQL() = %q, want %q", fetchSQL, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectLockErrors(t *testing.T) {
+	users := newUsersTable()
+
+	mysqlConn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	_, _, err := NewSelect(users).WithConnection(mysqlConn).ForNoKeyUpdate().ToSQL()
+	if err == nil || err.Error() != "FOR NO KEY UPDATE is not supported by the mysql dialect" {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+
+	sqliteConn, _ := newTestConn(t, &sqlite.SQLiteDialect{})
+	err = NewSelect(users).WithConnection(sqliteConn).LockTimeout(time.Second).All(context.Background(), &[]User{})
+	if err == nil || err.Error() != "SET LOCAL lock_timeout is not supported by the sqlite dialect" {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	pgConn, _ := newTestConn(t, &postgres.PostgresDialect{})
+	err = NewSelect(users).WithConnection(pgConn).LockTimeout(time.Second).All(context.Background(), &[]User{})
+	if err == nil || err.Error() != "LockTimeout requires a transaction" {
+		t.Fatalf("All() error = %v", err)
+	}
+
+	if _, _, err := NewSelect(users).LockTimeout(time.Microsecond).ToSQL(); err == nil {
+		t.Fatal("ToSQL() error = nil, want error for sub-millisecond timeout")
+	}
+}
+
+func TestSelectScalar(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT MAX(age) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(age)"}).AddRow(int64(64)))
+	var maxAge int
+	if err := NewSelect(users).WithConnection(conn).Select("MAX(age)").Scalar(ctx, &maxAge); err != nil {
+		t.Fatalf("Scalar(int) error = %v", err)
+	}
+	if maxAge != 64 {
+		t.Fatalf("Scalar(int) = %d, want 64", maxAge)
+	}
+
+	mock.ExpectQuery("SELECT MIN(name) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN(name)"}).AddRow([]byte("ann")))
+	var first string
+	if err := NewSelect(users).WithConnection(conn).Select("MIN(name)").Scalar(ctx, &first); err != nil {
+		t.Fatalf("Scalar(string) error = %v", err)
+	}
+	if first != "ann" {
+		t.Fatalf("Scalar(string) = %q, want ann", first)
+	}
+
+	// SQLite returns MAX over a timestamp column as text
+	mock.ExpectQuery("SELECT MAX(created_at) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(created_at)"}).AddRow("2024-03-01 12:30:00.5+02:00"))
+	var latest time.Time
+	if err := NewSelect(users).WithConnection(conn).Select("MAX(created_at)").Scalar(ctx, &latest); err != nil {
+		t.Fatalf("Scalar(time) error = %v", err)
+	}
+	if want := time.Date(2024, 3, 1, 10, 30, 0, 5e8, time.UTC); !latest.Equal(want) {
+		t.Fatalf("Scalar(time) = %v, want %v", latest, want)
+	}
+
+	want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT MAX(created_at) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(created_at)"}).AddRow(want))
+	var native sql.NullTime
+	if err := NewSelect(users).WithConnection(conn).Select("MAX(created_at)").Scalar(ctx, &native); err != nil {
+		t.Fatalf("Scalar(sql.NullTime) error = %v", err)
+	}
+	if !native.Valid || !native.Time.Equal(want) {
+		t.Fatalf("Scalar(sql.NullTime) = %v, want %v", native, want)
+	}
+
+	mock.ExpectQuery("SELECT MAX(created_at) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(created_at)"}).AddRow(nil))
+	latestPtr := &latest
+	if err := NewSelect(users).WithConnection(conn).Select("MAX(created_at)").Scalar(ctx, &latestPtr); err != nil {
+		t.Fatalf("Scalar(*time.Time) error = %v", err)
+	}
+	if latestPtr != nil {
+		t.Fatalf("Scalar(*time.Time) = %v, want nil for NULL", latestPtr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectScalarErrors(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "ann"))
+	var id int64
+	err := NewSelect(users).WithConnection(conn).Select("id", "name").Scalar(ctx, &id)
+	if err == nil || err.Error() != "Scalar expects exactly one column, got 2" {
+		t.Fatalf("Scalar() error = %v, want column count error", err)
+	}
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(2)))
+	err = NewSelect(users).WithConnection(conn).Select("id").Scalar(ctx, &id)
+	if err == nil || err.Error() != "Scalar expects exactly one row" {
+		t.Fatalf("Scalar() error = %v, want row count error", err)
+	}
+
+	var user User
+	err = NewSelect(users).WithConnection(conn).Select("id").Scalar(ctx, &user)
+	if err == nil || !strings.Contains(err.Error(), "single value") {
+		t.Fatalf("Scalar() error = %v, want struct dest error", err)
+	}
+}
+
+func TestSelectCount(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		builder *SelectBuilder
+		want    string
+	}{
+		{
+			name:    "fast path",
+			builder: NewSelect(users).Select("id", "name").Where(expr.Gt(users.C.Age, 18)).OrderBy("name").Limit(10),
+			want:    "SELECT COUNT(*) FROM users WHERE users.age > ?",
+		},
+		{
+			name:    "group by",
+			builder: NewSelect(users).Select("age").Where(expr.Gt(users.C.Age, 18)).GroupBy("age"),
+			want:    "SELECT COUNT(*) FROM (SELECT age FROM users WHERE users.age > ? GROUP BY age) t",
+		},
+		{
+			name:    "distinct",
+			builder: NewSelect(users).Select("email").Distinct().Where(expr.Gt(users.C.Age, 18)),
+			want:    "SELECT COUNT(*) FROM (SELECT DISTINCT email FROM users WHERE users.age > ?) t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.builder.countQuery()
+			if err != nil {
+				t.Fatalf("countQuery() error = %v", err)
+			}
+			sql, args, err := query.ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+			if len(args) != 1 || args[0] != 18 {
+				t.Fatalf("args = %v, want [18]", args)
+			}
+		})
+	}
+}
+
+func TestSelectCountGroups(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM (SELECT age FROM users GROUP BY age) t").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	n, err := NewSelect(users).WithConnection(conn).Select("age").GroupBy("age").Count(context.Background())
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("Count() = %d, want 3", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectCountDistinct(t *testing.T) {
+	users := newUsersTable()
+
+	query, err := NewSelect(users).Where(expr.Gt(users.C.Age, 18)).OrderBy("name").countDistinctQuery("email")
+	if err != nil {
+		t.Fatalf("countDistinctQuery() error = %v", err)
+	}
+	sql, args, err := query.ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT COUNT(DISTINCT email) FROM users WHERE users.age > ?"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 1 || args[0] != 18 {
+		t.Fatalf("args = %v, want [18]", args)
+	}
+
+	if _, err := NewSelect(users).StrictColumns(true).countDistinctQuery("password"); err == nil {
+		t.Fatal("expected unknown column error in strict mode")
+	}
+}
+
+func TestSelectExists(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    string
+		row     driver.Value
+	}{
+		{
+			name:    "postgres",
+			dialect: &postgres.PostgresDialect{},
+			want:    "SELECT EXISTS(SELECT 1 FROM users WHERE users.age > $1)",
+			row:     true,
+		},
+		{
+			name:    "sqlite",
+			dialect: &sqlite.SQLiteDialect{},
+			want:    "SELECT EXISTS(SELECT 1 FROM users WHERE users.age > ?)",
+			row:     int64(1),
+		},
+		{
+			name:    "mysql",
+			dialect: &mysql.MySQLDialect{},
+			want:    "SELECT EXISTS(SELECT 1 FROM users WHERE users.age > ?)",
+			row:     []byte("1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock := newTestConn(t, tt.dialect)
+			mock.ExpectQuery(tt.want).
+				WithArgs(18).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.row))
+
+			found, err := NewSelect(users).WithConnection(conn).
+				Select("id", "name").
+				Where(expr.Gt(users.C.Age, 18)).
+				OrderBy("name").
+				Exists(context.Background())
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if !found {
+				t.Fatal("Exists() = false, want true")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSelectExistsNoMatch(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+
+	mock.ExpectQuery("SELECT EXISTS(SELECT 1 FROM users WHERE users.name = ?)").
+		WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(int64(0)))
+
+	found, err := NewSelect(users).WithConnection(conn).
+		Where(expr.Eq(users.C.Name, "nobody")).
+		Exists(context.Background())
+	if err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+	if found {
+		t.Fatal("Exists() = true, want false")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectFirstLast(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT * FROM users WHERE users.age > $1 ORDER BY age ASC, id DESC LIMIT $2").
+		WithArgs(18, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "age"}).AddRow(int64(4), "Ann", "ann@example.com", 19))
+	mock.ExpectQuery("SELECT * FROM users WHERE users.age > $1 ORDER BY age DESC, id DESC LIMIT $2").
+		WithArgs(18, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "age"}).AddRow(int64(9), "Bob", "bob@example.com", 70))
+
+	q := NewSelect(users).WithConnection(conn).Where(expr.Gt(users.C.Age, 18)).OrderByDesc("id")
+
+	var youngest, oldest User
+	if err := q.First(context.Background(), &youngest, "age"); err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	if err := q.Last(context.Background(), &oldest, "age"); err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+	if youngest.ID != 4 || oldest.ID != 9 {
+		t.Fatalf("First/Last = %d/%d, want 4/9", youngest.ID, oldest.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectFirstNoRows(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT * FROM users ORDER BY id ASC LIMIT $1").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "age"}))
+
+	var u User
+	err := NewSelect(users).WithConnection(conn).First(context.Background(), &u, "id")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("First() error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+type PostsColumns struct {
+	ID        *table.Column[int64]
+	UserID    *table.Column[int64]
+	Published *table.Column[bool]
+}
+
+func TestSelectJoinArgsPlaceholderNumbering(t *testing.T) {
+	users := newUsersTable()
+	posts := table.NewTable("posts", PostsColumns{
+		ID:        table.Col[int64]("id").PrimaryKey(),
+		UserID:    table.Col[int64]("user_id"),
+		Published: table.Col[bool]("published"),
+	})
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	newQuery := func() *SelectBuilder {
+		return NewSelect(users).
+			WithConnection(conn).
+			Join(posts, expr.Raw("posts.user_id = users.id AND posts.published = ?", true)).
+			Where(expr.Gt(users.C.Age, 18)).
+			Where(expr.Eq(users.C.Name, "john")).
+			Limit(5)
+	}
+
+	mock.ExpectQuery("SELECT * FROM users INNER JOIN posts ON posts.user_id = users.id AND posts.published = $1 WHERE users.age > $2 AND users.name = $3 LIMIT $4").
+		WithArgs(true, 18, "john", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT COUNT(*) FROM users INNER JOIN posts ON posts.user_id = users.id AND posts.published = $1 WHERE users.age > $2 AND users.name = $3").
+		WithArgs(true, 18, "john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+
+	var got []User
+	if err := newQuery().All(context.Background(), &got); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if _, err := newQuery().Count(context.Background()); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectScalarSubqueryArgOrder(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	avgAge := NewSelect(users).Select("AVG(age)").Where(expr.Eq(users.C.Name, "john"))
+
+	mock.ExpectQuery("SELECT id, name FROM users WHERE users.id > $1 AND users.age > (SELECT AVG(age) FROM users WHERE users.name = $2) AND users.name != $3 LIMIT $4").
+		WithArgs(10, "john", "jane", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "bob"))
+
+	var got []User
+	err := NewSelect(users).
+		WithConnection(conn).
+		Select("id", "name").
+		Where(expr.Gt(users.C.ID, 10)).
+		Where(expr.Gt(users.C.Age, expr.ScalarSubquery(avgAge))).
+		Where(expr.Ne(users.C.Name, "jane")).
+		Limit(5).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectMixedWhereArgOrder(t *testing.T) {
+	users := newUsersTable()
+	pg := &postgres.PostgresDialect{}
+
+	tests := []struct {
+		name     string
+		build    func() *SelectBuilder
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "raw between typed",
+			build: func() *SelectBuilder {
+				return NewSelect(users).
+					Where(expr.Eq(users.C.Name, "a")).
+					Where(expr.Raw("users.age BETWEEN ? AND ?", 1, 2)).
+					Where(expr.In(users.C.ID, 3, 4))
+			},
+			wantSQL:  "SELECT * FROM users WHERE users.name = $1 AND users.age BETWEEN $2 AND $3 AND users.id IN ($4, $5)",
+			wantArgs: []interface{}{"a", 1, 2, int64(3), int64(4)},
+		},
+		{
+			name: "nested and/or",
+			build: func() *SelectBuilder {
+				return NewSelect(users).Where(expr.Or(
+					expr.And(expr.Raw("users.email LIKE ?", "%@x"), expr.Gt(users.C.Age, 5)),
+					expr.And(expr.Eq(users.C.Name, "b"), expr.Raw("users.id = ? OR users.id = ?", 6, 7)),
+				))
+			},
+			wantSQL:  "SELECT * FROM users WHERE ((((users.email LIKE $1) AND (users.age > $2))) OR (((users.name = $3) AND (users.id = $4 OR users.id = $5))))",
+			wantArgs: []interface{}{"%@x", 5, "b", 6, 7},
+		},
+		{
+			name: "raw literal question mark",
+			build: func() *SelectBuilder {
+				return NewSelect(users).
+					Where(expr.Raw("users.name <> '?' AND users.age < ?", 8)).
+					Where(expr.Compare(expr.Fragment("coalesce(?, '?')", users.C.Email), "=", "c")).
+					Where(expr.Between(users.C.Age, 9, 10))
+			},
+			wantSQL:  "SELECT * FROM users WHERE users.name <> '?' AND users.age < $1 AND coalesce(users.email, '?') = $2 AND users.age BETWEEN $3 AND $4",
+			wantArgs: []interface{}{8, "c", 9, 10},
+		},
+		{
+			name: "join, where, having and limit",
+			build: func() *SelectBuilder {
+				return NewSelect(users).
+					Join(users, expr.Raw("users.id = ?", 11)).
+					Where(expr.Raw("users.age > ?", 12)).
+					Where(expr.Eq(users.C.Name, "d")).
+					GroupBy("users.age").
+					Having(expr.Raw("COUNT(*) > ?", 13)).
+					Limit(14)
+			},
+			wantSQL:  "SELECT * FROM users INNER JOIN users ON users.id = $1 WHERE users.age > $2 AND users.name = $3 GROUP BY users.age HAVING COUNT(*) > $4 LIMIT $5",
+			wantArgs: []interface{}{11, 12, "d", 13, 14},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.build().DebugSQL(pg)
+			if sql != tt.wantSQL {
+				t.Fatalf("DebugSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectScalarSubqueryError(t *testing.T) {
+	users := newUsersTable()
+
+	invalid := NewSelect(users).Select("AVG(age)").GroupByRollup()
+	_, _, err := NewSelect(users).Where(expr.Gt(users.C.Age, expr.ScalarSubquery(invalid))).ToSQL()
+	if err == nil {
+		t.Fatalf("expected the subquery error")
+	}
+}
+
+func TestSelectILikeUsesConnectionDialect(t *testing.T) {
+	users := newUsersTable()
+	conn, _ := newTestConn(t, &sqlite.SQLiteDialect{})
+
+	sql, _, err := NewSelect(users).WithConnection(conn).Where(expr.ILike(users.C.Email, "%@example.com")).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE LOWER(users.email) LIKE LOWER(?)"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestSelectWhereOr(t *testing.T) {
+	users := newUsersTable()
+
+	sql, args, err := NewSelect(users).
+		Where(expr.Gt(users.C.Age, 18)).
+		WhereOr(expr.Eq(users.C.Name, "john"), expr.Eq(users.C.Name, "jane")).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE users.age > ? AND ((users.name = ?) OR (users.name = ?))"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 3 {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSelectWhereAnyAll(t *testing.T) {
+	users := newUsersTable()
+	likes := []expr.Expr{expr.Like(users.C.Email, "%@acme.com"), expr.Like(users.C.Email, "%@acme.org")}
+	ranges := []expr.Expr{expr.Gt(users.C.Age, 18), expr.Lt(users.C.Age, 65)}
+
+	sugar, sugarArgs, err := NewSelect(users).
+		Where(expr.Eq(users.C.Name, "john")).
+		WhereAny(likes...).
+		WhereAll(ranges...).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	manual, manualArgs, err := NewSelect(users).
+		Where(expr.Eq(users.C.Name, "john")).
+		Where(expr.Or(likes...)).
+		Where(expr.And(ranges...)).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if sugar != manual {
+		t.Fatalf("WhereAny/WhereAll = %q, want %q", sugar, manual)
+	}
+	if !reflect.DeepEqual(sugarArgs, manualArgs) {
+		t.Fatalf("args = %v, want %v", sugarArgs, manualArgs)
+	}
+	if want := "SELECT * FROM users WHERE users.name = ? AND ((users.email LIKE ?) OR (users.email LIKE ?)) AND ((users.age > ?) AND (users.age < ?))"; sugar != want {
+		t.Fatalf("ToSQL() = %q, want %q", sugar, want)
+	}
+}
+
+func TestSelectOrWhere(t *testing.T) {
+	users := newUsersTable()
+
+	sql, args, err := NewSelect(users).
+		Where(expr.Gt(users.C.Age, 18)).
+		Where(expr.Eq(users.C.Name, "john")).
+		OrWhere(expr.IsNull(users.C.Email)).
+		Where(expr.Lt(users.C.ID, int64(100))).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	want := "SELECT * FROM users WHERE ((((users.age > ?) AND (users.name = ?))) OR (users.email IS NULL)) AND users.id < ?"
+	if sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 3 || args[2] != int64(100) {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	sql, _, err = NewSelect(users).OrWhere(expr.IsNull(users.C.Email)).ToSQL()
+	if err != nil || sql != "SELECT * FROM users WHERE users.email IS NULL" {
+		t.Fatalf("ToSQL() = %q, %v", sql, err)
+	}
+}
+
+func TestSelectWhereEq(t *testing.T) {
+	users := newUsersTable()
+	filters := map[string]interface{}{"name": "john", "age": 30, "email": nil}
+
+	for i := 0; i < 3; i++ {
+		sql, args, err := NewSelect(users).WhereEq(filters).ToSQL()
+		if err != nil {
+			t.Fatalf("ToSQL() error = %v", err)
+		}
+		if want := "SELECT * FROM users WHERE users.age = ? AND users.email IS NULL AND users.name = ?"; sql != want {
+			t.Fatalf("ToSQL() = %q, want %q", sql, want)
+		}
+		if want := []interface{}{30, "john"}; !reflect.DeepEqual(args, want) {
+			t.Fatalf("args = %v, want %v", args, want)
+		}
+	}
+
+	_, _, err := NewSelect(users).WhereEq(map[string]interface{}{"name = name OR 1": 1}).ToSQL()
+	if err == nil || err.Error() != `unknown column "name = name OR 1" in WhereEq` {
+		t.Fatalf("ToSQL() error = %v, want unknown column", err)
+	}
+}
+
+func TestSelectAliasedAggregateScan(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	type ageGroup struct {
+		Age   int   `sql:"age"`
+		Total int64 `sql:"total"`
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) AS total, age FROM users GROUP BY age ORDER BY total DESC").
+		WillReturnRows(sqlmock.NewRows([]string{"total", "age"}).
+			AddRow(int64(3), 30).
+			AddRow(int64(1), 41))
+
+	var got []ageGroup
+	err := NewSelect(users).WithConnection(conn).
+		Select("COUNT(*) AS total", "age").
+		GroupBy("age").
+		OrderByDesc("total").
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if want := []ageGroup{{Age: 30, Total: 3}, {Age: 41, Total: 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+
+	sqliteConn, sqliteMock := newTestConn(t, &sqlite.SQLiteDialect{})
+	sqliteMock.ExpectQuery("SELECT COUNT(*), age FROM users GROUP BY age").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)", "age"}).AddRow(int64(3), 30))
+	err = NewSelect(users).WithConnection(sqliteConn).
+		Select("COUNT(*)", "age").
+		GroupBy("age").
+		All(context.Background(), &got)
+	if err == nil || !strings.Contains(err.Error(), "alias computed columns") {
+		t.Fatalf("All() error = %v, want alias hint", err)
+	}
+}
+
+func TestSelectCoalesce(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	type displayUser struct {
+		ID          int64  `sql:"id"`
+		DisplayName string `sql:"display_name"`
+	}
+
+	mock.ExpectQuery("SELECT id, COALESCE(users.email, users.name, $1) AS display_name FROM users WHERE COALESCE(users.age, $2) >= $3").
+		WithArgs("anonymous", 0, 18).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "display_name"}).
+			AddRow(int64(1), "ann@example.com").
+			AddRow(int64(2), "bob"))
+
+	var got []displayUser
+	err := NewSelect(users).WithConnection(conn).
+		Select("id").
+		SelectExpr(expr.Coalesce(users.C.Email, users.C.Name, expr.V("anonymous")), "display_name").
+		Where(expr.Compare(expr.Coalesce(users.C.Age, expr.V(0)), ">=", 18)).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if want := []displayUser{{1, "ann@example.com"}, {2, "bob"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	if _, _, err := NewSelect(users).SelectExpr(expr.Coalesce(users.C.Name), "bad alias").ToSQL(); err == nil {
+		t.Fatal("ToSQL() error = nil, want invalid alias error")
+	}
+}
+
+func TestSelectGroupByRollup(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    string
+	}{
+		{"postgres", &postgres.PostgresDialect{}, "SELECT name, age, COUNT(*) FROM users GROUP BY ROLLUP (name, age)"},
+		{"mysql", &mysql.MySQLDialect{}, "SELECT name, age, COUNT(*) FROM users GROUP BY name, age WITH ROLLUP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			sql, _, err := NewSelect(users).WithConnection(conn).
+				Select("name", "age", "COUNT(*)").
+				GroupByRollup("name", "age").
+				ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+
+	conn, _ := newTestConn(t, &sqlite.SQLiteDialect{})
+	_, _, err := NewSelect(users).WithConnection(conn).GroupByRollup("name").ToSQL()
+	if err == nil || err.Error() != "ROLLUP is not supported by the sqlite dialect" {
+		t.Fatalf("ToSQL() error = %v, want ROLLUP unsupported", err)
+	}
+}
+
+func TestSelectWhereInTuple(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT * FROM users WHERE users.age > $1 AND (users.name, users.email) IN (($2, $3), ($4, $5))").
+		WithArgs(18, "ann", "a@x", "bob", "b@x").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	var got []User
+	err := NewSelect(users).
+		WithConnection(conn).
+		Where(expr.Gt(users.C.Age, 18)).
+		Where(expr.InTuple([]string{"users.name", "users.email"}, [][]interface{}{{"ann", "a@x"}, {"bob", "b@x"}})).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	mysqlConn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	_, _, err = NewSelect(users).WithConnection(mysqlConn).
+		Where(expr.InTuple([]string{"users.name", "users.email"}, [][]interface{}{{"ann", "a@x"}})).
+		ToSQL()
+	if err == nil || err.Error() != "row values is not supported by the mysql dialect" {
+		t.Fatalf("ToSQL() error = %v, want row values unsupported", err)
+	}
+}
+
+func TestSelectGroupByGroupingSets(t *testing.T) {
+	users := newUsersTable()
+	conn, _ := newTestConn(t, &postgres.PostgresDialect{})
+
+	sql, _, err := NewSelect(users).WithConnection(conn).
+		Select("name", "age", "COUNT(*)").
+		GroupByGroupingSets([][]string{{"name", "age"}, {"name"}, {}}).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := "SELECT name, age, COUNT(*) FROM users GROUP BY GROUPING SETS ((name, age), (name), ())"; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+
+	mysqlConn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	_, _, err = NewSelect(users).WithConnection(mysqlConn).GroupByGroupingSets([][]string{{"name"}}).ToSQL()
+	if err == nil || err.Error() != "GROUPING SETS is not supported by the mysql dialect" {
+		t.Fatalf("ToSQL() error = %v, want GROUPING SETS unsupported", err)
+	}
+}
+
+func TestSelectFetchFirst(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name     string
+		dialect  dialect.Dialect
+		withTies bool
+		want     string
+		wantArgs []interface{}
+	}{
+		{"postgres", &postgres.PostgresDialect{}, false, "SELECT * FROM users ORDER BY age DESC OFFSET ? ROWS FETCH FIRST ? ROWS ONLY", []interface{}{20, 10}},
+		{"postgres with ties", &postgres.PostgresDialect{}, true, "SELECT * FROM users ORDER BY age DESC OFFSET ? ROWS FETCH FIRST ? ROWS WITH TIES", []interface{}{20, 10}},
+		{"sqlite", &sqlite.SQLiteDialect{}, false, "SELECT * FROM users ORDER BY age DESC LIMIT ? OFFSET ?", []interface{}{10, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			sql, args, err := NewSelect(users).WithConnection(conn).
+				OrderByDesc("age").
+				Offset(20).
+				FetchFirst(10, tt.withTies).
+				ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectFetchFirstWithTiesErrors(t *testing.T) {
+	users := newUsersTable()
+
+	mysqlConn, _ := newTestConn(t, &mysql.MySQLDialect{})
+	_, _, err := NewSelect(users).WithConnection(mysqlConn).OrderByDesc("age").FetchFirst(3, true).ToSQL()
+	if err == nil || err.Error() != "FETCH FIRST ... WITH TIES is not supported by the mysql dialect" {
+		t.Fatalf("ToSQL() error = %v, want WITH TIES unsupported", err)
+	}
+
+	pgConn, _ := newTestConn(t, &postgres.PostgresDialect{})
+	_, _, err = NewSelect(users).WithConnection(pgConn).FetchFirst(3, true).ToSQL()
+	if err == nil || err.Error() != "FetchFirst WITH TIES requires ORDER BY" {
+		t.Fatalf("ToSQL() error = %v, want ORDER BY required", err)
+	}
+}
+
+func TestSelectOffsetWithoutLimit(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    string
+	}{
+		{"postgres", &postgres.PostgresDialect{}, "SELECT * FROM users OFFSET ?"},
+		{"sqlite", &sqlite.SQLiteDialect{}, "SELECT * FROM users LIMIT -1 OFFSET ?"},
+		{"mysql", &mysql.MySQLDialect{}, "SELECT * FROM users LIMIT 18446744073709551615 OFFSET ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			sql, args, err := NewSelect(users).WithConnection(conn).Offset(20).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+			if len(args) != 1 || args[0] != 20 {
+				t.Fatalf("unexpected args %v", args)
+			}
+		})
+	}
+}
+
+func TestSelectJoinOnColumns(t *testing.T) {
+	users := newUsersTable()
+	posts := table.NewTable("posts", PostsColumns{
+		ID:        table.Col[int64]("id").PrimaryKey(),
+		UserID:    table.Col[int64]("user_id"),
+		Published: table.Col[bool]("published"),
+	})
+
+	sql, args, err := NewSelect(users).
+		Select("users.name", "posts.id").
+		Join(posts, expr.And(expr.Eq(posts.C.UserID, users.C.ID), expr.Eq(posts.C.Published, true))).
+		Where(expr.Gt(posts.C.ID, users.C.Age)).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	want := "SELECT users.name, posts.id FROM users INNER JOIN posts ON ((posts.user_id = users.id) AND (posts.published = ?)) WHERE posts.id > users.age"
+	if sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+	if len(args) != 1 || args[0] != true {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSelectRightAndFullJoin(t *testing.T) {
+	users := newUsersTable()
+	posts := table.NewTable("posts", PostsColumns{
+		ID:     table.Col[int64]("id").PrimaryKey(),
+		UserID: table.Col[int64]("user_id"),
+	})
+	on := expr.Eq(posts.C.UserID, users.C.ID)
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		full    bool
+		want    string
+		wantErr string
+	}{
+		{"postgres right", &postgres.PostgresDialect{}, false, "SELECT * FROM users RIGHT JOIN posts ON posts.user_id = users.id", ""},
+		{"postgres full", &postgres.PostgresDialect{}, true, "SELECT * FROM users FULL OUTER JOIN posts ON posts.user_id = users.id", ""},
+		{"sqlite 3.39 right", &sqlite.SQLiteDialect{Version: "3.39.2"}, false, "SELECT * FROM users RIGHT JOIN posts ON posts.user_id = users.id", ""},
+		{"sqlite 3.38 right", &sqlite.SQLiteDialect{Version: "3.38.5"}, false, "", "RIGHT JOIN is not supported by the sqlite dialect"},
+		{"sqlite 3.38 full", &sqlite.SQLiteDialect{Version: "3.38.5"}, true, "", "FULL OUTER JOIN is not supported by the sqlite dialect"},
+		{"mysql right", &mysql.MySQLDialect{}, false, "SELECT * FROM users RIGHT JOIN posts ON posts.user_id = users.id", ""},
+		{"mysql full", &mysql.MySQLDialect{}, true, "", "FULL OUTER JOIN is not supported by the mysql dialect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _ := newTestConn(t, tt.dialect)
+			b := NewSelect(users).WithConnection(conn)
+			if tt.full {
+				b.FullJoin(posts, on)
+			} else {
+				b.RightJoin(posts, on)
+			}
+			sql, _, err := b.ToSQL()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ToSQL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectColumnsJoined(t *testing.T) {
+	users := newUsersTable()
+	posts := table.NewTable("posts", PostsColumns{
+		ID:        table.Col[int64]("id").PrimaryKey(),
+		UserID:    table.Col[int64]("user_id"),
+		Published: table.Col[bool]("published"),
+	})
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT users.name, posts.user_id, posts.published FROM users INNER JOIN posts ON posts.user_id = users.id WHERE posts.published = $1").
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "user_id", "published"}).
+			AddRow("john", int64(1), true).
+			AddRow("jane", int64(2), true))
+
+	type UserPost struct {
+		Name      string `sql:"name"`
+		UserID    int64  `sql:"user_id"`
+		Published bool   `sql:"published"`
+	}
+	var got []UserPost
+	err := NewSelect(users).
+		WithConnection(conn).
+		SelectColumns(users.C.Name, posts.C.UserID, posts.C.Published).
+		Join(posts, expr.Eq(posts.C.UserID, users.C.ID)).
+		Where(expr.Eq(posts.C.Published, true)).
+		StrictColumns(true).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	want := []UserPost{{Name: "john", UserID: 1, Published: true}, {Name: "jane", UserID: 2, Published: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectColumnsJoinedSameName(t *testing.T) {
+	users := newUsersTable()
+	posts := table.NewTable("posts", PostsColumns{
+		ID:        table.Col[int64]("id").PrimaryKey(),
+		UserID:    table.Col[int64]("user_id"),
+		Published: table.Col[bool]("published"),
+	})
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT users.id, posts.id, users.name FROM users INNER JOIN posts ON posts.user_id = users.id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "id", "name"}).
+			AddRow(int64(1), int64(10), "john").
+			AddRow(int64(2), int64(20), "jane"))
+
+	type UserPost struct {
+		ID     int64  `sql:"id"`
+		PostID int64  `sql:"posts.id"`
+		Name   string `sql:"name"`
+	}
+	var got []UserPost
+	err := NewSelect(users).
+		WithConnection(conn).
+		SelectColumns(users.C.ID, posts.C.ID, users.C.Name).
+		Join(posts, expr.Eq(posts.C.UserID, users.C.ID)).
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	want := []UserPost{{ID: 1, PostID: 10, Name: "john"}, {ID: 2, PostID: 20, Name: "jane"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectPluck(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT id FROM users WHERE users.age > $1 ORDER BY id ASC").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(3)))
+	mock.ExpectQuery("SELECT name FROM users WHERE users.age > $1 ORDER BY id ASC").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("john").AddRow("jane"))
+
+	query := NewSelect(users).WithConnection(conn).
+		Select("id", "name", "email").
+		Where(expr.Gt(users.C.Age, 18)).
+		OrderBy("id")
+
+	var ids []int64
+	if err := query.Pluck(context.Background(), "id", &ids); err != nil {
+		t.Fatalf("Pluck(id) error = %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 3}) {
+		t.Fatalf("Pluck(id) = %v", ids)
+	}
+
+	var names []string
+	if err := query.Pluck(context.Background(), "name", &names); err != nil {
+		t.Fatalf("Pluck(name) error = %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"john", "jane"}) {
+		t.Fatalf("Pluck(name) = %v", names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSelectJoinOnColumnsQuoted(t *testing.T) {
+	users := newUsersTable()
+	orders := table.NewTable("order", orderColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		User: table.Col[string]("user"),
+	})
+	conn, _ := newTestConn(t, &postgres.PostgresDialect{})
+	conn.quote = true
+
+	sql, _, err := NewSelect(users).WithConnection(conn).
+		Join(orders, expr.Eq(orders.C.ID, users.C.ID)).
+		Where(expr.Eq(users.C.ID, int64(1))).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	// Both tables have an id column, so predicates stay qualified
+	if want := `SELECT * FROM "users" INNER JOIN "order" ON "order"."id" = "users"."id" WHERE "users"."id" = ?`; sql != want {
+		t.Fatalf("ToSQL() = %q, want %q", sql, want)
+	}
+}
@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// Tracer starts a span around every statement a builder executes. It keeps
+// the package free of a tracing dependency; adapt it to OpenTelemetry with a
+// few lines mapping SpanInfo to db.* attributes.
+type Tracer interface {
+	// StartSpan starts a span and returns the context carrying it
+	StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span)
+}
+
+// Span is a single traced statement
+type Span interface {
+	// RecordError records a failed statement on the span
+	RecordError(err error)
+
+	// End finishes the span
+	End()
+}
+
+// SpanInfo describes a traced statement using the OpenTelemetry database
+// semantic conventions
+type SpanInfo struct {
+	System    string // db.system, e.g. "postgresql"
+	Statement string // db.statement, the dialect-formatted SQL
+	Operation string // db.operation, e.g. "SELECT"
+	Table     string // db.sql.table
+}
+
+// tableBuilder is implemented by builders targeting a single table
+type tableBuilder interface {
+	targetTable() table.TableInterface
+}
+
+// startSpan starts a span for the statement when the connection has a tracer.
+func (s *statement) startSpan(b Builder) {
+	tracer := s.conn.Tracer()
+	if tracer == nil {
+		return
+	}
+
+	info := SpanInfo{
+		System:    s.conn.Dialect().Name(),
+		Statement: s.query,
+		Operation: operation(s.rawSQL),
+	}
+	if tb, ok := b.(tableBuilder); ok && tb.targetTable() != nil {
+		info.Table = tb.targetTable().Name()
+	}
+	s.ctx, s.span = tracer.StartSpan(s.ctx, info)
+}
+
+// operation returns the leading SQL keyword of a statement
+func operation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if i := strings.IndexByte(sql, ' '); i >= 0 {
+		sql = sql[:i]
+	}
+	return strings.ToUpper(sql)
+}
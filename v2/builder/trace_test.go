@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	info  SpanInfo
+	err   error
+	ended bool
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span) {
+	span := &recordingSpan{info: info}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *recordingSpan) RecordError(err error) { s.err = err }
+func (s *recordingSpan) End()                  { s.ended = true }
+
+func TestTracerSpans(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+	tracer := &recordingTracer{}
+	conn.tracer = tracer
+
+	mock.ExpectQuery("SELECT * FROM users WHERE users.id = $1").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	failure := errors.New("boom")
+	mock.ExpectExec("DELETE FROM users").WillReturnError(failure)
+
+	var got []User
+	if err := NewSelect(users).WithConnection(conn).Where(expr.Eq(users.C.ID, 1)).All(context.Background(), &got); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if _, err := NewDelete(conn.Dialect(), users).WithConnection(conn).AllowNoWhere().Exec(context.Background()); !errors.Is(err, failure) {
+		t.Fatalf("Exec() error = %v, want %v", err, failure)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(tracer.spans))
+	}
+	want := SpanInfo{System: "postgresql", Statement: "SELECT * FROM users WHERE users.id = $1", Operation: "SELECT", Table: "users"}
+	if got := tracer.spans[0]; got.info != want || got.err != nil || !got.ended {
+		t.Fatalf("select span = %+v, want info %+v", got, want)
+	}
+	if got := tracer.spans[1]; got.info.Operation != "DELETE" || !errors.Is(got.err, failure) || !got.ended {
+		t.Fatalf("delete span = %+v, want recorded error", got)
+	}
+}
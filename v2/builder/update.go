@@ -1,7 +1,11 @@
 package builder
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/guadalsistema/go-compose-sql/v2/dialect"
@@ -11,11 +15,17 @@ import (
 
 // UpdateBuilder builds UPDATE queries
 type UpdateBuilder struct {
+	conn       ConnectionInterface
 	dialect    dialect.Dialect
 	table      table.TableInterface
 	sets       map[string]interface{} // Column-value pairs to update
+	jsonSets   []jsonPathSet          // SetJSONPath calls, in order
 	whereExprs []expr.Expr
 	returning  []string
+	byKey      []map[string]interface{} // Rows for ValuesByKey
+	keyCol     string
+	allowAll   bool // AllowNoWhere
+	err        error
 }
 
 // NewUpdate creates a new UPDATE builder
@@ -33,26 +43,251 @@ func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
 	return b
 }
 
+// jsonPathSet is one SetJSONPath call
+type jsonPathSet struct {
+	column string
+	path   []string
+	value  string // JSON encoded
+}
+
+// SetJSONPath sets the value at path inside the JSON column, leaving the
+// rest of the document as is. path is dot separated object keys and array
+// indexes, e.g. "address.city" or "tags.0"; value is encoded as JSON.
+// Postgres renders col = jsonb_set(col, '{address,city}', ?) (the column
+// must be jsonb), SQLite col = json_set(col, '$.address.city', json(?)).
+// Several paths in one column nest into a single assignment.
+func (b *UpdateBuilder) SetJSONPath(column, path string, value interface{}) *UpdateBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.byKey) > 0 {
+		b.err = fmt.Errorf("SetJSONPath cannot be combined with ValuesByKey")
+		return b
+	}
+	keys, err := parseJSONPath(path)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		b.err = fmt.Errorf("SetJSONPath %s: %w", column, err)
+		return b
+	}
+	b.jsonSets = append(b.jsonSets, jsonPathSet{column: column, path: keys, value: string(encoded)})
+	return b
+}
+
+// parseJSONPath splits a dotted JSON path. Elements are written into the
+// SQL, so only identifiers and array indexes are accepted.
+func parseJSONPath(path string) ([]string, error) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if !jsonPathKey.MatchString(key) {
+			return nil, fmt.Errorf("invalid JSON path %q: elements must be identifiers or array indexes", path)
+		}
+	}
+	return keys, nil
+}
+
+var jsonPathKey = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*|[0-9]+)$`)
+
+// jsonSetSQL renders the SetJSONPath assignments, one per column in order
+// of first use, appending their args
+func (b *UpdateBuilder) jsonSetSQL(quote func(string) string, args []interface{}) ([]string, []interface{}, error) {
+	if len(b.jsonSets) == 0 {
+		return nil, args, nil
+	}
+	if err := dialect.Require(b.dialect, dialect.FeatureJSONSet); err != nil {
+		return nil, nil, err
+	}
+	setter, ok := b.dialect.(dialect.JSONPathSetter)
+	if !ok {
+		return nil, nil, fmt.Errorf("dialect %s does not render JSON path updates", b.dialect.Name())
+	}
+
+	var columns []string
+	docs := make(map[string]string)
+	for _, s := range b.jsonSets {
+		if _, ok := b.sets[s.column]; ok {
+			return nil, nil, fmt.Errorf("column %s is assigned by both Set and SetJSONPath", s.column)
+		}
+		doc, ok := docs[s.column]
+		if !ok {
+			columns = append(columns, s.column)
+			doc = quote(s.column)
+		}
+		docs[s.column] = setter.JSONSet(doc, s.path)
+	}
+
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = quote(col) + " = " + docs[col]
+		for _, s := range b.jsonSets {
+			if s.column == col {
+				args = append(args, bindColumn(b.table, col, s.value))
+			}
+		}
+	}
+	return parts, args, nil
+}
+
+// ValuesByKey updates many rows in one statement. rows is a slice of structs
+// or maps (as for InsertBuilder.Values); each row is matched on keyCol and
+// its other columns are set. Postgres renders
+// UPDATE t SET c = v.c FROM (VALUES ...) AS v(key, c) WHERE t.key = v.key;
+// other dialects run one UPDATE per row inside a transaction on Exec.
+func (b *UpdateBuilder) ValuesByKey(rows interface{}, keyCol string) *UpdateBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.jsonSets) > 0 {
+		b.err = fmt.Errorf("SetJSONPath cannot be combined with ValuesByKey")
+		return b
+	}
+	normalized, err := normalizeInsertValues(rows, b.table)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	for _, row := range normalized {
+		if _, ok := row[keyCol]; !ok {
+			b.err = fmt.Errorf("row is missing key column %s", keyCol)
+			return b
+		}
+	}
+	b.byKey = append(b.byKey, normalized...)
+	b.keyCol = keyCol
+	return b
+}
+
 // Where adds a WHERE condition
 func (b *UpdateBuilder) Where(condition expr.Expr) *UpdateBuilder {
 	b.whereExprs = append(b.whereExprs, condition)
 	return b
 }
 
+// WhereOr adds one WHERE condition that holds when any of conditions does;
+// it is ANDed with the other conditions like Where
+func (b *UpdateBuilder) WhereOr(conditions ...expr.Expr) *UpdateBuilder {
+	b.whereExprs = append(b.whereExprs, expr.Or(conditions...))
+	return b
+}
+
+// WhereAny is WhereOr, named to pair with WhereAll
+func (b *UpdateBuilder) WhereAny(conditions ...expr.Expr) *UpdateBuilder {
+	return b.WhereOr(conditions...)
+}
+
+// WhereAll adds one WHERE condition that holds when every one of
+// conditions does, grouped like WhereOr
+func (b *UpdateBuilder) WhereAll(conditions ...expr.Expr) *UpdateBuilder {
+	b.whereExprs = append(b.whereExprs, expr.And(conditions...))
+	return b
+}
+
+// OrWhere ORs condition with every condition added so far, so
+// Where(a).Where(b).OrWhere(c) renders ((a AND b) OR c); later Where calls
+// are ANDed with that whole group
+func (b *UpdateBuilder) OrWhere(condition expr.Expr) *UpdateBuilder {
+	b.whereExprs = orWhere(b.whereExprs, condition)
+	return b
+}
+
+// WithConnection binds the builder to a connection so it can be executed
+func (b *UpdateBuilder) WithConnection(conn ConnectionInterface) *UpdateBuilder {
+	b.conn = conn
+	return b
+}
+
+// targetTable returns the table the statement operates on
+func (b *UpdateBuilder) targetTable() table.TableInterface {
+	return b.table
+}
+
 // Returning specifies which columns to return
 func (b *UpdateBuilder) Returning(columns ...string) *UpdateBuilder {
 	b.returning = columns
 	return b
 }
 
+// AllowNoWhere permits executing the update without a WHERE condition,
+// changing every row in the table
+func (b *UpdateBuilder) AllowNoWhere() *UpdateBuilder {
+	b.allowAll = true
+	return b
+}
+
+// checkWhere refuses to run an update that would change every row, unless
+// AllowNoWhere was called. ValuesByKey rows are matched on their key.
+func (b *UpdateBuilder) checkWhere() error {
+	if len(b.whereExprs) > 0 || len(b.byKey) > 0 || b.allowAll {
+		return nil
+	}
+	return fmt.Errorf("UPDATE %s has no WHERE condition; call AllowNoWhere to update every row", b.table.Name())
+}
+
+// Exec executes the statement and returns the driver result. It fails
+// without a WHERE condition unless AllowNoWhere was called.
+func (b *UpdateBuilder) Exec(ctx context.Context) (sql.Result, error) {
+	if err := b.checkWhere(); err != nil {
+		return nil, err
+	}
+	if len(b.byKey) > 0 && !supportsValuesJoin(b.dialect) {
+		return b.execByKeyPerRow(ctx)
+	}
+	return execute(ctx, b.conn, b)
+}
+
+// One executes the statement and scans the single RETURNING row into dest
+func (b *UpdateBuilder) One(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("One requires a RETURNING clause")
+	}
+	if err := b.checkWhere(); err != nil {
+		return err
+	}
+	return queryOne(ctx, b.conn, b, dest)
+}
+
+// All executes the statement and scans every RETURNING row into dest
+func (b *UpdateBuilder) All(ctx context.Context, dest interface{}) error {
+	if len(b.returning) == 0 {
+		return fmt.Errorf("All requires a RETURNING clause")
+	}
+	if err := b.checkWhere(); err != nil {
+		return err
+	}
+	return queryAll(ctx, b.conn, b, dest)
+}
+
+// DebugSQL returns the SQL and args exactly as executed on dialect d
+// (placeholders formatted), for logging or sqlmock expectations.
+// It returns empty SQL if the builder is invalid; call ToSQL for the error.
+func (b *UpdateBuilder) DebugSQL(d dialect.Dialect) (string, []interface{}) {
+	c := *b
+	c.conn = withDialect(b.conn, d)
+	if d != nil {
+		c.dialect = d
+	}
+	return debugSQL(&c, d)
+}
+
 // ToSQL generates the SQL query and arguments
 func (b *UpdateBuilder) ToSQL() (string, []interface{}, error) {
-	if len(b.sets) == 0 {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	if len(b.byKey) > 0 {
+		return b.valuesJoinSQL()
+	}
+	if len(b.sets) == 0 && len(b.jsonSets) == 0 {
 		return "", nil, fmt.Errorf("no columns to update")
 	}
 
 	var sql strings.Builder
 	var args []interface{}
+	quote := identifierQuoter(b.conn)
 
 	// UPDATE table_name
 	tableName := b.table.Name()
@@ -60,15 +295,20 @@ func (b *UpdateBuilder) ToSQL() (string, []interface{}, error) {
 		return "", nil, fmt.Errorf("invalid table")
 	}
 	sql.WriteString("UPDATE ")
-	sql.WriteString(tableName)
+	sql.WriteString(quote(tableName))
 
 	// SET column1 = ?, column2 = ?
 	sql.WriteString(" SET ")
 	setParts := make([]string, 0, len(b.sets))
 	for col, val := range b.sets {
-		setParts = append(setParts, col+" = ?")
-		args = append(args, val)
+		setParts = append(setParts, quote(col)+" = ?")
+		args = append(args, bindColumn(b.table, col, val))
 	}
+	jsonParts, args, err := b.jsonSetSQL(quote, args)
+	if err != nil {
+		return "", nil, err
+	}
+	setParts = append(setParts, jsonParts...)
 	sql.WriteString(strings.Join(setParts, ", "))
 
 	// WHERE
@@ -78,7 +318,10 @@ func (b *UpdateBuilder) ToSQL() (string, []interface{}, error) {
 			if i > 0 {
 				sql.WriteString(" AND ")
 			}
-			whereSQL, whereArgs := whereExpr.ToSQL()
+			whereSQL, whereArgs, err := renderExpr(b.conn, whereExpr, b.dialect)
+			if err != nil {
+				return "", nil, err
+			}
 			sql.WriteString(whereSQL)
 			args = append(args, whereArgs...)
 		}
@@ -90,8 +333,208 @@ func (b *UpdateBuilder) ToSQL() (string, []interface{}, error) {
 			return "", nil, fmt.Errorf("driver does not support RETURNING clause")
 		}
 		sql.WriteString(" RETURNING ")
-		sql.WriteString(strings.Join(b.returning, ", "))
+		sql.WriteString(strings.Join(quoteAll(quote, b.returning), ", "))
+	}
+
+	return sql.String(), args, nil
+}
+
+// supportsValuesJoin reports whether UPDATE ... FROM (VALUES ...) AS v(cols)
+// is available
+func supportsValuesJoin(d dialect.Dialect) bool {
+	return d.Supports(dialect.FeatureUpdateFromValues)
+}
+
+// valuesJoinSQL renders the single-statement form of ValuesByKey
+func (b *UpdateBuilder) valuesJoinSQL() (string, []interface{}, error) {
+	if !supportsValuesJoin(b.dialect) {
+		return "", nil, fmt.Errorf("dialect %s does not support UPDATE ... FROM (VALUES ...); use Exec to update row by row", b.dialect.Name())
+	}
+	tableName := b.table.Name()
+	if tableName == "" {
+		return "", nil, fmt.Errorf("invalid table")
+	}
+	quote := identifierQuoter(b.conn)
+
+	// Key first, then every other column present in any row
+	colSet := make(map[string]interface{})
+	for _, row := range b.byKey {
+		for col := range row {
+			if col != b.keyCol {
+				colSet[col] = nil
+			}
+		}
+	}
+	columns := append([]string{b.keyCol}, orderedInsertColumns(colSet, b.table.Columns())...)
+	if len(columns) == 1 && len(b.sets) == 0 {
+		return "", nil, fmt.Errorf("no columns to update")
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+
+	// UPDATE table_name SET col = v.col, ...
+	sql.WriteString("UPDATE ")
+	sql.WriteString(quote(tableName))
+	sql.WriteString(" SET ")
+	setParts := make([]string, 0, len(columns)-1+len(b.sets))
+	for _, col := range columns[1:] {
+		setParts = append(setParts, quote(col)+" = v."+quote(col))
+	}
+	for col, val := range b.sets {
+		setParts = append(setParts, quote(col)+" = ?")
+		args = append(args, bindColumn(b.table, col, val))
+	}
+	sql.WriteString(strings.Join(setParts, ", "))
+
+	// FROM (VALUES (?, ?), ...) AS v(key, col, ...)
+	// The first row casts its placeholders so Postgres infers column types.
+	sql.WriteString(" FROM (VALUES ")
+	for i, row := range b.byKey {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString("(")
+		for j, col := range columns {
+			if j > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString("?")
+			if i == 0 {
+				if sqlType := b.columnSQLType(col); sqlType != "" {
+					sql.WriteString("::")
+					sql.WriteString(sqlType)
+				}
+			}
+			args = append(args, bindColumn(b.table, col, row[col]))
+		}
+		sql.WriteString(")")
+	}
+	sql.WriteString(") AS v(")
+	sql.WriteString(strings.Join(quoteAll(quote, columns), ", "))
+	sql.WriteString(")")
+
+	// WHERE t.key = v.key [AND ...]
+	sql.WriteString(" WHERE ")
+	sql.WriteString(quote(tableName) + "." + quote(b.keyCol) + " = v." + quote(b.keyCol))
+	for _, whereExpr := range b.whereExprs {
+		whereSQL, whereArgs, err := renderExpr(b.conn, whereExpr, b.dialect)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(" AND ")
+		sql.WriteString(whereSQL)
+		args = append(args, whereArgs...)
+	}
+
+	// RETURNING
+	if len(b.returning) > 0 {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(quoteAll(quote, b.returning), ", "))
 	}
 
 	return sql.String(), args, nil
 }
+
+// columnSQLType returns the dialect type of a table column, or ""
+func (b *UpdateBuilder) columnSQLType(name string) string {
+	for _, col := range b.table.Columns() {
+		if col.Name == name {
+			return col.SQLType(b.dialect)
+		}
+	}
+	return ""
+}
+
+// txConnection is implemented by connections that can run a transaction
+// (engine.Connection)
+type txConnection interface {
+	Begin() error
+	Commit() error
+	Rollback() error
+	InTransaction() bool
+}
+
+// contextBeginner is implemented by connections that can begin a transaction
+// with the caller's context (engine.Connection)
+type contextBeginner interface {
+	BeginContext(ctx context.Context) error
+}
+
+// execByKeyPerRow is the portable ValuesByKey fallback: one UPDATE per row,
+// wrapped in a transaction unless the connection is already in one
+func (b *UpdateBuilder) execByKeyPerRow(ctx context.Context) (sql.Result, error) {
+	if b.conn == nil {
+		return nil, fmt.Errorf("builder has no connection")
+	}
+	var total batchResult
+	err := inTransaction(ctx, b.conn, func() error {
+		for _, row := range b.byKey {
+			single := NewUpdate(b.dialect, b.table).WithConnection(b.conn)
+			single.whereExprs = append(single.whereExprs, &expr.BinaryExpr{Left: identifierQuoter(b.conn)(b.keyCol), Operator: "=", Right: row[b.keyCol]})
+			single.whereExprs = append(single.whereExprs, b.whereExprs...)
+			for col, val := range row {
+				if col != b.keyCol {
+					single.sets[col] = val
+				}
+			}
+			for col, val := range b.sets {
+				single.sets[col] = val
+			}
+			if len(single.sets) == 0 {
+				continue
+			}
+
+			res, err := single.Exec(ctx)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err == nil {
+				total.rowsAffected += n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// inTransaction runs fn inside a transaction when the connection supports
+// one and is not already in one; otherwise fn runs directly. The
+// transaction begins with ctx when the connection accepts one.
+func inTransaction(ctx context.Context, conn ConnectionInterface, fn func() error) (err error) {
+	tc, ok := conn.(txConnection)
+	if !ok || tc.InTransaction() {
+		return fn()
+	}
+	begin := tc.Begin
+	if cb, ok := conn.(contextBeginner); ok {
+		begin = func() error { return cb.BeginContext(ctx) }
+	}
+	if err := begin(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tc.Rollback()
+			return
+		}
+		err = tc.Commit()
+	}()
+	return fn()
+}
+
+// batchResult is the sql.Result of a statement run as several executions
+type batchResult struct {
+	rowsAffected int64
+}
+
+func (r batchResult) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("LastInsertId is not available for batched statements")
+}
+
+func (r batchResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
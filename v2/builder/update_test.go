@@ -0,0 +1,294 @@
+package builder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+func TestUpdateAll(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("UPDATE users SET age = $1 WHERE users.age < $2 RETURNING id, age").
+		WithArgs(18, 18).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).
+			AddRow(int64(1), 18).
+			AddRow(int64(2), 18))
+
+	var got []User
+	err := NewUpdate(conn.Dialect(), users).
+		WithConnection(conn).
+		Set("age", 18).
+		Where(expr.Lt(users.C.Age, 18)).
+		Returning("id", "age").
+		All(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].Age != 18 {
+		t.Fatalf("unexpected rows %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateAllRequiresReturning(t *testing.T) {
+	conn, _ := newTestConn(t, &mysql.MySQLDialect{})
+
+	var got []User
+	err := NewUpdate(conn.Dialect(), newUsersTable()).WithConnection(conn).Set("age", 18).All(context.Background(), &got)
+	if err == nil {
+		t.Fatal("expected error without RETURNING")
+	}
+
+	err = NewUpdate(conn.Dialect(), newUsersTable()).WithConnection(conn).Set("age", 18).Returning("id").All(context.Background(), &got)
+	if err == nil {
+		t.Fatal("expected error for dialect without RETURNING support")
+	}
+}
+
+func TestUpdateValuesByKeyPostgres(t *testing.T) {
+	users := newUsersTable()
+	rows := []User{
+		{ID: 1, Name: "john", Age: 30},
+		{ID: 2, Name: "jane", Age: 25},
+	}
+
+	sql, args, err := NewUpdate(&postgres.PostgresDialect{}, users).
+		ValuesByKey(rows, "id").
+		Where(expr.Gt(users.C.Age, 0)).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	want := "UPDATE users SET name = v.name, email = v.email, age = v.age" +
+		" FROM (VALUES (?::BIGINT, ?::TEXT, ?::TEXT, ?::BIGINT), (?, ?, ?, ?)) AS v(id, name, email, age)" +
+		" WHERE users.id = v.id AND users.age > ?"
+	if sql != want {
+		t.Fatalf("ToSQL() =\n%q\nwant\n%q", sql, want)
+	}
+	wantArgs := []interface{}{int64(1), "john", "", 30, int64(2), "jane", "", 25, 0}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestUpdateValuesByKeyMissingKey(t *testing.T) {
+	_, _, err := NewUpdate(&postgres.PostgresDialect{}, newUsersTable()).
+		ValuesByKey([]map[string]interface{}{{"name": "john"}}, "id").
+		ToSQL()
+	if err == nil {
+		t.Fatal("expected missing key error")
+	}
+}
+
+func TestUpdateValuesByKeyTagOptions(t *testing.T) {
+	type UserPatch struct {
+		ID    int64  `sql:"id"`
+		Name  string `sql:"name,omitempty"`
+		Email string `sql:"email,omitempty"`
+		Age   int    `sql:"age,readonly"`
+	}
+	users := newUsersTable()
+	base, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+	conn := &txTestConn{testConn: base}
+
+	mock.ExpectExec("UPDATE users SET name = ? WHERE id = ?").
+		WithArgs("john", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET email = ? WHERE id = ?").
+		WithArgs("jane@example.com", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rows := []UserPatch{
+		{ID: 1, Name: "john", Age: 99},
+		{ID: 2, Email: "jane@example.com"},
+	}
+	if _, err := NewUpdate(conn.Dialect(), users).WithConnection(conn).ValuesByKey(rows, "id").Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateRequiresWhere(t *testing.T) {
+	users := newUsersTable()
+	conn, mock := newTestConn(t, &postgres.PostgresDialect{})
+
+	_, err := NewUpdate(conn.Dialect(), users).WithConnection(conn).Set("age", 18).Exec(context.Background())
+	if err == nil || err.Error() != "UPDATE users has no WHERE condition; call AllowNoWhere to update every row" {
+		t.Fatalf("Exec() error = %v, want missing WHERE error", err)
+	}
+	var got []User
+	if err := NewUpdate(conn.Dialect(), users).WithConnection(conn).Set("age", 18).Returning("id").All(context.Background(), &got); err == nil {
+		t.Fatal("All() without WHERE: expected error")
+	}
+
+	mock.ExpectExec("UPDATE users SET age = $1").
+		WithArgs(18).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	if _, err := NewUpdate(conn.Dialect(), users).WithConnection(conn).Set("age", 18).AllowNoWhere().Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// txTestConn records transaction boundaries around a testConn
+type txTestConn struct {
+	*testConn
+	inTx      bool
+	committed bool
+	beginCtx  context.Context // context passed to BeginContext
+}
+
+func (c *txTestConn) Begin() error        { c.inTx = true; return nil }
+func (c *txTestConn) Commit() error       { c.inTx = false; c.committed = true; return nil }
+func (c *txTestConn) Rollback() error     { c.inTx = false; return nil }
+func (c *txTestConn) InTransaction() bool { return c.inTx }
+
+func (c *txTestConn) BeginContext(ctx context.Context) error {
+	c.beginCtx = ctx
+	return c.Begin()
+}
+
+// ctxKey tags a test context so tests can check which context was used
+type ctxKey struct{}
+
+func TestUpdateValuesByKeyPerRowFallback(t *testing.T) {
+	users := newUsersTable()
+	base, mock := newTestConn(t, &sqlite.SQLiteDialect{})
+	conn := &txTestConn{testConn: base}
+
+	mock.ExpectExec("UPDATE users SET name = ? WHERE id = ?").
+		WithArgs("john", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET name = ? WHERE id = ?").
+		WithArgs("jane", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "john"},
+		{"id": int64(2), "name": "jane"},
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	res, err := NewUpdate(conn.Dialect(), users).WithConnection(conn).ValuesByKey(rows, "id").Exec(ctx)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("RowsAffected() = %d, want 2", n)
+	}
+	if !conn.committed {
+		t.Fatal("expected per-row updates to run in a committed transaction")
+	}
+	if conn.beginCtx != ctx {
+		t.Fatal("expected the transaction to begin with the Exec context")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	if _, _, err := NewUpdate(conn.Dialect(), users).ValuesByKey(rows, "id").ToSQL(); err == nil {
+		t.Fatal("expected ToSQL() error for dialect without VALUES join")
+	}
+}
+
+type DocumentsColumns struct {
+	ID   *table.Column[int64]
+	Data *table.Column[[]byte]
+}
+
+func newDocumentsTable() *table.Table[DocumentsColumns] {
+	return table.NewTable("documents", DocumentsColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		Data: table.Col[[]byte]("data"),
+	})
+}
+
+func TestUpdateSetJSONPath(t *testing.T) {
+	docs := newDocumentsTable()
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		want    string
+	}{
+		{
+			name:    "postgres",
+			dialect: &postgres.PostgresDialect{},
+			want:    "UPDATE documents SET data = jsonb_set(jsonb_set(data, '{address,city}', ?), '{tags,0}', ?) WHERE documents.id = ?",
+		},
+		{
+			name:    "sqlite",
+			dialect: &sqlite.SQLiteDialect{},
+			want:    "UPDATE documents SET data = json_set(json_set(data, '$.address.city', json(?)), '$.tags[0]', json(?)) WHERE documents.id = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := NewUpdate(tt.dialect, docs).
+				SetJSONPath("data", "address.city", "Lisbon").
+				SetJSONPath("data", "tags.0", map[string]int{"n": 1}).
+				Where(expr.Eq(docs.C.ID, 7)).
+				ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+			if want := []interface{}{`"Lisbon"`, `{"n":1}`, 7}; !reflect.DeepEqual(args, want) {
+				t.Fatalf("args = %v, want %v", args, want)
+			}
+		})
+	}
+}
+
+func TestUpdateSetJSONPathErrors(t *testing.T) {
+	docs := newDocumentsTable()
+
+	tests := []struct {
+		name    string
+		builder *UpdateBuilder
+		want    string
+	}{
+		{
+			name:    "unsupported dialect",
+			builder: NewUpdate(&mysql.MySQLDialect{}, docs).SetJSONPath("data", "a", 1),
+			want:    "JSON path updates is not supported by the mysql dialect",
+		},
+		{
+			name:    "path written into sql",
+			builder: NewUpdate(&postgres.PostgresDialect{}, docs).SetJSONPath("data", "a}'; --", 1),
+			want:    `invalid JSON path "a}'; --": elements must be identifiers or array indexes`,
+		},
+		{
+			name:    "column also set",
+			builder: NewUpdate(&postgres.PostgresDialect{}, docs).Set("data", []byte("{}")).SetJSONPath("data", "a", 1),
+			want:    "column data is assigned by both Set and SetJSONPath",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.builder.ToSQL()
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("ToSQL() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
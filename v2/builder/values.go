@@ -4,19 +4,23 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/guadalsistema/go-compose-sql/v2/table"
 	"github.com/kisielk/sqlstruct"
 )
 
 // normalizeInsertValues converts input values (struct/map/slice) into row maps.
-// The optional column list filters out fields not present on the table.
-func normalizeInsertValues(data interface{}, cols []*table.ColumnRef) ([]map[string]interface{}, error) {
+// Fields and keys that are not columns of tbl are left out; untagged struct
+// fields are named by the table's field mapper.
+func normalizeInsertValues(data interface{}, tbl table.TableInterface) ([]map[string]interface{}, error) {
 	if data == nil {
 		return nil, fmt.Errorf("values cannot be nil")
 	}
 
 	// Build a fast lookup set for allowed columns.
+	cols := tbl.Columns()
+	mapper := fieldMapper(tbl)
 	colSet := make(map[string]struct{}, len(cols))
 	for _, col := range cols {
 		colSet[col.Name] = struct{}{}
@@ -38,7 +42,7 @@ func normalizeInsertValues(data interface{}, cols []*table.ColumnRef) ([]map[str
 		// Collect one map per element.
 		rows := make([]map[string]interface{}, 0, val.Len())
 		for i := 0; i < val.Len(); i++ {
-			row, err := extractRow(val.Index(i), colSet)
+			row, err := extractRow(val.Index(i), colSet, mapper)
 			if err != nil {
 				return nil, err
 			}
@@ -46,7 +50,7 @@ func normalizeInsertValues(data interface{}, cols []*table.ColumnRef) ([]map[str
 		}
 		return rows, nil
 	default:
-		row, err := extractRow(val, colSet)
+		row, err := extractRow(val, colSet, mapper)
 		if err != nil {
 			return nil, err
 		}
@@ -55,7 +59,7 @@ func normalizeInsertValues(data interface{}, cols []*table.ColumnRef) ([]map[str
 }
 
 // extractRow normalizes a single value into a row map using struct tags or map keys.
-func extractRow(val reflect.Value, colSet map[string]struct{}) (map[string]interface{}, error) {
+func extractRow(val reflect.Value, colSet map[string]struct{}, mapper func(string) string) (map[string]interface{}, error) {
 	for val.Kind() == reflect.Ptr {
 		if val.IsNil() {
 			return nil, fmt.Errorf("values cannot be nil")
@@ -69,7 +73,7 @@ func extractRow(val reflect.Value, colSet map[string]struct{}) (map[string]inter
 	case reflect.Struct:
 		// Build a column map from exported struct fields and tags.
 		row := make(map[string]interface{})
-		if err := mapFromStruct(val, colSet, row); err != nil {
+		if err := mapFromStruct(val, colSet, row, mapper); err != nil {
 			return nil, err
 		}
 		if len(row) == 0 {
@@ -108,7 +112,9 @@ func mapFromMap(val reflect.Value, colSet map[string]struct{}) (map[string]inter
 }
 
 // mapFromStruct walks exported fields (including embedded structs) and fills row.
-func mapFromStruct(val reflect.Value, colSet map[string]struct{}, row map[string]interface{}) error {
+// Fields tagged readonly are never written, and omitempty fields are left out
+// when they hold their zero value.
+func mapFromStruct(val reflect.Value, colSet map[string]struct{}, row map[string]interface{}, mapper func(string) string) error {
 	typ := val.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
@@ -118,18 +124,25 @@ func mapFromStruct(val reflect.Value, colSet map[string]struct{}, row map[string
 
 		// Inline embedded structs to match sqlstruct behavior.
 		if field.Anonymous && field.Type.Kind() == reflect.Struct {
-			if err := mapFromStruct(val.Field(i), colSet, row); err != nil {
+			if err := mapFromStruct(val.Field(i), colSet, row, mapper); err != nil {
 				return err
 			}
 			continue
 		}
 
-		tag := field.Tag.Get(sqlstruct.TagName)
-		if tag == "-" {
+		tag, opts := parseFieldTag(field.Tag.Get(sqlstruct.TagName))
+		if tag == "-" || opts.readonly {
+			continue
+		}
+		// Nested objects populated from prefixed columns are read-only.
+		if _, ok := nestedPrefix(tag); ok {
+			continue
+		}
+		if opts.omitempty && val.Field(i).IsZero() {
 			continue
 		}
 		if tag == "" {
-			tag = sqlstruct.ToSnakeCase(field.Name)
+			tag = mapFieldName(mapper, field.Name)
 		}
 
 		// Respect the table column filter if present.
@@ -144,6 +157,45 @@ func mapFromStruct(val reflect.Value, colSet map[string]struct{}, row map[string
 	return nil
 }
 
+// withoutZeroValues copies rows, dropping values that are nil or the zero
+// value of their type
+func withoutZeroValues(rows []map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		out[i] = make(map[string]interface{}, len(row))
+		for col, val := range row {
+			if v := reflect.ValueOf(val); v.IsValid() && !v.IsZero() {
+				out[i][col] = val
+			}
+		}
+	}
+	return out
+}
+
+// fieldTagOptions are the options following the column name in a struct tag,
+// e.g. `sql:"created_at,readonly"`
+type fieldTagOptions struct {
+	readonly  bool // selected and scanned, but never inserted or updated
+	omitempty bool // omitted from INSERT/UPDATE values when zero
+}
+
+// parseFieldTag splits a struct tag into its column name and options
+func parseFieldTag(tag string) (string, fieldTagOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	var opts fieldTagOptions
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		switch strings.TrimSpace(opt) {
+		case "readonly":
+			opts.readonly = true
+		case "omitempty":
+			opts.omitempty = true
+		}
+	}
+	return name, opts
+}
+
 // orderedInsertColumns chooses a stable column order for INSERT statements.
 // It prefers table column order when available, otherwise alphabetical order.
 func orderedInsertColumns(values map[string]interface{}, cols []*table.ColumnRef) []string {
@@ -176,3 +228,55 @@ func orderedInsertColumns(values map[string]interface{}, cols []*table.ColumnRef
 	}
 	return columns
 }
+
+// columnDefaults collects the declared default of every column that has one,
+// skipping auto-increment columns whose value the database generates.
+func columnDefaults(cols []*table.ColumnRef) map[string]interface{} {
+	defaults := make(map[string]interface{})
+	for _, col := range cols {
+		if col.Options.DefaultVal == nil || col.Options.AutoIncr {
+			continue
+		}
+		defaults[col.Name] = col.Options.DefaultVal
+	}
+	return defaults
+}
+
+// insertColumnSet merges the column keys of all rows, explicit sets and defaults.
+func insertColumnSet(rows []map[string]interface{}, sets map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for col := range defaults {
+		merged[col] = nil
+	}
+	for _, row := range rows {
+		for col := range row {
+			merged[col] = nil
+		}
+	}
+	for col := range sets {
+		merged[col] = nil
+	}
+	return merged
+}
+
+// fieldMapped is implemented by tables with a custom field name mapper
+// (table.Table.MapFields)
+type fieldMapped interface {
+	FieldMapper() func(string) string
+}
+
+// fieldMapper returns the field name mapper of tbl, or nil for the default
+func fieldMapper(tbl table.TableInterface) func(string) string {
+	if m, ok := tbl.(fieldMapped); ok {
+		return m.FieldMapper()
+	}
+	return nil
+}
+
+// mapFieldName names the column of an untagged struct field
+func mapFieldName(mapper func(string) string, name string) string {
+	if mapper == nil {
+		return sqlstruct.ToSnakeCase(name)
+	}
+	return mapper(name)
+}
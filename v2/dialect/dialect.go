@@ -1,8 +1,13 @@
 package dialect
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/errkind"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/feature"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
@@ -10,6 +15,9 @@ import (
 
 // Dialect represents a SQL dialect (placeholder/quoting behavior).
 type Dialect interface {
+	// Name returns the database system name (OpenTelemetry db.system value)
+	Name() string
+
 	// Placeholder returns the placeholder format for this driver
 	// e.g., "?" for SQLite/MySQL, "$" for Postgres
 	Placeholder(position int) string
@@ -17,12 +25,121 @@ type Dialect interface {
 	// SupportsReturning indicates if the driver supports RETURNING clauses
 	SupportsReturning() bool
 
+	// Supports reports whether the dialect implements an optional feature
+	Supports(f Feature) bool
+
 	// Quote quotes an identifier (table/column name)
 	Quote(identifier string) string
 
+	// SupportsPartialIndex indicates if CREATE INDEX accepts a WHERE predicate
+	SupportsPartialIndex() bool
+
+	// ColumnType maps a Go type to the SQL type used in CREATE TABLE
+	// Returns empty string if the type has no mapping
+	ColumnType(t reflect.Type) string
+
+	// FormatAutoIncrement returns the column constraint for auto-incrementing keys
+	FormatAutoIncrement() string
+
 	// FormatIgnoreConflict returns the SQL fragment for ignoring conflicts
 	// Returns empty string if not supported by the dialect
 	FormatIgnoreConflict() string
+
+	// FormatNoLimit returns the LIMIT clause meaning "all rows", required
+	// before an OFFSET without a limit
+	// Returns empty string if OFFSET may appear without LIMIT
+	FormatNoLimit() string
+
+	// FormatExplain returns the prefix that makes a query return its plan,
+	// executing it when analyze is set
+	// Returns empty string if the dialect cannot EXPLAIN ANALYZE
+	FormatExplain(analyze bool) string
+
+	// ClassifyError reports the kind of a driver error, e.g. a unique
+	// violation; errors it does not recognize are ErrorUnknown
+	ClassifyError(err error) ErrorKind
+}
+
+// ArrayConverter is implemented by dialects with native array columns
+// (FeatureArrays). Builders use it to bind Go slices and scan array results.
+type ArrayConverter interface {
+	// Array wraps a slice, or pointer to one, for binding and scanning
+	Array(v interface{}) interface {
+		driver.Valuer
+		sql.Scanner
+	}
+}
+
+// JSONPathSetter is implemented by dialects with JSON path updates
+// (FeatureJSONSet)
+type JSONPathSetter interface {
+	// JSONSet returns an expression that is doc with the value at path
+	// replaced by the JSON text bound to a single ? placeholder. path holds
+	// object keys and array indexes, already validated as identifiers or
+	// digits.
+	JSONSet(doc string, path []string) string
+}
+
+// SchemaIntrospector is implemented by dialects that can describe a live
+// table, for detecting drift from a table definition
+type SchemaIntrospector interface {
+	// ColumnsQuery returns the query (with the dialect's placeholders)
+	// listing the table's columns as (name, type) rows
+	ColumnsQuery(table string) (string, []interface{})
+
+	// SameColumnType reports whether a type reported by ColumnsQuery matches
+	// a type returned by ColumnType
+	SameColumnType(declared, live string) bool
+}
+
+// Feature is an optional SQL capability; see the feature package
+type Feature = feature.Feature
+
+// Capabilities reported by Dialect.Supports
+const (
+	FeatureReturning          = feature.Returning
+	FeatureFullOuterJoin      = feature.FullOuterJoin
+	FeatureOnConflict         = feature.OnConflict
+	FeatureILike              = feature.ILike
+	FeatureWindowFunctions    = feature.WindowFunctions
+	FeatureSkipLocked         = feature.SkipLocked
+	FeatureArrays             = feature.Arrays
+	FeatureUpdateFromValues   = feature.UpdateFromValues
+	FeatureRollup             = feature.Rollup
+	FeatureGroupingSets       = feature.GroupingSets
+	FeatureFetchFirst         = feature.FetchFirst
+	FeatureFetchWithTies      = feature.FetchWithTies
+	FeatureNoKeyUpdate        = feature.NoKeyUpdate
+	FeatureLockTimeout        = feature.LockTimeout
+	FeatureDefaultKeyword     = feature.DefaultKeyword
+	FeatureConflictConstraint = feature.ConflictConstraint
+	FeatureRowValues          = feature.RowValues
+	FeatureNullsOrdering      = feature.NullsOrdering
+	FeatureRightJoin          = feature.RightJoin
+	FeatureJSONSet            = feature.JSONSet
+)
+
+// ErrorKind is a driver-independent class of database error; see the
+// errkind package
+type ErrorKind = errkind.Kind
+
+// Error kinds reported by Dialect.ClassifyError
+const (
+	ErrorUnknown              = errkind.Unknown
+	ErrorUniqueViolation      = errkind.UniqueViolation
+	ErrorForeignKeyViolation  = errkind.ForeignKeyViolation
+	ErrorNotNullViolation     = errkind.NotNullViolation
+	ErrorSerializationFailure = errkind.SerializationFailure
+	ErrorDeadlock             = errkind.Deadlock
+)
+
+// Require returns an error naming the dialect and feature when d does not
+// support f, for builders to surface instead of emitting invalid SQL
+func Require(d Dialect, f Feature) error {
+	if d.Supports(f) {
+		return nil
+	}
+	return fmt.Errorf("%s is not supported by the %s dialect", f, d.Name())
 }
 
 // DialectByName returns a dialect by name
@@ -0,0 +1,136 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+)
+
+func TestSupportsMatrix(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		feature Feature
+		want    bool
+	}{
+		{&postgres.PostgresDialect{}, FeatureILike, true},
+		{&sqlite.SQLiteDialect{}, FeatureILike, false},
+		{&mysql.MySQLDialect{}, FeatureILike, false},
+		{&sqlite.SQLiteDialect{}, FeatureReturning, true},
+		{&mysql.MySQLDialect{}, FeatureReturning, false},
+		{&mysql.MySQLDialect{}, FeatureSkipLocked, true},
+		{&sqlite.SQLiteDialect{}, FeatureSkipLocked, false},
+		{&postgres.PostgresDialect{}, FeatureArrays, true},
+		{&sqlite.SQLiteDialect{}, FeatureArrays, false},
+		{&postgres.PostgresDialect{}, FeatureFetchWithTies, true},
+		{&mysql.MySQLDialect{}, FeatureFetchFirst, false},
+		{&postgres.PostgresDialect{}, FeatureNoKeyUpdate, true},
+		{&mysql.MySQLDialect{}, FeatureNoKeyUpdate, false},
+		{&sqlite.SQLiteDialect{}, FeatureLockTimeout, false},
+		{&mysql.MySQLDialect{}, FeatureDefaultKeyword, true},
+		{&sqlite.SQLiteDialect{}, FeatureDefaultKeyword, false},
+		{&postgres.PostgresDialect{}, FeatureConflictConstraint, true},
+		{&sqlite.SQLiteDialect{}, FeatureConflictConstraint, false},
+		{&sqlite.SQLiteDialect{}, FeatureRowValues, true},
+		{&mysql.MySQLDialect{}, FeatureRowValues, false},
+		{&sqlite.SQLiteDialect{}, FeatureNullsOrdering, true},
+		{&mysql.MySQLDialect{}, FeatureNullsOrdering, false},
+		{&mysql.MySQLDialect{}, FeatureRightJoin, true},
+		{&sqlite.SQLiteDialect{}, FeatureRightJoin, true},
+		{&sqlite.SQLiteDialect{Version: "3.39.0"}, FeatureRightJoin, true},
+		{&sqlite.SQLiteDialect{Version: "3.38.5"}, FeatureRightJoin, false},
+		{&sqlite.SQLiteDialect{Version: "3.38.5"}, FeatureFullOuterJoin, false},
+		{&sqlite.SQLiteDialect{Version: "3.38.5"}, FeatureReturning, true},
+		{&sqlite.SQLiteDialect{Version: "3.31"}, FeatureReturning, false},
+		{&sqlite.SQLiteDialect{Version: "3.31"}, FeatureNullsOrdering, true},
+		{&postgres.PostgresDialect{}, FeatureJSONSet, true},
+		{&sqlite.SQLiteDialect{Version: "3.38.0"}, FeatureJSONSet, true},
+		{&sqlite.SQLiteDialect{Version: "3.37.2"}, FeatureJSONSet, false},
+		{&mysql.MySQLDialect{}, FeatureJSONSet, false},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.Supports(tt.feature); got != tt.want {
+			t.Errorf("%s Supports(%s) = %v, want %v", tt.dialect.Name(), tt.feature, got, tt.want)
+		}
+		if got := tt.dialect.SupportsReturning(); got != tt.dialect.Supports(FeatureReturning) {
+			t.Errorf("%s SupportsReturning() = %v disagrees with Supports", tt.dialect.Name(), got)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	if err := Require(&postgres.PostgresDialect{}, FeatureArrays); err != nil {
+		t.Fatalf("Require() error = %v", err)
+	}
+	err := Require(&mysql.MySQLDialect{}, FeatureFullOuterJoin)
+	if err == nil || err.Error() != "FULL OUTER JOIN is not supported by the mysql dialect" {
+		t.Fatalf("Require() error = %v", err)
+	}
+}
+
+// pgError mimics *pq.Error and *pgconn.PgError
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pq: constraint violated" }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		err     error
+		want    ErrorKind
+	}{
+		{"postgres unique", &postgres.PostgresDialect{}, &pgError{"23505"}, ErrorUniqueViolation},
+		{"postgres wrapped fk", &postgres.PostgresDialect{}, fmt.Errorf("insert order: %w", &pgError{"23503"}), ErrorForeignKeyViolation},
+		{"postgres message", &postgres.PostgresDialect{}, errors.New(`ERROR: null value in column "name" violates not-null constraint (SQLSTATE 23502)`), ErrorNotNullViolation},
+		{"postgres serialization", &postgres.PostgresDialect{}, &pgError{"40001"}, ErrorSerializationFailure},
+		{"postgres deadlock", &postgres.PostgresDialect{}, &pgError{"40P01"}, ErrorDeadlock},
+		{"postgres other", &postgres.PostgresDialect{}, &pgError{"42P01"}, ErrorUnknown},
+		{"mysql unique", &mysql.MySQLDialect{}, errors.New("Error 1062 (23000): Duplicate entry 'a@b.c' for key 'email'"), ErrorUniqueViolation},
+		{"mysql wrapped fk", &mysql.MySQLDialect{}, fmt.Errorf("insert: %w", errors.New("Error 1452: Cannot add or update a child row")), ErrorForeignKeyViolation},
+		{"mysql not null", &mysql.MySQLDialect{}, errors.New("Error 1048 (23000): Column 'name' cannot be null"), ErrorNotNullViolation},
+		{"mysql deadlock", &mysql.MySQLDialect{}, errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), ErrorDeadlock},
+		{"sqlite unique", &sqlite.SQLiteDialect{}, errors.New("UNIQUE constraint failed: users.email"), ErrorUniqueViolation},
+		{"sqlite fk", &sqlite.SQLiteDialect{}, errors.New("FOREIGN KEY constraint failed"), ErrorForeignKeyViolation},
+		{"sqlite not null", &sqlite.SQLiteDialect{}, errors.New("NOT NULL constraint failed: users.name"), ErrorNotNullViolation},
+		{"sqlite other", &sqlite.SQLiteDialect{}, errors.New("database is locked"), ErrorUnknown},
+		{"nil", &sqlite.SQLiteDialect{}, nil, ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.ClassifyError(tt.err); got != tt.want {
+			t.Errorf("%s: ClassifyError() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSameColumnType(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		declared string
+		live     string
+		want     bool
+	}{
+		{&postgres.PostgresDialect{}, "TIMESTAMPTZ", "timestamp with time zone", true},
+		{&postgres.PostgresDialect{}, "BIGINT[]", "_int8", true},
+		{&postgres.PostgresDialect{}, "TEXT", "character varying", false},
+		{&mysql.MySQLDialect{}, "BIGINT", "bigint(20)", true},
+		{&mysql.MySQLDialect{}, "INT UNSIGNED", "int(10) unsigned", true},
+		{&mysql.MySQLDialect{}, "BOOLEAN", "tinyint(1)", true},
+		{&mysql.MySQLDialect{}, "VARCHAR(255)", "varchar(100)", false},
+		{&sqlite.SQLiteDialect{}, "INTEGER", "integer", true},
+		{&sqlite.SQLiteDialect{}, "TEXT", "VARCHAR(255)", false},
+	}
+	for _, tt := range tests {
+		introspector, ok := tt.dialect.(SchemaIntrospector)
+		if !ok {
+			t.Fatalf("%s does not implement SchemaIntrospector", tt.dialect.Name())
+		}
+		if got := introspector.SameColumnType(tt.declared, tt.live); got != tt.want {
+			t.Errorf("%s SameColumnType(%q, %q) = %v, want %v", tt.dialect.Name(), tt.declared, tt.live, got, tt.want)
+		}
+	}
+}
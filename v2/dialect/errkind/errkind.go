@@ -0,0 +1,43 @@
+// Package errkind enumerates driver-independent classes of database errors.
+// It is a leaf package so dialect implementations can classify their
+// driver's errors without importing the dialect package.
+package errkind
+
+// Kind is a class of database error reported by Dialect.ClassifyError
+type Kind int
+
+const (
+	// Unknown is any error the dialect does not recognize
+	Unknown Kind = iota
+	// UniqueViolation is a duplicate value in a unique index or primary key
+	UniqueViolation
+	// ForeignKeyViolation is a missing referenced row, or a delete/update of
+	// a row that is still referenced
+	ForeignKeyViolation
+	// NotNullViolation is a NULL written to a NOT NULL column
+	NotNullViolation
+	// SerializationFailure is a transaction aborted because it could not be
+	// serialized with concurrent ones; retrying it may succeed
+	SerializationFailure
+	// Deadlock is a transaction chosen as a deadlock victim; retrying it may
+	// succeed
+	Deadlock
+)
+
+// String returns a short description of the kind
+func (k Kind) String() string {
+	switch k {
+	case UniqueViolation:
+		return "unique violation"
+	case ForeignKeyViolation:
+		return "foreign key violation"
+	case NotNullViolation:
+		return "not-null violation"
+	case SerializationFailure:
+		return "serialization failure"
+	case Deadlock:
+		return "deadlock"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,82 @@
+// Package feature enumerates optional SQL capabilities a dialect may support.
+// It is a leaf package so dialect implementations can reference the values
+// without importing the dialect package.
+package feature
+
+// Feature is an optional SQL capability reported by Dialect.Supports
+type Feature int
+
+const (
+	// Returning is INSERT/UPDATE/DELETE ... RETURNING
+	Returning Feature = iota + 1
+	// FullOuterJoin is FULL OUTER JOIN
+	FullOuterJoin
+	// OnConflict is INSERT ... ON CONFLICT (upserts and conflict targets)
+	OnConflict
+	// ILike is the case-insensitive ILIKE operator
+	ILike
+	// WindowFunctions is OVER (PARTITION BY ... ORDER BY ...)
+	WindowFunctions
+	// SkipLocked is FOR UPDATE SKIP LOCKED
+	SkipLocked
+	// Arrays is native array column types and operators
+	Arrays
+	// UpdateFromValues is UPDATE ... FROM (VALUES ...) AS v(cols)
+	UpdateFromValues
+	// Rollup is GROUP BY ROLLUP (or MySQL's WITH ROLLUP)
+	Rollup
+	// GroupingSets is GROUP BY GROUPING SETS (...)
+	GroupingSets
+	// FetchFirst is OFFSET n ROWS FETCH FIRST n ROWS ONLY
+	FetchFirst
+	// FetchWithTies is FETCH FIRST n ROWS WITH TIES
+	FetchWithTies
+	// NoKeyUpdate is SELECT ... FOR NO KEY UPDATE
+	NoKeyUpdate
+	// LockTimeout is SET LOCAL lock_timeout
+	LockTimeout
+	// DefaultKeyword is the DEFAULT keyword in an INSERT VALUES list
+	DefaultKeyword
+	// ConflictConstraint is INSERT ... ON CONFLICT ON CONSTRAINT name
+	ConflictConstraint
+	// RowValues is a row-value IN list, (a, b) IN ((?, ?), ...)
+	RowValues
+	// NullsOrdering is ORDER BY ... NULLS FIRST / NULLS LAST
+	NullsOrdering
+	// RightJoin is RIGHT JOIN
+	RightJoin
+	// JSONSet is setting a value at a path inside a JSON column
+	// (jsonb_set, json_set)
+	JSONSet
+)
+
+var names = map[Feature]string{
+	Returning:          "RETURNING",
+	FullOuterJoin:      "FULL OUTER JOIN",
+	OnConflict:         "ON CONFLICT",
+	ILike:              "ILIKE",
+	WindowFunctions:    "window functions (OVER)",
+	SkipLocked:         "SKIP LOCKED",
+	Arrays:             "ARRAY",
+	UpdateFromValues:   "UPDATE ... FROM (VALUES ...)",
+	Rollup:             "ROLLUP",
+	GroupingSets:       "GROUPING SETS",
+	FetchFirst:         "FETCH FIRST",
+	FetchWithTies:      "FETCH FIRST ... WITH TIES",
+	NoKeyUpdate:        "FOR NO KEY UPDATE",
+	LockTimeout:        "SET LOCAL lock_timeout",
+	DefaultKeyword:     "DEFAULT in VALUES",
+	ConflictConstraint: "ON CONFLICT ON CONSTRAINT",
+	RowValues:          "row values",
+	NullsOrdering:      "NULLS FIRST/LAST",
+	RightJoin:          "RIGHT JOIN",
+	JSONSet:            "JSON path updates",
+}
+
+// String returns the SQL name of the feature, for error messages
+func (f Feature) String() string {
+	if name, ok := names[f]; ok {
+		return name
+	}
+	return "unknown feature"
+}
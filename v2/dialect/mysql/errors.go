@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/errkind"
+)
+
+// errorNumberRe matches the server error number go-sql-driver/mysql puts at
+// the start of *mysql.MySQLError messages: "Error 1062 (23000): ..."
+var errorNumberRe = regexp.MustCompile(`^Error (\d+)`)
+
+// ClassifyError maps a driver error to its kind by MySQL server error number.
+// Wrapped errors are unwrapped until one carries a number.
+func (d *MySQLDialect) ClassifyError(err error) errkind.Kind {
+	for ; err != nil; err = errors.Unwrap(err) {
+		m := errorNumberRe.FindStringSubmatch(err.Error())
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "1062": // ER_DUP_ENTRY
+			return errkind.UniqueViolation
+		case "1451", "1452": // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+			return errkind.ForeignKeyViolation
+		case "1048": // ER_BAD_NULL_ERROR
+			return errkind.NotNullViolation
+		case "1213": // ER_LOCK_DEADLOCK
+			return errkind.Deadlock
+		default:
+			return errkind.Unknown
+		}
+	}
+	return errkind.Unknown
+}
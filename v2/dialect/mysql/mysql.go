@@ -1,14 +1,29 @@
 package mysql
 
+import (
+	"reflect"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/feature"
+)
+
 // MySQLDialect implements the Dialect interface for MySQL.
 type MySQLDialect struct{}
 
+func (d *MySQLDialect) Name() string {
+	return "mysql"
+}
+
 func (d *MySQLDialect) Placeholder(position int) string {
 	return "?"
 }
 
 func (d *MySQLDialect) SupportsReturning() bool {
-	return false // MySQL doesn't support RETURNING
+	return d.Supports(feature.Returning)
+}
+
+func (d *MySQLDialect) SupportsPartialIndex() bool {
+	return false // MySQL has no partial indexes
 }
 
 func (d *MySQLDialect) Quote(identifier string) string {
@@ -18,3 +33,68 @@ func (d *MySQLDialect) Quote(identifier string) string {
 func (d *MySQLDialect) FormatIgnoreConflict() string {
 	return "IGNORE"
 }
+
+func (d *MySQLDialect) FormatNoLimit() string {
+	return "LIMIT 18446744073709551615" // OFFSET requires a LIMIT; max BIGINT UNSIGNED means all rows
+}
+
+func (d *MySQLDialect) FormatExplain(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE" // 8.0.18+
+	}
+	return "EXPLAIN"
+}
+
+func (d *MySQLDialect) ColumnType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "DATETIME"
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return "BLOB"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8:
+		return "TINYINT"
+	case reflect.Int16:
+		return "SMALLINT"
+	case reflect.Int32:
+		return "INT"
+	case reflect.Int, reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint8:
+		return "TINYINT UNSIGNED"
+	case reflect.Uint16:
+		return "SMALLINT UNSIGNED"
+	case reflect.Uint32:
+		return "INT UNSIGNED"
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED"
+	case reflect.Float32:
+		return "FLOAT"
+	case reflect.Float64:
+		return "DOUBLE"
+	case reflect.String:
+		return "VARCHAR(255)" // TEXT cannot be used in keys without a prefix length
+	default:
+		return ""
+	}
+}
+
+func (d *MySQLDialect) FormatAutoIncrement() string {
+	return "AUTO_INCREMENT"
+}
+
+func (d *MySQLDialect) Supports(f feature.Feature) bool {
+	switch f {
+	case feature.WindowFunctions, feature.SkipLocked: // 8.0+
+		return true
+	case feature.Rollup: // GROUP BY ... WITH ROLLUP
+		return true
+	case feature.DefaultKeyword, feature.RightJoin:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ColumnsQuery lists the columns of table in the current database as
+// (name, type) rows
+func (d *MySQLDialect) ColumnsQuery(table string) (string, []interface{}) {
+	return "SELECT column_name, column_type " +
+		"FROM information_schema.columns " +
+		"WHERE table_schema = DATABASE() AND table_name = ? " +
+		"ORDER BY ordinal_position", []interface{}{table}
+}
+
+// displayWidthRe matches the integer display width MySQL before 8.0.19
+// reports, e.g. bigint(20)
+var displayWidthRe = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+
+// SameColumnType reports whether the live type matches the declared one
+func (d *MySQLDialect) SameColumnType(declared, live string) bool {
+	return normalizeType(declared) == normalizeType(live)
+}
+
+// normalizeType lowercases a type name and drops integer display widths;
+// BOOLEAN is stored as tinyint(1)
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	switch t {
+	case "boolean", "bool":
+		return "tinyint"
+	}
+	t = strings.Replace(t, "integer", "int", 1)
+	return displayWidthRe.ReplaceAllString(t, "$1")
+}
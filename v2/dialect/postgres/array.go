@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Array wraps a slice of bool, integer, float or string values, or a pointer
+// to one, so it binds as a Postgres array literal ({1,2,3}) and scans back
+// from one. Scanning requires a pointer.
+func (d *PostgresDialect) Array(v interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return &ArrayValue{V: v}
+}
+
+// ArrayValue encodes and decodes one-dimensional Postgres arrays
+type ArrayValue struct {
+	V interface{}
+}
+
+// Value renders the slice as an array literal; a nil slice is NULL
+func (a *ArrayValue) Value() (driver.Value, error) {
+	rv := reflect.ValueOf(a.V)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("postgres array: unsupported type %T", a.V)
+	}
+	if rv.IsNil() {
+		return nil, nil
+	}
+
+	elems := make([]string, rv.Len())
+	for i := range elems {
+		elem, err := encodeArrayElem(rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = elem
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// Scan decodes an array literal into the slice pointed to by V
+func (a *ArrayValue) Scan(src interface{}) error {
+	rv := reflect.ValueOf(a.V)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("postgres array: scan destination must be a pointer to a slice, got %T", a.V)
+	}
+	slice := rv.Elem()
+
+	var text string
+	switch s := src.(type) {
+	case nil:
+		slice.Set(reflect.Zero(slice.Type()))
+		return nil
+	case []byte:
+		text = string(s)
+	case string:
+		text = s
+	default:
+		return fmt.Errorf("postgres array: cannot scan %T", src)
+	}
+
+	elems, err := parseArray(text)
+	if err != nil {
+		return err
+	}
+	out := reflect.MakeSlice(slice.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			return fmt.Errorf("postgres array: NULL element cannot be scanned into %s", slice.Type())
+		}
+		if err := decodeArrayElem(*elem, out.Index(i)); err != nil {
+			return err
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+func encodeArrayElem(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "t", nil
+		}
+		return "f", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.String:
+		s := strings.ReplaceAll(v.String(), `\`, `\\`)
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`, nil
+	default:
+		return "", fmt.Errorf("postgres array: unsupported element type %s", v.Type())
+	}
+}
+
+func decodeArrayElem(s string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(s == "t" || s == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("postgres array: %w", err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("postgres array: %w", err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("postgres array: %w", err)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return fmt.Errorf("postgres array: unsupported element type %s", v.Type())
+	}
+	return nil
+}
+
+// parseArray splits a one-dimensional array literal into its elements;
+// unquoted NULL elements are returned as nil
+func parseArray(text string) ([]*string, error) {
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return nil, fmt.Errorf("postgres array: invalid literal %q", text)
+	}
+	body := text[1 : len(text)-1]
+	if body == "" {
+		return []*string{}, nil
+	}
+
+	var elems []*string
+	for i := 0; i <= len(body); {
+		if i < len(body) && body[i] == '{' {
+			return nil, fmt.Errorf("postgres array: multi-dimensional arrays are not supported")
+		}
+		var elem strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("postgres array: unterminated element in %q", text)
+			}
+			i++
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				elem.WriteByte(body[i])
+			}
+		}
+		if i < len(body) && body[i] != ',' {
+			return nil, fmt.Errorf("postgres array: invalid literal %q", text)
+		}
+		i++
+
+		s := elem.String()
+		if !quoted && strings.EqualFold(s, "NULL") {
+			elems = append(elems, nil)
+			continue
+		}
+		elems = append(elems, &s)
+	}
+	return elems, nil
+}
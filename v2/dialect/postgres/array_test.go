@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArrayRoundTrip(t *testing.T) {
+	d := &PostgresDialect{}
+
+	tests := []struct {
+		in   interface{}
+		want string
+		out  interface{}
+	}{
+		{[]int64{1, 2, 3}, "{1,2,3}", &[]int64{}},
+		{[]string{"a", `b"c`, `d\e`, "x,y"}, `{"a","b\"c","d\\e","x,y"}`, &[]string{}},
+		{[]bool{true, false}, "{t,f}", &[]bool{}},
+		{[]float64{1.5}, "{1.5}", &[]float64{}},
+		{[]int64{}, "{}", &[]int64{}},
+	}
+	for _, tt := range tests {
+		v, err := d.Array(tt.in).Value()
+		if err != nil {
+			t.Fatalf("Value(%v) error = %v", tt.in, err)
+		}
+		if v != tt.want {
+			t.Fatalf("Value(%v) = %v, want %s", tt.in, v, tt.want)
+		}
+		if err := d.Array(tt.out).Scan([]byte(tt.want)); err != nil {
+			t.Fatalf("Scan(%s) error = %v", tt.want, err)
+		}
+		if got := reflect.ValueOf(tt.out).Elem().Interface(); !reflect.DeepEqual(got, tt.in) {
+			t.Fatalf("Scan(%s) = %#v, want %#v", tt.want, got, tt.in)
+		}
+	}
+}
+
+func TestArrayScanUnquotedAndNull(t *testing.T) {
+	var words []string
+	if err := (&ArrayValue{V: &words}).Scan("{foo,\"bar baz\"}"); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !reflect.DeepEqual(words, []string{"foo", "bar baz"}) {
+		t.Fatalf("Scan() = %#v", words)
+	}
+
+	if err := (&ArrayValue{V: &words}).Scan(nil); err != nil || words != nil {
+		t.Fatalf("Scan(nil) = %#v, %v; want nil slice", words, err)
+	}
+
+	var ids []int64
+	if err := (&ArrayValue{V: &ids}).Scan("{1,NULL}"); err == nil {
+		t.Fatalf("expected error scanning NULL element")
+	}
+	if err := (&ArrayValue{V: &ids}).Scan("{{1,2},{3,4}}"); err == nil {
+		t.Fatalf("expected error scanning multi-dimensional array")
+	}
+}
+
+func TestArrayColumnType(t *testing.T) {
+	d := &PostgresDialect{}
+	if got := d.ColumnType(reflect.TypeOf([]int64{})); got != "BIGINT[]" {
+		t.Fatalf("ColumnType([]int64) = %q", got)
+	}
+	if got := d.ColumnType(reflect.TypeOf([]string{})); got != "TEXT[]" {
+		t.Fatalf("ColumnType([]string) = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/errkind"
+)
+
+// sqlStateRe matches the SQLSTATE pgx appends to error messages
+var sqlStateRe = regexp.MustCompile(`SQLSTATE ([0-9A-Z]{5})`)
+
+// ClassifyError maps a driver error to its kind by SQLSTATE: class 23
+// (integrity constraint violation) and class 40 (transaction rollback). lib/pq's *pq.Error and pgx's
+// *pgconn.PgError both expose SQLState(); other errors are matched on the
+// "SQLSTATE xxxxx" suffix of their message.
+func (d *PostgresDialect) ClassifyError(err error) errkind.Kind {
+	if err == nil {
+		return errkind.Unknown
+	}
+	var code string
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		code = state.SQLState()
+	} else if m := sqlStateRe.FindStringSubmatch(err.Error()); m != nil {
+		code = m[1]
+	}
+	switch code {
+	case "23505":
+		return errkind.UniqueViolation
+	case "23503":
+		return errkind.ForeignKeyViolation
+	case "23502":
+		return errkind.NotNullViolation
+	case "40001":
+		return errkind.SerializationFailure
+	case "40P01":
+		return errkind.Deadlock
+	default:
+		return errkind.Unknown
+	}
+}
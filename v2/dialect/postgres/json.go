@@ -0,0 +1,8 @@
+package postgres
+
+import "strings"
+
+// JSONSet renders jsonb_set(doc, '{a,b}', ?); the column must be jsonb
+func (d *PostgresDialect) JSONSet(doc string, path []string) string {
+	return "jsonb_set(" + doc + ", '{" + strings.Join(path, ",") + "}', ?)"
+}
@@ -1,15 +1,29 @@
 package postgres
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/feature"
+)
 
 // PostgresDialect implements the Dialect interface for PostgreSQL.
 type PostgresDialect struct{}
 
+func (d *PostgresDialect) Name() string {
+	return "postgresql"
+}
+
 func (d *PostgresDialect) Placeholder(position int) string {
 	return fmt.Sprintf("$%d", position)
 }
 
 func (d *PostgresDialect) SupportsReturning() bool {
+	return d.Supports(feature.Returning)
+}
+
+func (d *PostgresDialect) SupportsPartialIndex() bool {
 	return true
 }
 
@@ -20,3 +34,65 @@ func (d *PostgresDialect) Quote(identifier string) string {
 func (d *PostgresDialect) FormatIgnoreConflict() string {
 	return "ON CONFLICT DO NOTHING"
 }
+
+func (d *PostgresDialect) FormatNoLimit() string {
+	return ""
+}
+
+func (d *PostgresDialect) FormatExplain(analyze bool) string {
+	if analyze {
+		return "EXPLAIN (ANALYZE, FORMAT TEXT)"
+	}
+	return "EXPLAIN (FORMAT TEXT)"
+}
+
+func (d *PostgresDialect) ColumnType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "TIMESTAMPTZ"
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return "BYTEA"
+	}
+	if t.Kind() == reflect.Slice {
+		if elem := d.ColumnType(t.Elem()); elem != "" && t.Elem().Kind() != reflect.Slice {
+			return elem + "[]"
+		}
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "DOUBLE PRECISION"
+	case reflect.String:
+		return "TEXT"
+	default:
+		return ""
+	}
+}
+
+func (d *PostgresDialect) FormatAutoIncrement() string {
+	return "GENERATED BY DEFAULT AS IDENTITY"
+}
+
+func (d *PostgresDialect) Supports(f feature.Feature) bool {
+	switch f {
+	case feature.Returning, feature.FullOuterJoin, feature.OnConflict, feature.ILike,
+		feature.WindowFunctions, feature.SkipLocked, feature.Arrays, feature.UpdateFromValues,
+		feature.Rollup, feature.GroupingSets,
+		feature.FetchFirst, feature.FetchWithTies, // WITH TIES: 13+
+		feature.NoKeyUpdate, feature.LockTimeout, feature.DefaultKeyword, feature.ConflictConstraint, feature.RowValues,
+		feature.NullsOrdering, feature.RightJoin, feature.JSONSet:
+		return true
+	default:
+		return false
+	}
+}
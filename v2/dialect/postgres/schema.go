@@ -0,0 +1,47 @@
+package postgres
+
+import "strings"
+
+// ColumnsQuery lists the columns of table in the current schema as
+// (name, type) rows. Array columns report their element type name, e.g.
+// _int8, so they can be told apart.
+func (d *PostgresDialect) ColumnsQuery(table string) (string, []interface{}) {
+	return "SELECT column_name, CASE WHEN data_type = 'ARRAY' THEN udt_name ELSE data_type END " +
+		"FROM information_schema.columns " +
+		"WHERE table_schema = current_schema() AND table_name = $1 " +
+		"ORDER BY ordinal_position", []interface{}{table}
+}
+
+// typeAliases maps information_schema and internal type names to the names
+// ColumnType uses
+var typeAliases = map[string]string{
+	"timestamp with time zone": "timestamptz",
+	"int8":                     "bigint",
+	"int4":                     "integer",
+	"int":                      "integer",
+	"int2":                     "smallint",
+	"float8":                   "double precision",
+	"float4":                   "real",
+	"bool":                     "boolean",
+}
+
+// SameColumnType reports whether the live type matches the declared one
+func (d *PostgresDialect) SameColumnType(declared, live string) bool {
+	return normalizeType(declared) == normalizeType(live)
+}
+
+// normalizeType lowercases a type name and resolves aliases; array types
+// reported as _elem become elem[]
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	suffix := ""
+	if strings.HasPrefix(t, "_") {
+		t, suffix = t[1:], "[]"
+	} else if strings.HasSuffix(t, "[]") {
+		t, suffix = strings.TrimSuffix(t, "[]"), "[]"
+	}
+	if alias, ok := typeAliases[t]; ok {
+		t = alias
+	}
+	return t + suffix
+}
@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/errkind"
+)
+
+// ClassifyError maps a driver error to its kind by SQLite's constraint
+// message, which mattn/go-sqlite3 and modernc.org/sqlite both report, e.g.
+// "UNIQUE constraint failed: users.email".
+func (d *SQLiteDialect) ClassifyError(err error) errkind.Kind {
+	if err == nil {
+		return errkind.Unknown
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return errkind.UniqueViolation
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return errkind.ForeignKeyViolation
+	case strings.Contains(msg, "NOT NULL constraint failed"):
+		return errkind.NotNullViolation
+	default:
+		return errkind.Unknown
+	}
+}
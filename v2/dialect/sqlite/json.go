@@ -0,0 +1,28 @@
+package sqlite
+
+import "strings"
+
+// JSONSet renders json_set(doc, '$.a.b[0]', json(?)). json() keeps the bound
+// JSON text from being stored as a string.
+func (d *SQLiteDialect) JSONSet(doc string, path []string) string {
+	var p strings.Builder
+	p.WriteString("$")
+	for _, key := range path {
+		if isIndex(key) {
+			p.WriteString("[" + key + "]")
+		} else {
+			p.WriteString("." + key)
+		}
+	}
+	return "json_set(" + doc + ", '" + p.String() + "', json(?))"
+}
+
+// isIndex reports whether a path element is an array index
+func isIndex(key string) bool {
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return key != ""
+}
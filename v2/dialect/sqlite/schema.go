@@ -0,0 +1,15 @@
+package sqlite
+
+import "strings"
+
+// ColumnsQuery lists the columns of table as (name, type) rows using the
+// table_info pragma (pragma_table_info requires SQLite 3.16+)
+func (d *SQLiteDialect) ColumnsQuery(table string) (string, []interface{}) {
+	return "SELECT name, type FROM pragma_table_info(?) ORDER BY cid", []interface{}{table}
+}
+
+// SameColumnType reports whether the live type matches the declared one.
+// SQLite reports the type as written in CREATE TABLE, so only case differs.
+func (d *SQLiteDialect) SameColumnType(declared, live string) bool {
+	return strings.EqualFold(strings.TrimSpace(declared), strings.TrimSpace(live))
+}
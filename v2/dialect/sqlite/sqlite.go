@@ -1,14 +1,38 @@
 package sqlite
 
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/feature"
+)
+
 // SQLiteDialect implements the Dialect interface for SQLite.
-type SQLiteDialect struct{}
+//
+// Version is the SQLite library version, e.g. "3.38.5" as reported by
+// SELECT sqlite_version(). Supports reports features newer than it as
+// unsupported, e.g. RIGHT and FULL OUTER JOIN before 3.39. Empty assumes a
+// current release.
+type SQLiteDialect struct {
+	Version string
+}
+
+func (d *SQLiteDialect) Name() string {
+	return "sqlite"
+}
 
 func (d *SQLiteDialect) Placeholder(position int) string {
 	return "?"
 }
 
 func (d *SQLiteDialect) SupportsReturning() bool {
-	return true // SQLite 3.35.0+ supports RETURNING
+	return d.Supports(feature.Returning)
+}
+
+func (d *SQLiteDialect) SupportsPartialIndex() bool {
+	return true // SQLite 3.8.0+ supports partial indexes
 }
 
 func (d *SQLiteDialect) Quote(identifier string) string {
@@ -18,3 +42,81 @@ func (d *SQLiteDialect) Quote(identifier string) string {
 func (d *SQLiteDialect) FormatIgnoreConflict() string {
 	return "OR IGNORE"
 }
+
+func (d *SQLiteDialect) FormatNoLimit() string {
+	return "LIMIT -1" // OFFSET requires a LIMIT; a negative limit means no limit
+}
+
+func (d *SQLiteDialect) FormatExplain(analyze bool) string {
+	if analyze {
+		return "" // no EXPLAIN ANALYZE; the sqlite3 shell's .scanstats is not SQL
+	}
+	return "EXPLAIN QUERY PLAN"
+}
+
+func (d *SQLiteDialect) ColumnType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "DATETIME"
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return "BLOB"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER" // AUTOINCREMENT requires exactly INTEGER
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	case reflect.String:
+		return "TEXT"
+	default:
+		return ""
+	}
+}
+
+func (d *SQLiteDialect) FormatAutoIncrement() string {
+	return "AUTOINCREMENT"
+}
+
+func (d *SQLiteDialect) Supports(f feature.Feature) bool {
+	switch f {
+	case feature.Returning:
+		return d.atLeast(3, 35)
+	case feature.FullOuterJoin, feature.RightJoin:
+		return d.atLeast(3, 39)
+	case feature.OnConflict:
+		return d.atLeast(3, 24)
+	case feature.WindowFunctions:
+		return d.atLeast(3, 25)
+	case feature.RowValues:
+		return d.atLeast(3, 15)
+	case feature.NullsOrdering:
+		return d.atLeast(3, 30)
+	case feature.JSONSet: // JSON functions are built in from 3.38
+		return d.atLeast(3, 38)
+	default:
+		return false
+	}
+}
+
+// atLeast reports whether Version is major.minor or newer. An empty or
+// unparsable Version counts as new enough.
+func (d *SQLiteDialect) atLeast(major, minor int) bool {
+	if d.Version == "" {
+		return true
+	}
+	parts := strings.SplitN(d.Version, ".", 3)
+	gotMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return true
+	}
+	gotMinor := 0
+	if len(parts) > 1 {
+		if gotMinor, err = strconv.Atoi(parts[1]); err != nil {
+			return true
+		}
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
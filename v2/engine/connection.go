@@ -4,24 +4,43 @@ import (
 	"context"
 	"database/sql"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
+	"github.com/guadalsistema/go-compose-sql/v2/builder"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
 )
 
 // Connection represents a database connection/transaction context.
 type Connection struct {
-	engine *Engine
-	db     *sql.DB
-	ctx    context.Context
-	tx     *sql.Tx
+	engine      *Engine
+	db          *sql.DB
+	ctx         context.Context
+	tx          *sql.Tx
+	stmts       *stmtCache // nil unless EngineOpts.StatementCacheSize > 0
+	replicas    []*sql.DB  // read replica pools (EngineOpts.ReplicaURLs)
+	nextReplica uint32
 }
 
+// Connection implements builder.ConnectionInterface
+var _ builder.ConnectionInterface = (*Connection)(nil)
+
 // Begin starts a transaction on the connection.
 func (c *Connection) Begin() error {
+	return c.BeginContext(c.ctx)
+}
+
+// BeginContext starts a transaction bound to ctx instead of the connection's
+// context; the transaction rolls back if ctx is canceled before Commit.
+func (c *Connection) BeginContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = c.ctx
+	}
 	if c.tx != nil {
 		return ErrAlreadyInTransaction
 	}
-	tx, err := c.db.BeginTx(c.ctx, nil)
+	tx, err := c.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -34,6 +53,14 @@ func (c *Connection) ExecuteContext(ctx context.Context, query string, args ...i
 	if ctx == nil {
 		ctx = c.ctx
 	}
+	if c.stmts != nil {
+		stmt, release, err := c.stmt(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return stmt.ExecContext(ctx, args...)
+	}
 	if c.tx != nil {
 		return c.tx.ExecContext(ctx, query, args...)
 	}
@@ -45,6 +72,14 @@ func (c *Connection) QueryRowContext(ctx context.Context, query string, args ...
 	if ctx == nil {
 		ctx = c.ctx
 	}
+	if c.stmts != nil {
+		// sql.Row has no constructor for errors, so fall back to an
+		// uncached query when the statement cannot be prepared.
+		if stmt, release, err := c.stmt(ctx, query); err == nil {
+			defer release()
+			return stmt.QueryRowContext(ctx, args...)
+		}
+	}
 	if c.tx != nil {
 		return c.tx.QueryRowContext(ctx, query, args...)
 	}
@@ -56,12 +91,53 @@ func (c *Connection) QueryRowsContext(ctx context.Context, query string, args ..
 	if ctx == nil {
 		ctx = c.ctx
 	}
+	if c.stmts != nil {
+		stmt, release, err := c.stmt(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return stmt.QueryContext(ctx, args...)
+	}
 	if c.tx != nil {
 		return c.tx.QueryContext(ctx, query, args...)
 	}
 	return c.db.QueryContext(ctx, query, args...)
 }
 
+// PrepareContext prepares a statement on the open transaction, or on the
+// database outside one.
+func (c *Connection) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if ctx == nil {
+		ctx = c.ctx
+	}
+	if c.tx != nil {
+		return c.tx.PrepareContext(ctx, query)
+	}
+	return c.db.PrepareContext(ctx, query)
+}
+
+// PrepareQuery prepares a SELECT builder on this connection for repeated
+// execution with new args; see builder.Prepare for how args are re-bound.
+func (c *Connection) PrepareQuery(ctx context.Context, q *builder.SelectBuilder) (*builder.PreparedQuery, error) {
+	return builder.Prepare(ctx, c, q)
+}
+
+// stmt returns the cached prepared statement for query. Inside a transaction
+// the cached statement is rebound to the transaction; the rebound copy is
+// released by database/sql when the transaction ends, so the cache itself
+// survives Commit and Rollback. Call release once the statement has run.
+func (c *Connection) stmt(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	stmt, release, err := c.stmts.get(ctx, c.db, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.tx != nil {
+		return c.tx.StmtContext(ctx, stmt), release, nil
+	}
+	return stmt, release, nil
+}
+
 // Commit commits the transaction.
 func (c *Connection) Commit() error {
 	if c.tx == nil {
@@ -87,6 +163,12 @@ func (c *Connection) Close() error {
 	if c.tx != nil {
 		_ = c.Rollback()
 	}
+	if c.stmts != nil {
+		c.stmts.close()
+	}
+	for _, replica := range c.replicas {
+		_ = replica.Close()
+	}
 	return c.db.Close()
 }
 
@@ -105,6 +187,57 @@ func (c *Connection) Logger() *slog.Logger {
 	return c.engine.Logger()
 }
 
+// LogLevel returns the level executed statements are logged at.
+func (c *Connection) LogLevel() slog.Level {
+	return c.engine.LogLevel()
+}
+
+// QueryObserver returns the observer notified after each builder statement.
+func (c *Connection) QueryObserver() builder.QueryObserver {
+	return c.engine.QueryObserver()
+}
+
+// RedactArgs returns the hook applied to statement args before logging.
+func (c *Connection) RedactArgs() func([]any) []any {
+	return c.engine.RedactArgs()
+}
+
+// QuoteIdentifiers reports whether builders quote generated identifiers.
+func (c *Connection) QuoteIdentifiers() bool {
+	return c.engine.QuoteIdentifiers()
+}
+
+// LenientScan reports whether unmatched result columns are discarded.
+func (c *Connection) LenientScan() bool {
+	return c.engine.LenientScan()
+}
+
+// Tracer returns the tracer wrapping each builder statement in a span.
+func (c *Connection) Tracer() builder.Tracer {
+	return c.engine.Tracer()
+}
+
+// QueryTimeout returns the default per-statement timeout for builders.
+func (c *Connection) QueryTimeout() time.Duration {
+	return c.engine.QueryTimeout()
+}
+
+// SlowQueryThreshold returns the duration from which builder statements are
+// reported as slow.
+func (c *Connection) SlowQueryThreshold() time.Duration {
+	return c.engine.SlowQueryThreshold()
+}
+
+// ExplainSlowQueries reports whether slow builder statements are explained.
+func (c *Connection) ExplainSlowQueries() bool {
+	return c.engine.ExplainSlowQueries()
+}
+
+// ResultCache returns the cache SELECT builders read through.
+func (c *Connection) ResultCache() builder.ResultCache {
+	return c.engine.ResultCache()
+}
+
 // Context returns the connection context.
 func (c *Connection) Context() context.Context {
 	return c.ctx
@@ -114,3 +247,70 @@ func (c *Connection) Context() context.Context {
 func (c *Connection) InTransaction() bool {
 	return c.tx != nil
 }
+
+// DB returns the underlying primary database handle, for statements the
+// builders cannot express. Statements run on it directly bypass the open
+// transaction; use Tx inside one.
+func (c *Connection) DB() *sql.DB {
+	return c.db
+}
+
+// Tx returns the open transaction, or nil outside a transaction.
+func (c *Connection) Tx() *sql.Tx {
+	return c.tx
+}
+
+// Query starts a SELECT builder bound to this connection. With read
+// replicas configured and no transaction open, the builder runs on the next
+// replica in round-robin order; use Primary to read your own writes.
+func (c *Connection) Query(tbl table.TableInterface) *builder.SelectBuilder {
+	return builder.NewSelect(tbl).WithConnection(c.reader())
+}
+
+// QueryRaw starts a SELECT builder reading from a raw FROM fragment instead
+// of a table, e.g. QueryRaw("generate_series(?, ?) AS n", 1, 10); see
+// builder.NewSelectFrom. It is routed to replicas like Query.
+func (c *Connection) QueryRaw(fromSQL string, args ...interface{}) *builder.SelectBuilder {
+	return builder.NewSelectFrom(fromSQL, args...).WithConnection(c.reader())
+}
+
+// Primary returns the connection with replica routing disabled, so queries
+// it starts read from the primary, e.g. right after a write. It shares the
+// connection's pools; close the original connection, not the returned one.
+func (c *Connection) Primary() *Connection {
+	if len(c.replicas) == 0 || c.tx != nil {
+		return c
+	}
+	primary := *c
+	primary.replicas = nil
+	return &primary
+}
+
+// reader returns the connection SELECT builders run on: the next replica
+// outside transactions, otherwise c itself
+func (c *Connection) reader() *Connection {
+	if len(c.replicas) == 0 || c.tx != nil {
+		return c
+	}
+	n := atomic.AddUint32(&c.nextReplica, 1) - 1
+	return &Connection{
+		engine: c.engine,
+		db:     c.replicas[int(n%uint32(len(c.replicas)))],
+		ctx:    c.ctx,
+	}
+}
+
+// Insert starts an INSERT builder bound to this connection.
+func (c *Connection) Insert(tbl table.TableInterface) *builder.InsertBuilder {
+	return builder.NewInsert(c.Dialect(), tbl).WithConnection(c)
+}
+
+// Update starts an UPDATE builder bound to this connection.
+func (c *Connection) Update(tbl table.TableInterface) *builder.UpdateBuilder {
+	return builder.NewUpdate(c.Dialect(), tbl).WithConnection(c)
+}
+
+// Delete starts a DELETE builder bound to this connection.
+func (c *Connection) Delete(tbl table.TableInterface) *builder.DeleteBuilder {
+	return builder.NewDelete(c.Dialect(), tbl).WithConnection(c)
+}
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net/url"
 	"strings"
+	"time"
 
+	"github.com/guadalsistema/go-compose-sql/v2/builder"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
@@ -16,17 +18,59 @@ import (
 
 // Engine manages database configuration and connections.
 type Engine struct {
-	dialect dialect.Dialect
-	config  EngineOpts
-	info    *connectionInfo // TODO check if  dialect is needed really, currently is part of info
+	dialect  dialect.Dialect
+	config   EngineOpts
+	info     *connectionInfo // TODO check if  dialect is needed really, currently is part of info
+	replicas []*connectionInfo
 }
 
 // EngineOpts holds engine configuration.
 // Logger is optional and can be used by higher layers to trace SQL statements.
+// StatementCacheSize enables a per-connection LRU of prepared statements
+// holding at most that many entries; zero disables caching.
+// QueryTimeout bounds every builder statement; a caller context with an
+// earlier deadline still takes precedence.
+// LogLevel sets the level executed statements are logged at (Debug when nil)
+// and QueryObserver, when set, is notified after every builder statement.
+// RedactArgs rewrites logged args (after Sensitive column redaction); it
+// never affects the values sent to the database.
+// ReplicaURLs lists read replicas of the primary, in the same URL format and
+// dialect; Connection.Query spreads SELECTs outside transactions across them
+// round-robin while every other statement goes to the primary.
+// SlowQueryThreshold reports builder statements running at least that long
+// at warning level and to a QueryObserver implementing
+// builder.SlowQueryObserver. ExplainSlowQueries additionally runs each slow
+// statement under EXPLAIN (an extra query) to include its plan.
 type EngineOpts struct {
-	Logger     *slog.Logger
-	Autocommit bool
-	Ping       bool // TODO implement ping when connect if driver support it
+	Logger             *slog.Logger
+	LogLevel           slog.Leveler
+	QueryObserver      builder.QueryObserver
+	RedactArgs         func([]any) []any
+	Tracer             builder.Tracer // Optional span per builder statement
+	QuoteIdentifiers   bool           // Quote generated table/column names with the dialect Quote
+	LenientScan        bool           // Discard result columns with no matching struct field
+	Autocommit         bool
+	Ping               bool // TODO implement ping when connect if driver support it
+	StatementCacheSize int
+	QueryTimeout       time.Duration
+	ReplicaURLs        []string
+	SlowQueryThreshold time.Duration
+	ExplainSlowQueries bool
+	SQLiteVersion      string // SQLite library version, e.g. "3.38.5"; gates RIGHT/FULL JOIN and other newer features
+	// OnConnect runs on every new pooled connection, primary or replica,
+	// before it is used, e.g. to run PRAGMA foreign_keys = ON on SQLite or
+	// SET statement_timeout on PostgreSQL. An error discards the connection
+	// and fails the statement that needed it.
+	OnConnect func(ctx context.Context, conn *sql.Conn) error
+	// SQLiteForeignKeys runs PRAGMA foreign_keys = ON on every new SQLite
+	// connection, before OnConnect, so FOREIGN KEY constraints and ON DELETE
+	// actions are enforced; SQLite ignores them by default. Other dialects
+	// ignore it.
+	SQLiteForeignKeys bool
+	// Cache answers repeated SelectBuilder.All queries with the same SQL and
+	// args from memory, e.g. builder.NewTTLCache(1000, time.Minute). Results
+	// can be stale for up to the TTL after a write; see SelectBuilder.All.
+	Cache builder.ResultCache
 }
 
 // NewEngine creates a new database engine from a SQLAlchemy-style connection URL,
@@ -44,10 +88,31 @@ func NewEngine(connectionURL string, opts EngineOpts) (*Engine, error) {
 		return nil, err
 	}
 
+	if _, ok := dialectDriver.(*sqlite.SQLiteDialect); ok && opts.SQLiteVersion != "" {
+		dialectDriver = &sqlite.SQLiteDialect{Version: opts.SQLiteVersion}
+	}
+
+	replicas := make([]*connectionInfo, 0, len(opts.ReplicaURLs))
+	for _, replicaURL := range opts.ReplicaURLs {
+		replica, err := parseConnectionURL(replicaURL)
+		if err != nil {
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		replicaDialect, err := dialectForScheme(replica.dialect)
+		if err != nil {
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		if replicaDialect.Name() != dialectDriver.Name() {
+			return nil, fmt.Errorf("replica dialect %s does not match primary dialect %s", replicaDialect.Name(), dialectDriver.Name())
+		}
+		replicas = append(replicas, replica)
+	}
+
 	return &Engine{
-		dialect: dialectDriver,
-		config:  opts,
-		info:    parsed,
+		dialect:  dialectDriver,
+		config:   opts,
+		info:     parsed,
+		replicas: replicas,
 	}, nil
 }
 
@@ -61,6 +126,59 @@ func (e *Engine) Logger() *slog.Logger {
 	return e.config.Logger
 }
 
+// LogLevel returns the level executed statements are logged at.
+func (e *Engine) LogLevel() slog.Level {
+	if e.config.LogLevel == nil {
+		return slog.LevelDebug
+	}
+	return e.config.LogLevel.Level()
+}
+
+// QueryObserver returns the configured query observer (may be nil).
+func (e *Engine) QueryObserver() builder.QueryObserver {
+	return e.config.QueryObserver
+}
+
+// RedactArgs returns the configured log argument redaction hook (may be nil).
+func (e *Engine) RedactArgs() func([]any) []any {
+	return e.config.RedactArgs
+}
+
+// QuoteIdentifiers reports whether builders quote generated identifiers.
+func (e *Engine) QuoteIdentifiers() bool {
+	return e.config.QuoteIdentifiers
+}
+
+// LenientScan reports whether unmatched result columns are discarded.
+func (e *Engine) LenientScan() bool {
+	return e.config.LenientScan
+}
+
+// Tracer returns the configured statement tracer (may be nil).
+func (e *Engine) Tracer() builder.Tracer {
+	return e.config.Tracer
+}
+
+// QueryTimeout returns the default per-statement timeout (zero for none).
+func (e *Engine) QueryTimeout() time.Duration {
+	return e.config.QueryTimeout
+}
+
+// SlowQueryThreshold returns the slow statement threshold (zero for none).
+func (e *Engine) SlowQueryThreshold() time.Duration {
+	return e.config.SlowQueryThreshold
+}
+
+// ExplainSlowQueries reports whether slow statements are explained.
+func (e *Engine) ExplainSlowQueries() bool {
+	return e.config.ExplainSlowQueries
+}
+
+// ResultCache returns the cache for SELECT results (may be nil).
+func (e *Engine) ResultCache() builder.ResultCache {
+	return e.config.Cache
+}
+
 // Autocommit returns whether the engine defaults to autocommit connections.
 func (e *Engine) Autocommit() bool {
 	return e.config.Autocommit
@@ -73,16 +191,47 @@ func (e *Engine) ConnectionInfo() *connectionInfo {
 
 // Connect creates a new database connection using the engine configuration.
 func (e *Engine) Connect(ctx context.Context) (*Connection, error) {
-	db, err := sql.Open(e.info.sqlDriverName, e.info.dsn)
+	hook := e.connectHook()
+	db, err := openDB(e.info, hook)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connection{
+	conn := &Connection{
 		engine: e,
 		db:     db,
 		ctx:    ctx,
-	}, nil
+	}
+	for _, replica := range e.replicas {
+		replicaDB, err := openDB(replica, hook)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.replicas = append(conn.replicas, replicaDB)
+	}
+	if e.config.StatementCacheSize > 0 {
+		conn.stmts = newStmtCache(e.config.StatementCacheSize)
+	}
+	return conn, nil
+}
+
+// connectHook combines the per-connection setup implied by the options
+// with EngineOpts.OnConnect
+func (e *Engine) connectHook() func(context.Context, *sql.Conn) error {
+	hook := e.config.OnConnect
+	if !e.config.SQLiteForeignKeys || e.dialect.Name() != "sqlite" {
+		return hook
+	}
+	return func(ctx context.Context, conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
+			return fmt.Errorf("enable sqlite foreign keys: %w", err)
+		}
+		if hook != nil {
+			return hook(ctx, conn)
+		}
+		return nil
+	}
 }
 
 type connectionInfo struct {
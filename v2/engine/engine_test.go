@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
 	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
 )
@@ -85,6 +90,179 @@ func TestNewEngineFromConnectionURL(t *testing.T) {
 	}
 }
 
+func TestNewEngineSQLiteVersion(t *testing.T) {
+	registerTestDrivers()
+
+	eng, err := NewEngine("sqlite:///:memory:", EngineOpts{SQLiteVersion: "3.38.5"})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	d, ok := eng.Dialect().(*sqlite.SQLiteDialect)
+	if !ok || d.Version != "3.38.5" {
+		t.Fatalf("Dialect() = %#v, want SQLite 3.38.5", eng.Dialect())
+	}
+	if d.Supports(dialect.FeatureRightJoin) {
+		t.Fatalf("expected RIGHT JOIN to be unsupported on SQLite 3.38.5")
+	}
+}
+
+func TestOnConnectRunsPerConnection(t *testing.T) {
+	registerTestDrivers()
+
+	var calls int
+	eng, err := NewEngine("sqlite:///:memory:", EngineOpts{
+		OnConnect: func(ctx context.Context, conn *sql.Conn) error {
+			calls++
+			_, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+
+	db := conn.DB()
+	db.SetMaxOpenConns(1)
+	for i := 0; i < 3; i++ {
+		if _, err := db.ExecContext(context.Background(), "SELECT 1"); err != nil {
+			t.Fatalf("ExecContext() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("OnConnect ran %d times for one pooled connection, want 1", calls)
+	}
+
+	// A fresh connection runs the hook again
+	db.SetMaxIdleConns(0)
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatalf("PingContext() error = %v", err)
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatalf("PingContext() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("OnConnect ran %d times, want 3", calls)
+	}
+}
+
+func TestOnConnectErrorFailsStatement(t *testing.T) {
+	registerTestDrivers()
+
+	hookErr := errors.New("set statement_timeout failed")
+	eng, err := NewEngine("postgres://localhost/app", EngineOpts{
+		OnConnect: func(context.Context, *sql.Conn) error { return hookErr },
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.DB().PingContext(context.Background()); !errors.Is(err, hookErr) {
+		t.Fatalf("PingContext() error = %v, want %v", err, hookErr)
+	}
+}
+
+func TestSQLiteForeignKeys(t *testing.T) {
+	registerTestDrivers()
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"sqlite:///app.db", []string{"PRAGMA foreign_keys = ON", "SET app.hook = 1", "DELETE FROM client"}},
+		{"postgres://localhost/app", []string{"SET app.hook = 1", "DELETE FROM client"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			eng, err := NewEngine(tt.url, EngineOpts{
+				SQLiteForeignKeys: true,
+				OnConnect: func(ctx context.Context, conn *sql.Conn) error {
+					_, err := conn.ExecContext(ctx, "SET app.hook = 1")
+					return err
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+			conn, err := eng.Connect(context.Background())
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer conn.Close()
+
+			preparedQueries.take()
+			if _, err := conn.DB().ExecContext(context.Background(), "DELETE FROM client"); err != nil {
+				t.Fatalf("ExecContext() error = %v", err)
+			}
+			if got := preparedQueries.take(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("executed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionDBAndTx(t *testing.T) {
+	registerTestDrivers()
+	eng, err := NewEngine("sqlite:///:memory:", EngineOpts{})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+
+	if conn.DB() == nil {
+		t.Fatalf("DB() = nil")
+	}
+	if conn.Tx() != nil {
+		t.Fatalf("Tx() outside a transaction = %v, want nil", conn.Tx())
+	}
+	if err := conn.Begin(); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	tx := conn.Tx()
+	if tx == nil {
+		t.Fatalf("Tx() inside a transaction = nil")
+	}
+	if _, err := tx.ExecContext(context.Background(), "UPDATE users SET name = ?", "x"); err != nil {
+		t.Fatalf("raw Exec on Tx() error = %v", err)
+	}
+	if err := conn.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if conn.Tx() != nil {
+		t.Fatalf("Tx() after Commit = %v, want nil", conn.Tx())
+	}
+}
+
+// newNoopConn connects to a Postgres engine backed by the no-op test driver,
+// for tests and benchmarks that don't inspect the SQL sent
+func newNoopConn(tb testing.TB) *Connection {
+	tb.Helper()
+	registerTestDrivers()
+	eng, err := NewEngine("postgres://localhost/app", EngineOpts{})
+	if err != nil {
+		tb.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		tb.Fatalf("Connect() error = %v", err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	return conn
+}
+
 // registerTestDrivers ensures sql.Open can succeed without pulling real database drivers.
 func registerTestDrivers() {
 	registerDriverOnce("sqlite3")
@@ -107,10 +285,37 @@ func (noopDriver) Open(string) (driver.Conn, error) { return &noopConn{}, nil }
 
 type noopConn struct{}
 
-func (c *noopConn) Prepare(string) (driver.Stmt, error) { return &noopStmt{}, nil }
-func (c *noopConn) Close() error                        { return nil }
-func (c *noopConn) Begin() (driver.Tx, error)           { return &noopTx{}, nil }
-func (c *noopConn) Ping(context.Context) error          { return nil }
+// preparedQueries records every statement the noop driver prepares
+var preparedQueries queryLog
+
+type queryLog struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (l *queryLog) record(query string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = append(l.queries, query)
+}
+
+// take returns the recorded queries and clears the log
+func (l *queryLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queries := l.queries
+	l.queries = nil
+	return queries
+}
+
+func (c *noopConn) Prepare(query string) (driver.Stmt, error) {
+	preparedQueries.record(query)
+	return &noopStmt{}, nil
+}
+
+func (c *noopConn) Close() error               { return nil }
+func (c *noopConn) Begin() (driver.Tx, error)  { return &noopTx{}, nil }
+func (c *noopConn) Ping(context.Context) error { return nil }
 
 type noopStmt struct{}
 
@@ -140,3 +345,22 @@ type noopResult int64
 
 func (r noopResult) LastInsertId() (int64, error) { return int64(r), nil }
 func (r noopResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{errors.New("UNIQUE constraint failed: users.email"), ErrUniqueViolation},
+		{fmt.Errorf("create user: %w", errors.New("Error 1062 (23000): Duplicate entry 'x' for key 'email'")), ErrUniqueViolation},
+		{errors.New(`ERROR: insert or update violates foreign key constraint (SQLSTATE 23503)`), ErrForeignKeyViolation},
+		{errors.New("NOT NULL constraint failed: users.name"), ErrNotNullViolation},
+		{sql.ErrNoRows, ErrUnknown},
+		{nil, ErrUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
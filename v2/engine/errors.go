@@ -1,8 +1,46 @@
 package engine
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
 
 var (
 	ErrNotInTransaction     = errors.New("connection is not in a transaction")
 	ErrAlreadyInTransaction = errors.New("connection is already in a transaction")
 )
+
+// ErrorKind is a driver-independent class of database error
+type ErrorKind = dialect.ErrorKind
+
+// Error kinds returned by ClassifyError
+const (
+	ErrUnknown              = dialect.ErrorUnknown
+	ErrUniqueViolation      = dialect.ErrorUniqueViolation
+	ErrForeignKeyViolation  = dialect.ErrorForeignKeyViolation
+	ErrNotNullViolation     = dialect.ErrorNotNullViolation
+	ErrSerializationFailure = dialect.ErrorSerializationFailure
+	ErrDeadlock             = dialect.ErrorDeadlock
+)
+
+// classifyingDialects are asked in turn to recognize an error; their driver
+// error shapes (SQLSTATE, MySQL error numbers, SQLite messages) don't overlap
+var classifyingDialects = []string{"postgresql", "mysql", "sqlite"}
+
+// ClassifyError reports the kind of a database error from any supported
+// driver, e.g. so a handler can answer 409 Conflict on ErrUniqueViolation
+// instead of 500. Wrapped errors are recognized; nil and unrecognized errors
+// are ErrUnknown.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ErrUnknown
+	}
+	for _, name := range classifyingDialects {
+		d, _ := dialect.DialectByName(name)
+		if kind := d.ClassifyError(err); kind != ErrUnknown {
+			return kind
+		}
+	}
+	return ErrUnknown
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+)
+
+// openDB opens the database for info, running hook on every new pooled
+// connection when it is set
+func openDB(info *connectionInfo, hook func(context.Context, *sql.Conn) error) (*sql.DB, error) {
+	db, err := sql.Open(info.sqlDriverName, info.dsn)
+	if err != nil || hook == nil {
+		return db, err
+	}
+
+	// sql.Open only resolves the driver; swap in a connector that runs hook
+	drv := db.Driver()
+	db.Close()
+	var connector driver.Connector = dsnConnector{dsn: info.dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(info.dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(&hookConnector{Connector: connector, hook: hook}), nil
+}
+
+// dsnConnector adapts a driver without OpenConnector, like sql.Open does
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// hookConnector runs hook on each connection before the pool hands it out
+type hookConnector struct {
+	driver.Connector
+	hook func(context.Context, *sql.Conn) error
+}
+
+func (c *hookConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lend the connection to a single-use pool so the hook gets a *sql.Conn;
+	// closing that pool must not close the connection itself
+	lent := sql.OpenDB(lentConnector{conn: conn, driver: c.Driver()})
+	lent.SetMaxIdleConns(0)
+	err = c.runHook(ctx, lent)
+	lent.Close()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Close closes the wrapped connector when it holds resources, as sql.DB.Close
+// would without the wrapper
+func (c *hookConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c *hookConnector) runHook(ctx context.Context, lent *sql.DB) error {
+	sc, err := lent.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	return c.hook(ctx, sc)
+}
+
+// lentConnector hands out one borrowed connection
+type lentConnector struct {
+	conn   driver.Conn
+	driver driver.Driver
+}
+
+func (c lentConnector) Connect(context.Context) (driver.Conn, error) {
+	return borrowedConn{c.conn}, nil
+}
+
+func (c lentConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// borrowedConn forwards to the pooled connection but leaves closing it to
+// its owner. Exec and Query return driver.ErrSkip when the connection lacks
+// them, so database/sql falls back to Prepare.
+type borrowedConn struct {
+	driver.Conn
+}
+
+func (c borrowedConn) Close() error {
+	return nil
+}
+
+func (c borrowedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c borrowedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
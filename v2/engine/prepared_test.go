@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type benchUsersColumns struct {
+	ID   *table.Column[int64]
+	Name *table.Column[string]
+	Age  *table.Column[int]
+}
+
+type benchUser struct {
+	ID   int64  `sql:"id"`
+	Name string `sql:"name"`
+	Age  int    `sql:"age"`
+}
+
+var benchUsers = table.NewTable("users", benchUsersColumns{
+	ID:   table.Col[int64]("id").PrimaryKey(),
+	Name: table.Col[string]("name"),
+	Age:  table.Col[int]("age"),
+})
+
+func BenchmarkQueryAdHoc(b *testing.B) {
+	conn := newNoopConn(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []benchUser
+		err := conn.Query(benchUsers).
+			Select("id", "name", "age").
+			Where(expr.Gt(benchUsers.C.Age, i)).
+			Limit(10).
+			All(ctx, &users)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryPrepared(b *testing.B) {
+	conn := newNoopConn(b)
+	ctx := context.Background()
+	p, err := conn.PrepareQuery(ctx, conn.Query(benchUsers).
+		Select("id", "name", "age").
+		Where(expr.Gt(benchUsers.C.Age, 0)).
+		Limit(10))
+	if err != nil {
+		b.Fatalf("PrepareQuery() error = %v", err)
+	}
+	defer p.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []benchUser
+		if err := p.Bind(i, 10).All(ctx, &users); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type replicaItemsColumns struct {
+	ID   *table.Column[int64]
+	Name *table.Column[string]
+}
+
+type replicaItem struct {
+	ID   int64  `sql:"id"`
+	Name string `sql:"name"`
+}
+
+func newReplicaTestConn(t *testing.T, replicas int) (*Connection, sqlmock.Sqlmock, []sqlmock.Sqlmock) {
+	t.Helper()
+	open := func() (*Connection, sqlmock.Sqlmock) {
+		return newSchemaTestConn(t, &postgres.PostgresDialect{})
+	}
+	conn, primary := open()
+	var mocks []sqlmock.Sqlmock
+	for i := 0; i < replicas; i++ {
+		replica, mock := open()
+		conn.replicas = append(conn.replicas, replica.db)
+		mocks = append(mocks, mock)
+	}
+	return conn, primary, mocks
+}
+
+func TestQueryRoutesToReplicasRoundRobin(t *testing.T) {
+	items := table.NewTable("items", replicaItemsColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		Name: table.Col[string]("name"),
+	})
+	conn, primary, replicas := newReplicaTestConn(t, 2)
+
+	for _, mock := range []sqlmock.Sqlmock{replicas[0], replicas[1], replicas[0]} {
+		mock.ExpectQuery("SELECT * FROM items").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "a"))
+	}
+	for i := 0; i < 3; i++ {
+		var got []replicaItem
+		if err := conn.Query(items).All(context.Background(), &got); err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+	}
+
+	primary.ExpectExec("INSERT INTO items (id, name) VALUES ($1, $2)").
+		WithArgs(int64(2), "b").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := conn.Insert(items).Values(replicaItem{ID: 2, Name: "b"}).Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	primary.ExpectQuery("SELECT * FROM items").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "b"))
+	var got []replicaItem
+	if err := conn.Primary().Query(items).All(context.Background(), &got); err != nil {
+		t.Fatalf("Primary().Query().All() error = %v", err)
+	}
+
+	for _, mock := range append(replicas, primary) {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	}
+}
+
+func TestQueryInTransactionUsesPrimary(t *testing.T) {
+	items := table.NewTable("items", replicaItemsColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		Name: table.Col[string]("name"),
+	})
+	conn, primary, replicas := newReplicaTestConn(t, 1)
+
+	primary.ExpectBegin()
+	primary.ExpectQuery("SELECT * FROM items").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "a"))
+	primary.ExpectCommit()
+
+	if err := conn.Begin(); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	var got []replicaItem
+	if err := conn.Query(items).All(context.Background(), &got); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if err := conn.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	for _, mock := range append(replicas, primary) {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	}
+}
+
+func TestNewEngineReplicaURLs(t *testing.T) {
+	registerTestDrivers()
+	eng, err := NewEngine("postgres://primary/app", EngineOpts{
+		ReplicaURLs: []string{"postgres://replica1/app", "postgres://replica2/app"},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+	if len(conn.replicas) != 2 {
+		t.Fatalf("Connect() opened %d replicas, want 2", len(conn.replicas))
+	}
+
+	_, err = NewEngine("postgres://primary/app", EngineOpts{ReplicaURLs: []string{"mysql://replica/app"}})
+	if err == nil {
+		t.Fatalf("NewEngine() with a mysql replica of a postgres primary should fail")
+	}
+}
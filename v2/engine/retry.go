@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff between TransactionRetry attempts: retryBaseDelay doubled after
+// every failed attempt, capped at retryMaxDelay
+var (
+	retryBaseDelay = 10 * time.Millisecond
+	retryMaxDelay  = time.Second
+)
+
+// TransactionRetry runs fn inside a transaction, committing when it returns
+// nil. If fn or the commit fails with a transient error (ErrSerializationFailure:
+// Postgres SQLSTATE 40001; ErrDeadlock: Postgres 40P01, MySQL 1213) the
+// transaction is rolled back and retried with exponential backoff, up to
+// maxAttempts runs in total. Any other error is rolled back and returned
+// immediately, as is the last error once attempts run out or ctx is done.
+// fn must not Begin, Commit or Rollback itself, and should be safe to rerun.
+func (c *Connection) TransactionRetry(ctx context.Context, maxAttempts int, fn func(*Connection) error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := c.runTransaction(ctx, fn)
+		if err == nil || attempt >= maxAttempts || !isTransient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
+
+// runTransaction runs fn in a new transaction bound to ctx, rolling back on
+// error
+func (c *Connection) runTransaction(ctx context.Context, fn func(*Connection) error) error {
+	if err := c.BeginContext(ctx); err != nil {
+		return err
+	}
+	if err := fn(c); err != nil {
+		_ = c.Rollback()
+		return err
+	}
+	return c.Commit()
+}
+
+// isTransient reports whether err is worth retrying in a new transaction
+func isTransient(err error) bool {
+	switch ClassifyError(err) {
+	case ErrSerializationFailure, ErrDeadlock:
+		return true
+	default:
+		return false
+	}
+}
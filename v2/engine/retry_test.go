@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// serializationError mimics a Postgres driver error with SQLSTATE 40001
+type serializationError struct{}
+
+func (serializationError) Error() string {
+	return "could not serialize access due to concurrent update"
+}
+func (serializationError) SQLState() string { return "40001" }
+
+// newRetryTestConn returns a no-op connection with a 1ms retry backoff
+func newRetryTestConn(t *testing.T) *Connection {
+	t.Helper()
+	conn := newNoopConn(t)
+
+	base := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = base })
+	return conn
+}
+
+func TestTransactionRetrySucceedsAfterTransientErrors(t *testing.T) {
+	conn := newRetryTestConn(t)
+
+	attempts := 0
+	err := conn.TransactionRetry(context.Background(), 5, func(tx *Connection) error {
+		attempts++
+		if !tx.InTransaction() {
+			t.Fatal("fn called outside a transaction")
+		}
+		if attempts <= 2 {
+			return serializationError{}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("TransactionRetry() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+	if conn.InTransaction() {
+		t.Fatal("transaction left open")
+	}
+}
+
+func TestTransactionRetryGivesUp(t *testing.T) {
+	conn := newRetryTestConn(t)
+
+	attempts := 0
+	err := conn.TransactionRetry(context.Background(), 3, func(*Connection) error {
+		attempts++
+		return serializationError{}
+	})
+	if !errors.As(err, new(serializationError)) {
+		t.Fatalf("TransactionRetry() error = %v, want serialization failure", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestTransactionRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	conn := newRetryTestConn(t)
+
+	permanent := errors.New("UNIQUE constraint failed: users.email")
+	attempts := 0
+	err := conn.TransactionRetry(context.Background(), 5, func(*Connection) error {
+		attempts++
+		return permanent
+	})
+	if !errors.Is(err, permanent) || attempts != 1 {
+		t.Fatalf("TransactionRetry() = %v after %d attempts, want the unique violation after 1", err, attempts)
+	}
+	if conn.InTransaction() {
+		t.Fatal("transaction left open")
+	}
+}
+
+func TestTransactionRetryBeginsWithCallerContext(t *testing.T) {
+	conn := newRetryTestConn(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := conn.TransactionRetry(ctx, 3, func(*Connection) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("TransactionRetry() error = %v, want context.Canceled", err)
+	}
+	if called || conn.InTransaction() {
+		t.Fatal("transaction started with a cancelled context")
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// SchemaDiff is the drift between a table definition and the live table
+type SchemaDiff struct {
+	Table         string
+	Missing       []*table.ColumnRef // declared but absent from the database
+	Extra         []string           // present in the database but not declared
+	TypeMismatch  []ColumnTypeDiff   // declared and present with different types
+	TableNotFound bool               // the live table does not exist; every column is Missing
+}
+
+// ColumnTypeDiff is a column whose live type differs from its declaration
+type ColumnTypeDiff struct {
+	Column   string
+	Declared string // type ColumnType maps the Go type to
+	Live     string // type reported by the database
+}
+
+// Empty reports whether the live table matches the definition
+func (d *SchemaDiff) Empty() bool {
+	return !d.TableNotFound && len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.TypeMismatch) == 0
+}
+
+// SchemaDiff compares tbl with the live table, introspected through
+// information_schema (PostgreSQL, MySQL) or PRAGMA table_info (SQLite), and
+// reports missing, extra and type-mismatched columns. Only column names and
+// types are compared; constraints, defaults and indexes are not. Missing
+// columns can be added with Table.AddColumnSQL.
+func (c *Connection) SchemaDiff(ctx context.Context, tbl table.TableInterface) (*SchemaDiff, error) {
+	d := c.Dialect()
+	introspector, ok := d.(dialect.SchemaIntrospector)
+	if !ok {
+		return nil, fmt.Errorf("the %s dialect does not support schema introspection", d.Name())
+	}
+
+	query, args := introspector.ColumnsQuery(tbl.Name())
+	rows, err := c.QueryRowsContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	live := make(map[string]string)
+	var order []string
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+		live[name] = typ
+		order = append(order, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{Table: tbl.Name(), TableNotFound: len(order) == 0}
+	declared := make(map[string]struct{}, len(tbl.Columns()))
+	for _, col := range tbl.Columns() {
+		declared[col.Name] = struct{}{}
+		liveType, ok := live[col.Name]
+		if !ok {
+			diff.Missing = append(diff.Missing, col)
+			continue
+		}
+		declaredType := col.SQLType(d)
+		if declaredType != "" && !introspector.SameColumnType(declaredType, liveType) {
+			diff.TypeMismatch = append(diff.TypeMismatch, ColumnTypeDiff{
+				Column:   col.Name,
+				Declared: declaredType,
+				Live:     liveType,
+			})
+		}
+	}
+	for _, name := range order {
+		if _, ok := declared[name]; !ok {
+			diff.Extra = append(diff.Extra, name)
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+type schemaUsersColumns struct {
+	ID    *table.Column[int64]
+	Name  *table.Column[string]
+	Email *table.Column[string]
+	Tags  *table.Column[[]string]
+}
+
+func newSchemaTestConn(t *testing.T, d dialect.Dialect) (*Connection, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &Connection{engine: &Engine{dialect: d}, db: db, ctx: context.Background()}, mock
+}
+
+func TestSchemaDiffPostgres(t *testing.T) {
+	users := table.NewTable("users", schemaUsersColumns{
+		ID:    table.Col[int64]("id").PrimaryKey(),
+		Name:  table.Col[string]("name"),
+		Email: table.Col[string]("email"),
+		Tags:  table.Col[[]string]("tags"),
+	})
+	conn, mock := newSchemaTestConn(t, &postgres.PostgresDialect{})
+
+	mock.ExpectQuery("SELECT column_name, CASE WHEN data_type = 'ARRAY' THEN udt_name ELSE data_type END FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position").
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type"}).
+			AddRow("id", "bigint").
+			AddRow("name", "character varying").
+			AddRow("tags", "_text").
+			AddRow("legacy_flag", "boolean"))
+
+	diff, err := conn.SchemaDiff(context.Background(), users)
+	if err != nil {
+		t.Fatalf("SchemaDiff() error = %v", err)
+	}
+	if diff.Empty() || diff.TableNotFound {
+		t.Fatalf("SchemaDiff() = %+v, want drift on an existing table", diff)
+	}
+	if len(diff.Missing) != 1 || diff.Missing[0].Name != "email" {
+		t.Fatalf("Missing = %v, want [email]", diff.Missing)
+	}
+	if !reflect.DeepEqual(diff.Extra, []string{"legacy_flag"}) {
+		t.Fatalf("Extra = %v, want [legacy_flag]", diff.Extra)
+	}
+	want := []ColumnTypeDiff{{Column: "name", Declared: "TEXT", Live: "character varying"}}
+	if !reflect.DeepEqual(diff.TypeMismatch, want) {
+		t.Fatalf("TypeMismatch = %+v, want %+v", diff.TypeMismatch, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSchemaDiffSQLite(t *testing.T) {
+	users := table.NewTable("users", schemaUsersColumns{
+		ID:    table.Col[int64]("id").PrimaryKey(),
+		Name:  table.Col[string]("name"),
+		Email: table.Col[string]("email"),
+	})
+	conn, mock := newSchemaTestConn(t, &sqlite.SQLiteDialect{})
+
+	mock.ExpectQuery("SELECT name, type FROM pragma_table_info(?) ORDER BY cid").
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "type"}).
+			AddRow("id", "integer").
+			AddRow("name", "TEXT").
+			AddRow("email", "TEXT"))
+
+	diff, err := conn.SchemaDiff(context.Background(), users)
+	if err != nil {
+		t.Fatalf("SchemaDiff() error = %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("SchemaDiff() = %+v, want no drift", diff)
+	}
+
+	mock.ExpectQuery("SELECT name, type FROM pragma_table_info(?) ORDER BY cid").
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "type"}))
+
+	diff, err = conn.SchemaDiff(context.Background(), users)
+	if err != nil {
+		t.Fatalf("SchemaDiff() error = %v", err)
+	}
+	if !diff.TableNotFound || len(diff.Missing) != 3 {
+		t.Fatalf("SchemaDiff() = %+v, want a missing table", diff)
+	}
+}
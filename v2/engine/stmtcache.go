@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// stmtCache is a bounded LRU of prepared statements keyed by SQL text.
+type stmtCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front = most recently used
+	items    map[string]*list.Element
+}
+
+type stmtCacheEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // borrowers that have not released stmt yet
+	evicted bool // dropped from the cache; closed once refs reaches 0
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached statement for query, preparing it on db on a miss.
+// The caller must call release once it no longer uses the statement; an
+// evicted statement stays open until every borrower has released it.
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var entry *stmtCacheEntry
+	if el, ok := c.items[query]; ok {
+		c.order.MoveToFront(el)
+		entry = el.Value.(*stmtCacheEntry)
+	} else {
+		stmt, err := db.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry = &stmtCacheEntry{query: query, stmt: stmt}
+		c.items[query] = c.order.PushFront(entry)
+	}
+	entry.refs++
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		c.evict(oldest.Value.(*stmtCacheEntry))
+	}
+	return entry.stmt, func() { c.release(entry) }, nil
+}
+
+// release returns a statement borrowed with get.
+func (c *stmtCache) release(entry *stmtCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		closeStmt(entry.stmt)
+	}
+}
+
+// evict forgets entry, closing its statement unless it is still borrowed.
+// c.mu must be held.
+func (c *stmtCache) evict(entry *stmtCacheEntry) {
+	delete(c.items, entry.query)
+	entry.evicted = true
+	if entry.refs == 0 {
+		closeStmt(entry.stmt)
+	}
+}
+
+// closeStmt closes stmt in the background: sql.Stmt.Close blocks until rows
+// still being read from the statement are closed.
+func closeStmt(stmt *sql.Stmt) {
+	go stmt.Close()
+}
+
+// len returns the number of cached statements.
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// close closes and forgets every cached statement; statements still
+// borrowed are closed when released.
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, el := range c.items {
+		entry := el.Value.(*stmtCacheEntry)
+		delete(c.items, entry.query)
+		entry.evicted = true
+		if entry.refs == 0 {
+			entry.stmt.Close()
+		}
+	}
+	c.order.Init()
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStmtCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	registerTestDrivers()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	cache := newStmtCache(2)
+	defer cache.close()
+
+	ctx := context.Background()
+	for _, q := range []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3"} {
+		_, release, err := cache.get(ctx, db, q)
+		if err != nil {
+			t.Fatalf("get(%q) error = %v", q, err)
+		}
+		release()
+	}
+
+	if got := cache.len(); got != 2 {
+		t.Fatalf("len() = %d, want 2", got)
+	}
+	if _, ok := cache.items["SELECT 2"]; ok {
+		t.Fatal("expected least recently used statement to be evicted")
+	}
+	if _, ok := cache.items["SELECT 1"]; !ok {
+		t.Fatal("expected recently used statement to be kept")
+	}
+}
+
+func TestStmtCacheKeepsBorrowedStatementOpen(t *testing.T) {
+	registerTestDrivers()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	cache := newStmtCache(1)
+	defer cache.close()
+
+	ctx := context.Background()
+	stmt, release, err := cache.get(ctx, db, "SELECT 1")
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	// Evict the borrowed statement
+	_, releaseOther, err := cache.get(ctx, db, "SELECT 2")
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	releaseOther()
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		t.Fatalf("evicted statement closed while borrowed: %v", err)
+	}
+	release()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected evicted statement to close once released")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConnectionStatementCache(t *testing.T) {
+	registerTestDrivers()
+	eng, err := NewEngine("sqlite:///:memory:", EngineOpts{StatementCacheSize: 8})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+
+	const query = "UPDATE users SET name = ? WHERE id = ?"
+	for i := 0; i < 3; i++ {
+		if _, err := conn.ExecuteContext(nil, query, "john", i); err != nil {
+			t.Fatalf("ExecuteContext() error = %v", err)
+		}
+	}
+
+	if err := conn.Begin(); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := conn.ExecuteContext(nil, query, "jane", 1); err != nil {
+		t.Fatalf("ExecuteContext() in transaction error = %v", err)
+	}
+	if err := conn.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if got := conn.stmts.len(); got != 1 {
+		t.Fatalf("cached statements = %d, want 1", got)
+	}
+}
+
+func BenchmarkExecuteContext(b *testing.B) {
+	registerTestDrivers()
+	for _, size := range []int{0, 16} {
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			eng, err := NewEngine("sqlite:///:memory:", EngineOpts{StatementCacheSize: size})
+			if err != nil {
+				b.Fatalf("NewEngine() error = %v", err)
+			}
+			conn, err := eng.Connect(context.Background())
+			if err != nil {
+				b.Fatalf("Connect() error = %v", err)
+			}
+			defer conn.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := conn.ExecuteContext(nil, "UPDATE users SET name = ? WHERE id = ?", "john", i); err != nil {
+					b.Fatalf("ExecuteContext() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// ArrayExpr compares an array column with an ARRAY[...] of bound values
+// using a Postgres array operator (@>, <@, &&)
+type ArrayExpr struct {
+	Column   string
+	Operator string
+	Values   []interface{}
+}
+
+func (a *ArrayExpr) ToSQL() (string, []interface{}) {
+	return a.ToSQLDialect(nil)
+}
+
+func (a *ArrayExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	if len(a.Values) == 0 {
+		// Every array contains the empty array; none overlaps it
+		if a.Operator == "@>" {
+			return "1=1", nil
+		}
+		return "1=0", nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(a.Values)), ", ")
+	return Ident(d, a.Column) + " " + a.Operator + " ARRAY[" + placeholders + "]", a.Values
+}
+
+// Check rejects dialects without native arrays
+func (a *ArrayExpr) Check(d dialect.Dialect) error {
+	return dialect.Require(d, dialect.FeatureArrays)
+}
+
+// ArrayContains matches rows whose array column contains every value
+// (col @> ARRAY[?]); Postgres only
+func ArrayContains[T any](col *table.Column[[]T], values ...T) Expr {
+	return newArrayExpr(col, "@>", values)
+}
+
+// ArrayOverlaps matches rows whose array column shares any value with
+// values (col && ARRAY[?]); Postgres only
+func ArrayOverlaps[T any](col *table.Column[[]T], values ...T) Expr {
+	return newArrayExpr(col, "&&", values)
+}
+
+func newArrayExpr[T any](col *table.Column[[]T], operator string, values []T) Expr {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return &ArrayExpr{
+		Column:   col.FullName(),
+		Operator: operator,
+		Values:   vals,
+	}
+}
@@ -0,0 +1,66 @@
+package expr
+
+import "github.com/guadalsistema/go-compose-sql/v2/dialect"
+
+// Checker is implemented by expressions that only render on dialects with
+// a given capability
+type Checker interface {
+	// Check returns an error if the expression cannot be rendered for d
+	Check(d dialect.Dialect) error
+}
+
+// Check reports whether e, including any nested expressions, can be
+// rendered for dialect d. A nil dialect skips capability checks, but a
+// subquery operand that failed to build is still reported.
+func Check(e Expr, d dialect.Dialect) error {
+	if e == nil {
+		return nil
+	}
+	if c, ok := e.(Checker); ok && d != nil {
+		if err := c.Check(d); err != nil {
+			return err
+		}
+	}
+
+	switch v := e.(type) {
+	case *LogicalExpr:
+		for _, child := range v.Exprs {
+			if err := Check(child, d); err != nil {
+				return err
+			}
+		}
+	case *ParensExpr:
+		return Check(v.Expr, d)
+	case *CompareExpr:
+		if err := checkValue(v.Right, d); err != nil {
+			return err
+		}
+		return Check(v.LeftExpr, d)
+	case *DistinctExpr:
+		return checkValue(v.Right, d)
+	case *CoalesceValue:
+		return checkValue(v, d)
+	case *FragmentExpr:
+		for _, op := range v.Operands {
+			if child, ok := op.(Expr); ok {
+				if err := Check(child, d); err != nil {
+					return err
+				}
+			}
+			if value, ok := op.(SQLValue); ok {
+				if err := checkValue(value, d); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkValue checks an operand that can fail to render, such as a subquery
+func checkValue(v SQLValue, d dialect.Dialect) error {
+	if c, ok := v.(Checker); ok {
+		return c.Check(d)
+	}
+	return nil
+}
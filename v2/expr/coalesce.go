@@ -0,0 +1,65 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
+
+// CoalesceValue renders COALESCE(a, b, ...): columns are inlined and
+// literals bound. It is both an SQLValue, usable as a comparison operand,
+// and an Expr, usable as the left side of Compare or as a selected column.
+type CoalesceValue struct {
+	Values []SQLValue
+}
+
+// Coalesce returns the first non-NULL of values, e.g.
+// Coalesce(Users.C.Nickname, Users.C.Name) renders
+// COALESCE(users.nickname, users.name). Wrap plain values with V:
+// Compare(Coalesce(Users.C.Deleted, V(false)), "=", true)
+func Coalesce(values ...SQLValue) *CoalesceValue {
+	return &CoalesceValue{Values: values}
+}
+
+func (c *CoalesceValue) ToSQL() (string, []interface{}) {
+	return c.ToSQLDialect(nil)
+}
+
+func (c *CoalesceValue) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	parts := make([]string, len(c.Values))
+	var args []interface{}
+	for i, v := range c.Values {
+		sql, valueArgs := valueSQL(v, d)
+		parts[i] = sql
+		args = append(args, valueArgs...)
+	}
+	return "COALESCE(" + strings.Join(parts, ", ") + ")", args
+}
+
+func (c *CoalesceValue) SQLString() (string, bool) {
+	sql, _ := c.ToSQL()
+	return sql, false
+}
+
+func (c *CoalesceValue) Value() interface{} {
+	return nil
+}
+
+func (c *CoalesceValue) Args() []interface{} {
+	_, args := c.ToSQL()
+	return args
+}
+
+// Check reports an empty argument list or an operand that cannot render
+func (c *CoalesceValue) Check(d dialect.Dialect) error {
+	if len(c.Values) == 0 {
+		return fmt.Errorf("Coalesce requires at least one value")
+	}
+	for _, v := range c.Values {
+		if err := checkValue(v, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
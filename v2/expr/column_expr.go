@@ -1,10 +1,22 @@
 package expr
 
-import "github.com/guadalsistema/go-compose-sql/v2/table"
+import (
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
 
 // ColumnExpr provides expression methods for columns
 // This is added to Column[T] via methods
 
+// bind marks values compared against a Sensitive column for log redaction
+func bind[T any](col *table.Column[T], value any) any {
+	if col.Options().Sensitive {
+		return table.SensitiveValue{Val: value}
+	}
+	return value
+}
+
 // Eq creates an equality expression (column = value OR column = column)
 // Accepts either a raw value or another column (SQLValue)
 func Eq[T any](col *table.Column[T], value any) Expr {
@@ -15,7 +27,7 @@ func Eq[T any](col *table.Column[T], value any) Expr {
 		sqlValue = sv
 	} else {
 		// Wrap raw value in Literal
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -31,7 +43,7 @@ func Ne[T any](col *table.Column[T], value any) Expr {
 	if sv, ok := value.(SQLValue); ok {
 		sqlValue = sv
 	} else {
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -47,7 +59,7 @@ func Lt[T any](col *table.Column[T], value any) Expr {
 	if sv, ok := value.(SQLValue); ok {
 		sqlValue = sv
 	} else {
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -63,7 +75,7 @@ func Le[T any](col *table.Column[T], value any) Expr {
 	if sv, ok := value.(SQLValue); ok {
 		sqlValue = sv
 	} else {
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -79,7 +91,7 @@ func Gt[T any](col *table.Column[T], value any) Expr {
 	if sv, ok := value.(SQLValue); ok {
 		sqlValue = sv
 	} else {
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -95,7 +107,7 @@ func Ge[T any](col *table.Column[T], value any) Expr {
 	if sv, ok := value.(SQLValue); ok {
 		sqlValue = sv
 	} else {
-		sqlValue = V(value)
+		sqlValue = V(bind(col, value))
 	}
 
 	return &CompareExpr{
@@ -105,6 +117,33 @@ func Ge[T any](col *table.Column[T], value any) Expr {
 	}
 }
 
+// IsDistinctFrom creates a NULL-safe inequality (column IS DISTINCT FROM value):
+// true when exactly one side is NULL or both are non-NULL and different
+func IsDistinctFrom[T any](col *table.Column[T], value any) Expr {
+	sqlValue, ok := value.(SQLValue)
+	if !ok {
+		sqlValue = V(bind(col, value))
+	}
+	return &DistinctExpr{
+		Column: col.FullName(),
+		Right:  sqlValue,
+	}
+}
+
+// IsNotDistinctFrom creates a NULL-safe equality (column IS NOT DISTINCT FROM
+// value): true when both sides are NULL or equal
+func IsNotDistinctFrom[T any](col *table.Column[T], value any) Expr {
+	sqlValue, ok := value.(SQLValue)
+	if !ok {
+		sqlValue = V(bind(col, value))
+	}
+	return &DistinctExpr{
+		Column: col.FullName(),
+		Right:  sqlValue,
+		Not:    true,
+	}
+}
+
 // IsNull creates an IS NULL expression
 func IsNull[T any](col *table.Column[T]) Expr {
 	return &UnaryExpr{
@@ -125,7 +164,7 @@ func IsNotNull[T any](col *table.Column[T]) Expr {
 func In[T any](col *table.Column[T], values ...T) Expr {
 	vals := make([]interface{}, len(values))
 	for i, v := range values {
-		vals[i] = v
+		vals[i] = bind(col, v)
 	}
 	return &InExpr{
 		Column: col.FullName(),
@@ -138,7 +177,7 @@ func In[T any](col *table.Column[T], values ...T) Expr {
 func NotIn[T any](col *table.Column[T], values ...T) Expr {
 	vals := make([]interface{}, len(values))
 	for i, v := range values {
-		vals[i] = v
+		vals[i] = bind(col, v)
 	}
 	return &InExpr{
 		Column: col.FullName(),
@@ -156,6 +195,42 @@ func Like(col *table.Column[string], pattern string) Expr {
 	}
 }
 
+// EscapeLike escapes the LIKE wildcards % and _, and the escape character
+// \ itself, so s matches literally inside a pattern. Use the result with an
+// escaped expression (LikeEscaped, LikeContains): SQLite has no default
+// escape character
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikeEscaped creates a LIKE expression with ESCAPE '\', for patterns
+// built with EscapeLike, e.g. LikeEscaped(col, EscapeLike(prefix)+"%")
+func LikeEscaped(col *table.Column[string], pattern string) Expr {
+	return &LikeExpr{
+		Column:  col.FullName(),
+		Pattern: pattern,
+		Escaped: true,
+	}
+}
+
+// LikeContains matches values containing s literally: wildcards in s are
+// escaped, so user input can't widen the search
+func LikeContains(col *table.Column[string], s string) Expr {
+	return LikeEscaped(col, "%"+EscapeLike(s)+"%")
+}
+
+// ILikeContains is the case-insensitive LikeContains
+func ILikeContains(col *table.Column[string], s string) Expr {
+	return &LikeExpr{
+		Column:          col.FullName(),
+		Pattern:         "%" + EscapeLike(s) + "%",
+		CaseInsensitive: true,
+		Escaped:         true,
+	}
+}
+
 // NotLike creates a NOT LIKE expression
 func NotLike(col *table.Column[string], pattern string) Expr {
 	return &LikeExpr{
@@ -179,18 +254,46 @@ func ILike(col *table.Column[string], pattern string) Expr {
 func Between[T any](col *table.Column[T], start, end T) Expr {
 	return &BetweenExpr{
 		Column: col.FullName(),
-		Start:  start,
-		End:    end,
+		Start:  bind(col, start),
+		End:    bind(col, end),
 		Not:    false,
 	}
 }
 
+// RangeOpts sets the bound inclusivity of InRange. The zero value is the
+// half-open range [start, end)
+type RangeOpts struct {
+	ExclusiveStart bool // col > start instead of col >= start
+	InclusiveEnd   bool // col <= end instead of col < end
+}
+
+// InRange creates a range check with configurable bounds, by default
+// col >= start AND col < end. Unlike Between, the half-open default suits
+// time ranges: a day is [midnight, next midnight) without an off-by-one at
+// the boundary.
+func InRange[T any](col *table.Column[T], start, end T, opts RangeOpts) Expr {
+	r := &RangeExpr{
+		Column:  col.FullName(),
+		StartOp: ">=",
+		Start:   bind(col, start),
+		EndOp:   "<",
+		End:     bind(col, end),
+	}
+	if opts.ExclusiveStart {
+		r.StartOp = ">"
+	}
+	if opts.InclusiveEnd {
+		r.EndOp = "<="
+	}
+	return r
+}
+
 // NotBetween creates a NOT BETWEEN expression
 func NotBetween[T any](col *table.Column[T], start, end T) Expr {
 	return &BetweenExpr{
 		Column: col.FullName(),
-		Start:  start,
-		End:    end,
+		Start:  bind(col, start),
+		End:    bind(col, end),
 		Not:    true,
 	}
 }
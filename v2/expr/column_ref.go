@@ -0,0 +1,94 @@
+package expr
+
+import "github.com/guadalsistema/go-compose-sql/v2/table"
+
+// ColumnName is a column chosen at runtime: a *table.ColumnRef, e.g. from
+// Table.Columns(), or a qualified name such as "users.id". A string name is
+// written into the SQL as-is, so it must not come from user input.
+type ColumnName interface {
+	*table.ColumnRef | string
+}
+
+// columnOf returns the qualified name of col and whether values compared
+// with it are redacted in logs
+func columnOf[C ColumnName](col C) (string, bool) {
+	switch c := any(col).(type) {
+	case *table.ColumnRef:
+		return c.FullName, c.Options.Sensitive
+	default:
+		return any(col).(string), false
+	}
+}
+
+// compareCol compares a runtime column with a value or another column
+func compareCol[C ColumnName](col C, operator string, value any) Expr {
+	name, sensitive := columnOf(col)
+	sqlValue, ok := value.(SQLValue)
+	if !ok {
+		if sensitive {
+			value = table.SensitiveValue{Val: value}
+		}
+		sqlValue = V(value)
+	}
+	return &CompareExpr{
+		Left:     name,
+		Operator: operator,
+		Right:    sqlValue,
+	}
+}
+
+// EqCol is Eq for a column chosen at runtime, e.g. when building filters
+// over Table.Columns(): EqCol(ref, value) or EqCol("users.id", 1)
+func EqCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, "=", value)
+}
+
+// NeCol is Ne for a column chosen at runtime
+func NeCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, "!=", value)
+}
+
+// LtCol is Lt for a column chosen at runtime
+func LtCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, "<", value)
+}
+
+// LeCol is Le for a column chosen at runtime
+func LeCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, "<=", value)
+}
+
+// GtCol is Gt for a column chosen at runtime
+func GtCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, ">", value)
+}
+
+// GeCol is Ge for a column chosen at runtime
+func GeCol[C ColumnName](col C, value any) Expr {
+	return compareCol(col, ">=", value)
+}
+
+// InCol is In for a column chosen at runtime
+func InCol[C ColumnName](col C, values ...any) Expr {
+	name, sensitive := columnOf(col)
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+		if sensitive {
+			vals[i] = table.SensitiveValue{Val: v}
+		}
+	}
+	return &InExpr{
+		Column: name,
+		Values: vals,
+	}
+}
+
+// IsNullCol is IsNull for a column chosen at runtime
+func IsNullCol[C ColumnName](col C) Expr {
+	name, _ := columnOf(col)
+	return &UnaryExpr{
+		Column:   name,
+		Operator: "IS NULL",
+	}
+}
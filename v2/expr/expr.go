@@ -1,11 +1,37 @@
 package expr
 
+import (
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
+
 // Expr represents a SQL expression (WHERE, HAVING, etc.)
 type Expr interface {
 	// ToSQL converts the expression to SQL with placeholders
 	ToSQL() (string, []interface{})
 }
 
+// DialectExpr is an expression whose SQL depends on the target dialect.
+// Builders render expressions through Render, so custom expressions only
+// need ToSQLDialect when their output differs between databases; composite
+// expressions (And/Or, Parens, Fragment, Compare) pass the dialect down.
+type DialectExpr interface {
+	Expr
+
+	// ToSQLDialect converts the expression to SQL for the given dialect
+	ToSQLDialect(d dialect.Dialect) (string, []interface{})
+}
+
+// Render converts e to SQL for dialect d. Expressions that are not
+// dialect-aware, or a nil dialect, fall back to ToSQL.
+func Render(e Expr, d dialect.Dialect) (string, []interface{}) {
+	if de, ok := e.(DialectExpr); ok && d != nil {
+		return de.ToSQLDialect(d)
+	}
+	return e.ToSQL()
+}
+
 // SQLValue represents a value that can be used in SQL comparisons
 // It can be either a column reference or a literal value
 type SQLValue interface {
@@ -32,18 +58,25 @@ func (b *BinaryExpr) ToSQL() (string, []interface{}) {
 // CompareExpr represents a comparison operation that supports both column and value comparisons
 type CompareExpr struct {
 	Left     string
+	LeftExpr Expr // Optional; rendered instead of Left, e.g. lower(email)
 	Operator string
 	Right    SQLValue
 }
 
 func (c *CompareExpr) ToSQL() (string, []interface{}) {
-	rightSQL, isLiteral := c.Right.SQLString()
-	if isLiteral {
-		// Value comparison: column = ?
-		return c.Left + " " + c.Operator + " " + rightSQL, []interface{}{c.Right.Value()}
+	return c.ToSQLDialect(nil)
+}
+
+func (c *CompareExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	left := Ident(d, c.Left)
+	var args []interface{}
+	if c.LeftExpr != nil {
+		left, args = Render(c.LeftExpr, d)
 	}
-	// Column comparison: column1 = column2
-	return c.Left + " " + c.Operator + " " + rightSQL, nil
+
+	// column = ?, column1 = column2 or column = (SELECT ...)
+	rightSQL, rightArgs := valueSQL(c.Right, d)
+	return left + " " + c.Operator + " " + rightSQL, append(args, rightArgs...)
 }
 
 // Literal wraps a value to implement SQLValue interface
@@ -71,6 +104,10 @@ type LogicalExpr struct {
 }
 
 func (l *LogicalExpr) ToSQL() (string, []interface{}) {
+	return l.ToSQLDialect(nil)
+}
+
+func (l *LogicalExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
 	if len(l.Exprs) == 0 {
 		return "", nil
 	}
@@ -79,7 +116,7 @@ func (l *LogicalExpr) ToSQL() (string, []interface{}) {
 	var args []interface{}
 
 	for _, expr := range l.Exprs {
-		sql, exprArgs := expr.ToSQL()
+		sql, exprArgs := Render(expr, d)
 		if sql != "" {
 			sqlParts = append(sqlParts, "("+sql+")")
 			args = append(args, exprArgs...)
@@ -110,10 +147,16 @@ type UnaryExpr struct {
 }
 
 func (u *UnaryExpr) ToSQL() (string, []interface{}) {
-	return u.Column + " " + u.Operator, nil
+	return u.ToSQLDialect(nil)
+}
+
+func (u *UnaryExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	return Ident(d, u.Column) + " " + u.Operator, nil
 }
 
-// InExpr represents IN/NOT IN operations
+// InExpr represents IN/NOT IN operations.
+// With no values IN matches nothing (1=0) and NOT IN matches everything (1=1),
+// so a filter built from an empty slice never silently selects all rows.
 type InExpr struct {
 	Column string
 	Values []interface{}
@@ -121,8 +164,15 @@ type InExpr struct {
 }
 
 func (i *InExpr) ToSQL() (string, []interface{}) {
+	return i.ToSQLDialect(nil)
+}
+
+func (i *InExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
 	if len(i.Values) == 0 {
-		return "", nil
+		if i.Not {
+			return "1=1", nil
+		}
+		return "1=0", nil
 	}
 
 	op := "IN"
@@ -138,7 +188,7 @@ func (i *InExpr) ToSQL() (string, []interface{}) {
 		placeholders += "?"
 	}
 
-	sql := i.Column + " " + op + " (" + placeholders + ")"
+	sql := Ident(d, i.Column) + " " + op + " (" + placeholders + ")"
 	return sql, i.Values
 }
 
@@ -148,21 +198,82 @@ type LikeExpr struct {
 	Pattern         string
 	CaseInsensitive bool
 	Not             bool
+	Escaped         bool // ESCAPE '\', for patterns built with EscapeLike
 }
 
 func (l *LikeExpr) ToSQL() (string, []interface{}) {
+	return l.ToSQLDialect(nil)
+}
+
+// ToSQLDialect renders ILIKE natively where the dialect supports it (and for
+// a nil dialect); elsewhere case-insensitive matching becomes
+// LOWER(col) LIKE LOWER(?). An escaped pattern adds ESCAPE '\', written
+// '\\' on MySQL where backslash escapes inside string literals.
+func (l *LikeExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	column := Ident(d, l.Column)
+	not := ""
+	if l.Not {
+		not = "NOT "
+	}
+	escape := ""
+	if l.Escaped {
+		escape = ` ESCAPE '\'`
+		if d != nil && d.Name() == "mysql" {
+			escape = ` ESCAPE '\\'`
+		}
+	}
+
+	if l.CaseInsensitive && d != nil && !d.Supports(dialect.FeatureILike) {
+		sql := "LOWER(" + column + ") " + not + "LIKE LOWER(?)" + escape
+		return sql, []interface{}{l.Pattern}
+	}
+
 	op := "LIKE"
 	if l.CaseInsensitive {
 		op = "ILIKE"
 	}
-	if l.Not {
-		op = "NOT " + op
-	}
-
-	sql := l.Column + " " + op + " ?"
+	sql := column + " " + not + op + " ?" + escape
 	return sql, []interface{}{l.Pattern}
 }
 
+// DistinctExpr is a NULL-safe comparison: IS [NOT] DISTINCT FROM treats two
+// NULLs as equal and a NULL and a value as different
+type DistinctExpr struct {
+	Column string
+	Right  SQLValue
+	Not    bool // IS NOT DISTINCT FROM (null-safe equality)
+}
+
+func (e *DistinctExpr) ToSQL() (string, []interface{}) {
+	return e.ToSQLDialect(nil)
+}
+
+// ToSQLDialect renders the standard IS [NOT] DISTINCT FROM on Postgres (and
+// for a nil dialect), <=> on MySQL and IS [NOT] on SQLite.
+func (e *DistinctExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	column := Ident(d, e.Column)
+	right, args := valueSQL(e.Right, d)
+
+	name := ""
+	if d != nil {
+		name = d.Name()
+	}
+	switch {
+	case name == "mysql" && e.Not:
+		return column + " <=> " + right, args
+	case name == "mysql":
+		return "NOT (" + column + " <=> " + right + ")", args
+	case name == "sqlite" && e.Not:
+		return column + " IS " + right, args
+	case name == "sqlite":
+		return column + " IS NOT " + right, args
+	case e.Not:
+		return column + " IS NOT DISTINCT FROM " + right, args
+	default:
+		return column + " IS DISTINCT FROM " + right, args
+	}
+}
+
 // BetweenExpr represents BETWEEN operations
 type BetweenExpr struct {
 	Column string
@@ -172,15 +283,38 @@ type BetweenExpr struct {
 }
 
 func (b *BetweenExpr) ToSQL() (string, []interface{}) {
+	return b.ToSQLDialect(nil)
+}
+
+func (b *BetweenExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
 	op := "BETWEEN"
 	if b.Not {
 		op = "NOT BETWEEN"
 	}
 
-	sql := b.Column + " " + op + " ? AND ?"
+	sql := Ident(d, b.Column) + " " + op + " ? AND ?"
 	return sql, []interface{}{b.Start, b.End}
 }
 
+// RangeExpr represents a range check with a bound on each side
+type RangeExpr struct {
+	Column  string
+	StartOp string // ">=" or ">"
+	Start   interface{}
+	EndOp   string // "<" or "<="
+	End     interface{}
+}
+
+func (r *RangeExpr) ToSQL() (string, []interface{}) {
+	return r.ToSQLDialect(nil)
+}
+
+func (r *RangeExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	column := Ident(d, r.Column)
+	sql := column + " " + r.StartOp + " ? AND " + column + " " + r.EndOp + " ?"
+	return sql, []interface{}{r.Start, r.End}
+}
+
 // RawExpr represents a raw SQL expression
 type RawExpr struct {
 	SQL  string
@@ -191,6 +325,82 @@ func (r *RawExpr) ToSQL() (string, []interface{}) {
 	return r.SQL, r.Args
 }
 
+// ParensExpr wraps an expression in parentheses
+type ParensExpr struct {
+	Expr Expr
+}
+
+func (p *ParensExpr) ToSQL() (string, []interface{}) {
+	return p.ToSQLDialect(nil)
+}
+
+func (p *ParensExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	sql, args := Render(p.Expr, d)
+	return "(" + sql + ")", args
+}
+
+// FragmentExpr is a raw SQL fragment whose ? markers are filled by operands:
+// expressions and columns are inlined, anything else is bound as a value.
+// Like placeholders, a ? inside a quoted literal, ?? and the Postgres ?| and
+// ?& operators are copied as written rather than taking an operand.
+type FragmentExpr struct {
+	SQL      string
+	Operands []interface{}
+}
+
+func (f *FragmentExpr) ToSQL() (string, []interface{}) {
+	return f.ToSQLDialect(nil)
+}
+
+func (f *FragmentExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	var sql strings.Builder
+	var args []interface{}
+	next := 0
+	var quote byte
+	for i := 0; i < len(f.SQL); i++ {
+		c := f.SQL[i]
+		if quote != 0 || c != '?' || next >= len(f.Operands) {
+			if quote == 0 && (c == '\'' || c == '"') {
+				quote = c
+			} else if c == quote {
+				quote = 0
+			}
+			sql.WriteByte(c)
+			continue
+		}
+		if (i+1 < len(f.SQL) && f.SQL[i+1] == '?') || isJSONOperator(f.SQL, i) {
+			// ??, ?| or ?&, left for FormatPlaceholders
+			sql.WriteString(f.SQL[i : i+2])
+			i++
+			continue
+		}
+		switch op := f.Operands[next].(type) {
+		case Expr:
+			opSQL, opArgs := Render(op, d)
+			sql.WriteString(opSQL)
+			args = append(args, opArgs...)
+		case SQLValue:
+			opSQL, opArgs := valueSQL(op, d)
+			sql.WriteString(opSQL)
+			args = append(args, opArgs...)
+		default:
+			sql.WriteByte('?')
+			args = append(args, op)
+		}
+		next++
+	}
+	return sql.String(), args
+}
+
+// isJSONOperator reports whether the ? at sql[i] starts a Postgres ?| or ?&
+// operator rather than a placeholder followed by || or &&
+func isJSONOperator(sql string, i int) bool {
+	if i+1 >= len(sql) || (sql[i+1] != '|' && sql[i+1] != '&') {
+		return false
+	}
+	return i+2 >= len(sql) || sql[i+2] != sql[i+1]
+}
+
 // Helper functions for building expressions
 
 // And combines multiple expressions with AND
@@ -209,6 +419,36 @@ func Or(exprs ...Expr) Expr {
 	}
 }
 
+// Parens wraps an expression in parentheses
+func Parens(e Expr) Expr {
+	return &ParensExpr{Expr: e}
+}
+
+// Fragment builds a reusable SQL fragment, filling each ? with an operand:
+// columns and expressions are inlined, other values are bound.
+// e.g. Fragment("lower(?)", Users.C.Email) renders lower(users.email)
+func Fragment(sql string, operands ...interface{}) Expr {
+	return &FragmentExpr{
+		SQL:      sql,
+		Operands: operands,
+	}
+}
+
+// Compare compares an arbitrary left operand (a Fragment, function call or
+// subexpression) with a value or column, e.g.
+// Compare(Fragment("lower(?)", Users.C.Email), "=", "john@example.com")
+func Compare(left Expr, operator string, value any) Expr {
+	sqlValue, ok := value.(SQLValue)
+	if !ok {
+		sqlValue = V(value)
+	}
+	return &CompareExpr{
+		LeftExpr: left,
+		Operator: operator,
+		Right:    sqlValue,
+	}
+}
+
 // Raw creates a raw SQL expression
 func Raw(sql string, args ...interface{}) Expr {
 	return &RawExpr{
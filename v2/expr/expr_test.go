@@ -0,0 +1,399 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+func TestInEmptyValues(t *testing.T) {
+	id := table.Col[int64]("id")
+
+	tests := []struct {
+		name string
+		expr Expr
+		want string
+	}{
+		{"in matches nothing", In(id), "1=0"},
+		{"not in matches everything", NotIn(id), "1=1"},
+		{"nested in and", And(Eq(id, 1), In(id)), "((id = ?) AND (1=0))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _ := tt.expr.ToSQL()
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestInRange(t *testing.T) {
+	createdAt := table.Col[time.Time]("created_at")
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name string
+		opts RangeOpts
+		want string
+	}{
+		{"half-open by default", RangeOpts{}, "created_at >= ? AND created_at < ?"},
+		{"inclusive end", RangeOpts{InclusiveEnd: true}, "created_at >= ? AND created_at <= ?"},
+		{"exclusive start", RangeOpts{ExclusiveStart: true}, "created_at > ? AND created_at < ?"},
+		{"exclusive start inclusive end", RangeOpts{ExclusiveStart: true, InclusiveEnd: true}, "created_at > ? AND created_at <= ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := InRange(createdAt, start, end, tt.opts).ToSQL()
+			if sql != tt.want {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.want)
+			}
+			if want := []interface{}{start, end}; !reflect.DeepEqual(args, want) {
+				t.Fatalf("args = %v, want %v", args, want)
+			}
+		})
+	}
+
+	sql, _ := Or(InRange(createdAt, start, end, RangeOpts{}), IsNull(createdAt)).ToSQL()
+	if want := "((created_at >= ? AND created_at < ?) OR (created_at IS NULL))"; sql != want {
+		t.Fatalf("nested ToSQL() = %q, want %q", sql, want)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`a\b`, `a\\b`},
+		{`%_\`, `\%\_\\`},
+	}
+	for _, tt := range tests {
+		if got := EscapeLike(tt.in); got != tt.want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLikeContainsByDialect(t *testing.T) {
+	name := table.Col[string]("name")
+
+	tests := []struct {
+		name    string
+		expr    Expr
+		dialect dialect.Dialect
+		wantSQL string
+	}{
+		{"postgres", LikeContains(name, "50%_off"), &postgres.PostgresDialect{}, `name LIKE ? ESCAPE '\'`},
+		{"sqlite", LikeContains(name, "50%_off"), &sqlite.SQLiteDialect{}, `name LIKE ? ESCAPE '\'`},
+		{"mysql escapes the backslash literal", LikeContains(name, "50%_off"), &mysql.MySQLDialect{}, `name LIKE ? ESCAPE '\\'`},
+		{"ilike native", ILikeContains(name, "50%_off"), &postgres.PostgresDialect{}, `name ILIKE ? ESCAPE '\'`},
+		{"ilike fallback", ILikeContains(name, "50%_off"), &sqlite.SQLiteDialect{}, `LOWER(name) LIKE LOWER(?) ESCAPE '\'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := Render(tt.expr, tt.dialect)
+			if sql != tt.wantSQL {
+				t.Fatalf("Render() = %q, want %q", sql, tt.wantSQL)
+			}
+			if want := []interface{}{`%50\%\_off%`}; !reflect.DeepEqual(args, want) {
+				t.Fatalf("args = %v, want %v", args, want)
+			}
+		})
+	}
+
+	sql, args := LikeEscaped(name, EscapeLike("a_b")+"%").ToSQL()
+	if sql != `name LIKE ? ESCAPE '\'` || !reflect.DeepEqual(args, []interface{}{`a\_b%`}) {
+		t.Fatalf("LikeEscaped ToSQL() = %q %v", sql, args)
+	}
+}
+
+func TestFragmentAsLeftOperand(t *testing.T) {
+	email := table.Col[string]("email")
+	name := table.Col[string]("name")
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "function call on column",
+			expr:     Compare(Fragment("lower(?)", email), "=", "john@example.com"),
+			wantSQL:  "lower(email) = ?",
+			wantArgs: []interface{}{"john@example.com"},
+		},
+		{
+			name:     "bound operand and column right side",
+			expr:     Compare(Fragment("substr(?, 1, ?)", email, 3), "=", name),
+			wantSQL:  "substr(email, 1, ?) = name",
+			wantArgs: []interface{}{3},
+		},
+		{
+			name:     "nested expression in parens",
+			expr:     Compare(Parens(Fragment("? || ?", name, email)), "!=", "x"),
+			wantSQL:  "(name || email) != ?",
+			wantArgs: []interface{}{"x"},
+		},
+		{
+			name:     "quoted and operator question marks take no operand",
+			expr:     Fragment("coalesce(?, '?') = ? AND tags ?| ? AND tags ?? ?", name, "n", []string{"a"}, "k"),
+			wantSQL:  "coalesce(name, '?') = ? AND tags ?| ? AND tags ?? ?",
+			wantArgs: []interface{}{"n", []string{"a"}, "k"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.expr.ToSQL()
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestILikeByDialect(t *testing.T) {
+	email := table.Col[string]("email")
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		expr    Expr
+		want    string
+	}{
+		{"no dialect", nil, ILike(email, "%@x.com"), "email ILIKE ?"},
+		{"postgres", &postgres.PostgresDialect{}, ILike(email, "%@x.com"), "email ILIKE ?"},
+		{"sqlite", &sqlite.SQLiteDialect{}, ILike(email, "%@x.com"), "LOWER(email) LIKE LOWER(?)"},
+		{"mysql", &mysql.MySQLDialect{}, ILike(email, "%@x.com"), "LOWER(email) LIKE LOWER(?)"},
+		{"mysql not", &mysql.MySQLDialect{}, &LikeExpr{Column: "email", Pattern: "a%", CaseInsensitive: true, Not: true}, "LOWER(email) NOT LIKE LOWER(?)"},
+		{"sqlite nested", &sqlite.SQLiteDialect{}, Or(ILike(email, "a%"), Like(email, "b%")), "((LOWER(email) LIKE LOWER(?)) OR (email LIKE ?))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := Render(tt.expr, tt.dialect)
+			if sql != tt.want {
+				t.Fatalf("Render() = %q, want %q", sql, tt.want)
+			}
+			if len(args) == 0 {
+				t.Fatal("expected pattern args")
+			}
+		})
+	}
+}
+
+func TestRenderPropagatesDialect(t *testing.T) {
+	email := table.Col[string]("email")
+	d := &mysql.MySQLDialect{}
+
+	tests := []struct {
+		name string
+		expr Expr
+		want string
+	}{
+		{"parens", Parens(ILike(email, "a%")), "(LOWER(email) LIKE LOWER(?))"},
+		{"fragment", Fragment("NOT ?", ILike(email, "a%")), "NOT LOWER(email) LIKE LOWER(?)"},
+		{"compare left", Compare(Parens(ILike(email, "a%")), "=", true), "(LOWER(email) LIKE LOWER(?)) = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sql, _ := Render(tt.expr, d); sql != tt.want {
+				t.Fatalf("Render() = %q, want %q", sql, tt.want)
+			}
+			// The no-arg ToSQL keeps the dialect-neutral output
+			if sql, _ := tt.expr.ToSQL(); !strings.Contains(sql, "ILIKE") {
+				t.Fatalf("ToSQL() = %q, want ILIKE", sql)
+			}
+		})
+	}
+}
+
+func TestArrayContains(t *testing.T) {
+	tags := table.Col[[]string]("tags")
+
+	sql, args := Render(ArrayContains(tags, "go"), &postgres.PostgresDialect{})
+	if sql != "tags @> ARRAY[?]" || !reflect.DeepEqual(args, []interface{}{"go"}) {
+		t.Fatalf("Render() = %q %v", sql, args)
+	}
+	if err := Check(And(Eq(table.Col[int]("age"), 3), ArrayContains(tags, "go")), &postgres.PostgresDialect{}); err != nil {
+		t.Fatalf("Check(postgres) error = %v", err)
+	}
+	err := Check(And(Eq(table.Col[int]("age"), 3), ArrayContains(tags, "go")), &sqlite.SQLiteDialect{})
+	if err == nil || !strings.Contains(err.Error(), "ARRAY") {
+		t.Fatalf("Check(sqlite) error = %v, want arrays unsupported", err)
+	}
+}
+
+func TestInTuple(t *testing.T) {
+	cols := []string{"orders.tenant_id", "orders.id"}
+
+	sql, args := InTuple(cols, [][]interface{}{{1, 7}, {2, 9}}).ToSQL()
+	if sql != "(orders.tenant_id, orders.id) IN ((?, ?), (?, ?))" {
+		t.Fatalf("ToSQL() = %q", sql)
+	}
+	if want := []interface{}{1, 7, 2, 9}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	if sql, _ := NotInTuple(cols, [][]interface{}{{1, 7}}).ToSQL(); sql != "(orders.tenant_id, orders.id) NOT IN ((?, ?))" {
+		t.Fatalf("ToSQL() = %q", sql)
+	}
+	if sql, args := InTuple(cols, nil).ToSQL(); sql != "1=0" || args != nil {
+		t.Fatalf("ToSQL() = %q %v, want 1=0", sql, args)
+	}
+
+	tests := []struct {
+		name    string
+		e       Expr
+		d       dialect.Dialect
+		wantErr string
+	}{
+		{"postgres", InTuple(cols, [][]interface{}{{1, 7}}), &postgres.PostgresDialect{}, ""},
+		{"sqlite", InTuple(cols, [][]interface{}{{1, 7}}), &sqlite.SQLiteDialect{}, ""},
+		{"mysql", InTuple(cols, [][]interface{}{{1, 7}}), &mysql.MySQLDialect{}, "row values is not supported by the mysql dialect"},
+		{"ragged row", And(InTuple(cols, [][]interface{}{{1, 7}, {2}})), &postgres.PostgresDialect{}, "InTuple row 1 has 1 values, want 2"},
+		{"no columns", InTuple(nil, nil), &postgres.PostgresDialect{}, "InTuple requires at least one column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.e, tt.d)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsDistinctFromByDialect(t *testing.T) {
+	email := table.Col[string]("email")
+	backup := table.Col[string]("backup_email")
+
+	tests := []struct {
+		name    string
+		dialect dialect.Dialect
+		expr    Expr
+		want    string
+	}{
+		{"no dialect", nil, IsDistinctFrom(email, "a@x.com"), "email IS DISTINCT FROM ?"},
+		{"postgres", &postgres.PostgresDialect{}, IsDistinctFrom(email, "a@x.com"), "email IS DISTINCT FROM ?"},
+		{"postgres not", &postgres.PostgresDialect{}, IsNotDistinctFrom(email, "a@x.com"), "email IS NOT DISTINCT FROM ?"},
+		{"mysql", &mysql.MySQLDialect{}, IsDistinctFrom(email, "a@x.com"), "NOT (email <=> ?)"},
+		{"mysql not", &mysql.MySQLDialect{}, IsNotDistinctFrom(email, "a@x.com"), "email <=> ?"},
+		{"sqlite", &sqlite.SQLiteDialect{}, IsDistinctFrom(email, "a@x.com"), "email IS NOT ?"},
+		{"sqlite not", &sqlite.SQLiteDialect{}, IsNotDistinctFrom(email, "a@x.com"), "email IS ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := Render(tt.expr, tt.dialect)
+			if sql != tt.want {
+				t.Fatalf("Render() = %q, want %q", sql, tt.want)
+			}
+			if len(args) != 1 || args[0] != "a@x.com" {
+				t.Fatalf("Render() args = %v", args)
+			}
+		})
+	}
+
+	sql, args := Render(IsNotDistinctFrom(email, backup), &mysql.MySQLDialect{})
+	if sql != "email <=> backup_email" || len(args) != 0 {
+		t.Fatalf("Render() = %q %v, want column comparison", sql, args)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	nickname := table.Col[string]("nickname")
+	name := table.Col[string]("name")
+	deleted := table.Col[bool]("deleted")
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"columns", Coalesce(nickname, name), "COALESCE(nickname, name)", nil},
+		{"column and literal", Coalesce(nickname, V("anonymous")), "COALESCE(nickname, ?)", []interface{}{"anonymous"}},
+		{"compare left", Compare(Coalesce(deleted, V(false)), "=", true), "COALESCE(deleted, ?) = ?", []interface{}{false, true}},
+		{"compare right", Eq(name, Coalesce(V("x"), nickname)), "name = COALESCE(?, nickname)", []interface{}{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.expr.ToSQL()
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if err := Check(Compare(Coalesce(), "=", 1), nil); err == nil {
+		t.Fatal("Check() error = nil, want error for empty Coalesce")
+	}
+}
+
+func TestColumnRefComparisons(t *testing.T) {
+	type accountColumns struct {
+		ID       *table.Column[int64]
+		Password *table.Column[string]
+	}
+	accounts := table.NewTable("accounts", accountColumns{
+		ID:       table.Col[int64]("id"),
+		Password: table.Col[string]("password").Sensitive(),
+	})
+	id, password := accounts.Columns()[0], accounts.Columns()[1]
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"eq ref", EqCol(id, int64(7)), "accounts.id = ?", []interface{}{int64(7)}},
+		{"eq name", EqCol("accounts.id", 7), "accounts.id = ?", []interface{}{7}},
+		{"ne", NeCol(id, 1), "accounts.id != ?", []interface{}{1}},
+		{"lt", LtCol(id, 1), "accounts.id < ?", []interface{}{1}},
+		{"le", LeCol(id, 1), "accounts.id <= ?", []interface{}{1}},
+		{"gt", GtCol(id, 1), "accounts.id > ?", []interface{}{1}},
+		{"ge", GeCol(id, 1), "accounts.id >= ?", []interface{}{1}},
+		{"column to column", EqCol("accounts.id", accounts.C.ID), "accounts.id = accounts.id", nil},
+		{"in", InCol(id, 1, 2), "accounts.id IN (?, ?)", []interface{}{1, 2}},
+		{"is null", IsNullCol("accounts.password"), "accounts.password IS NULL", nil},
+		{"sensitive", EqCol(password, "hunter2"), "accounts.password = ?", []interface{}{table.SensitiveValue{Val: "hunter2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.expr.ToSQL()
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
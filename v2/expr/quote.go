@@ -0,0 +1,20 @@
+package expr
+
+import "github.com/guadalsistema/go-compose-sql/v2/dialect"
+
+// QuotingDialect wraps a dialect so expressions rendered for it pass column
+// references through QuoteIdent, e.g. "order".id. Builders render with it
+// when the connection enables identifier quoting.
+type QuotingDialect struct {
+	dialect.Dialect
+	QuoteIdent func(string) string
+}
+
+// Ident renders the column reference name for dialect d: quoted when d is a
+// QuotingDialect, unchanged otherwise
+func Ident(d dialect.Dialect, name string) string {
+	if q, ok := d.(*QuotingDialect); ok {
+		return q.QuoteIdent(name)
+	}
+	return name
+}
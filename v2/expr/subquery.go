@@ -0,0 +1,71 @@
+package expr
+
+import (
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+)
+
+// Subquery is a query usable as an operand, such as a *builder.SelectBuilder
+type Subquery interface {
+	ToSQL() (string, []interface{}, error)
+}
+
+// ArgsValue is an SQLValue whose SQL carries its own bind arguments, such as
+// a subquery; expressions bind Args in place of Value
+type ArgsValue interface {
+	SQLValue
+	Args() []interface{}
+}
+
+// SubqueryValue is a scalar subquery operand rendered as (SELECT ...)
+type SubqueryValue struct {
+	sql  string
+	args []interface{}
+	err  error
+}
+
+// ScalarSubquery uses a query returning a single value as a comparison
+// operand, e.g.
+// Gt(Products.C.Price, ScalarSubquery(NewSelect(Products).Select("AVG(price)")))
+// renders products.price > (SELECT AVG(price) FROM products). The subquery's
+// SQL and args are captured when ScalarSubquery is called.
+func ScalarSubquery(sub Subquery) SQLValue {
+	sql, args, err := sub.ToSQL()
+	return &SubqueryValue{sql: "(" + sql + ")", args: args, err: err}
+}
+
+func (s *SubqueryValue) SQLString() (string, bool) {
+	return s.sql, false
+}
+
+func (s *SubqueryValue) Value() interface{} {
+	return nil
+}
+
+func (s *SubqueryValue) Args() []interface{} {
+	return s.args
+}
+
+// Check reports the error the subquery failed to build with, if any
+func (s *SubqueryValue) Check(d dialect.Dialect) error {
+	return s.err
+}
+
+// valueSQL renders an operand for dialect d and the args it binds
+func valueSQL(v SQLValue, d dialect.Dialect) (string, []interface{}) {
+	if de, ok := v.(DialectExpr); ok {
+		// e.g. COALESCE(...), whose column operands follow d
+		return de.ToSQLDialect(d)
+	}
+	sql, isLiteral := v.SQLString()
+	if _, ok := v.(table.ColumnReference); ok {
+		return Ident(d, sql), nil
+	}
+	if av, ok := v.(ArgsValue); ok {
+		return sql, av.Args()
+	}
+	if isLiteral {
+		return sql, []interface{}{v.Value()}
+	}
+	return sql, nil
+}
@@ -0,0 +1,75 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
+
+// InTupleExpr matches a row value against a list of rows:
+// (a, b) IN ((?, ?), (?, ?)). Args are bound row-major.
+type InTupleExpr struct {
+	Columns []string
+	Rows    [][]interface{}
+	Not     bool
+}
+
+// InTuple matches rows whose columns equal one of rows, e.g. a composite
+// key: InTuple([]string{"orders.tenant_id", "orders.id"}, [][]interface{}{{1, 7}, {1, 9}}).
+// An empty rows list matches nothing. Requires row value support
+// (PostgreSQL, SQLite).
+func InTuple(cols []string, rows [][]interface{}) Expr {
+	return &InTupleExpr{Columns: cols, Rows: rows}
+}
+
+// NotInTuple is the negation of InTuple; an empty rows list matches
+// everything
+func NotInTuple(cols []string, rows [][]interface{}) Expr {
+	return &InTupleExpr{Columns: cols, Rows: rows, Not: true}
+}
+
+func (t *InTupleExpr) ToSQL() (string, []interface{}) {
+	return t.ToSQLDialect(nil)
+}
+
+func (t *InTupleExpr) ToSQLDialect(d dialect.Dialect) (string, []interface{}) {
+	if len(t.Rows) == 0 {
+		if t.Not {
+			return "1=1", nil
+		}
+		return "1=0", nil
+	}
+
+	op := "IN"
+	if t.Not {
+		op = "NOT IN"
+	}
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(t.Columns)), ", ") + ")"
+	rows := make([]string, len(t.Rows))
+	args := make([]interface{}, 0, len(t.Rows)*len(t.Columns))
+	for i, values := range t.Rows {
+		rows[i] = row
+		args = append(args, values...)
+	}
+	columns := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		columns[i] = Ident(d, col)
+	}
+	return "(" + strings.Join(columns, ", ") + ") " + op + " (" + strings.Join(rows, ", ") + ")", args
+}
+
+// Check rejects rows whose width differs from the column list and dialects
+// without row values
+func (t *InTupleExpr) Check(d dialect.Dialect) error {
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("InTuple requires at least one column")
+	}
+	for i, values := range t.Rows {
+		if len(values) != len(t.Columns) {
+			return fmt.Errorf("InTuple row %d has %d values, want %d", i, len(values), len(t.Columns))
+		}
+	}
+	return dialect.Require(d, dialect.FeatureRowValues)
+}
@@ -0,0 +1,110 @@
+// Package integration runs the engine against a real SQLite database. It
+// lives apart from the engine tests, which register a fake "sqlite3" driver.
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/guadalsistema/go-compose-sql/v2/engine"
+	"github.com/guadalsistema/go-compose-sql/v2/expr"
+	"github.com/guadalsistema/go-compose-sql/v2/table"
+	_ "modernc.org/sqlite"
+)
+
+type clientColumns struct {
+	ID   *table.Column[int64]
+	Name *table.Column[string]
+}
+
+type instanceColumns struct {
+	ID       *table.Column[int64]
+	Name     *table.Column[string]
+	ClientID *table.Column[int64]
+}
+
+var registerOnce sync.Once
+
+// openSQLite opens an engine on a fresh SQLite file. The engine opens
+// SQLite through the "sqlite3" driver name, so modernc.org/sqlite (which
+// registers as "sqlite") is registered under it as well.
+func openSQLite(t *testing.T, opts engine.EngineOpts) *engine.Connection {
+	t.Helper()
+	registerOnce.Do(func() {
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatalf("sql.Open() error = %v", err)
+		}
+		sql.Register("sqlite3", db.Driver())
+		db.Close()
+	})
+
+	// A file, unlike :memory:, is shared by every pooled connection
+	url := "sqlite:///" + filepath.Join(t.TempDir(), "app.db")
+	eng, err := engine.NewEngine(url, opts)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	conn, err := eng.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestIntegrationSQLiteForeignKeysCascade(t *testing.T) {
+	conn := openSQLite(t, engine.EngineOpts{SQLiteForeignKeys: true})
+	ctx := context.Background()
+
+	clients := table.NewTable("client", clientColumns{
+		ID:   table.Col[int64]("id").PrimaryKey(),
+		Name: table.Col[string]("name").NotNull(),
+	})
+	instances := table.NewTable("instance", instanceColumns{
+		ID:       table.Col[int64]("id").PrimaryKey(),
+		Name:     table.Col[string]("name").NotNull(),
+		ClientID: table.Col[int64]("client_id").NotNull().ForeignKey("client", "id").OnDelete(table.Cascade),
+	})
+	for _, ddl := range []func() (string, error){
+		func() (string, error) { return clients.CreateTableSQL(conn.Dialect()) },
+		func() (string, error) { return instances.CreateTableSQL(conn.Dialect()) },
+	} {
+		stmt, err := ddl()
+		if err != nil {
+			t.Fatalf("CreateTableSQL() error = %v", err)
+		}
+		if _, err := conn.ExecuteContext(ctx, stmt); err != nil {
+			t.Fatalf("create table: %v", err)
+		}
+	}
+
+	if _, err := conn.Insert(clients).Set("id", int64(1)).Set("name", "acme").Exec(ctx); err != nil {
+		t.Fatalf("insert client: %v", err)
+	}
+	for _, name := range []string{"prod", "staging"} {
+		if _, err := conn.Insert(instances).Set("name", name).Set("client_id", int64(1)).Exec(ctx); err != nil {
+			t.Fatalf("insert instance: %v", err)
+		}
+	}
+
+	if _, err := conn.Delete(clients).Where(expr.Eq(clients.C.ID, int64(1))).Exec(ctx); err != nil {
+		t.Fatalf("delete client: %v", err)
+	}
+
+	n, err := conn.Query(instances).Where(expr.Eq(instances.C.ClientID, int64(1))).Count(ctx)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("ON DELETE CASCADE left %d instances of the deleted client", n)
+	}
+
+	// Enforcement also rejects rows pointing at a missing client
+	if _, err := conn.Insert(instances).Set("name", "orphan").Set("client_id", int64(99)).Exec(ctx); err == nil {
+		t.Fatal("expected a foreign key violation for a missing client")
+	}
+}
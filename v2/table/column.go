@@ -1,6 +1,11 @@
 package table
 
-import "fmt"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
 
 // Column represents a database column with type safety
 type Column[T any] struct {
@@ -18,14 +23,56 @@ type ColumnOptions struct {
 	AutoIncr   bool
 	DefaultVal interface{}
 	ForeignKey *ForeignKeyRef
+	Index      bool
+	IndexName  string
+	SoftDelete bool
+	Sensitive  bool
 }
 
 // ForeignKeyRef represents a foreign key relationship
 type ForeignKeyRef struct {
-	Table  string
-	Column string
+	Table    string
+	Column   string
+	OnDelete ReferentialAction // Optional ON DELETE action
+	OnUpdate ReferentialAction // Optional ON UPDATE action
 }
 
+// SensitiveValue wraps a value bound to a Sensitive column so query logs can
+// redact it. Builders unwrap it before execution; as a driver.Valuer it also
+// passes the underlying value through when ToSQL output is run directly.
+type SensitiveValue struct {
+	Val interface{}
+}
+
+// Value implements driver.Valuer
+func (s SensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(s.Val)
+}
+
+// RawDefault is a column default rendered verbatim as SQL instead of being bound
+type RawDefault string
+
+// CurrentTimestamp is the CURRENT_TIMESTAMP column default
+const CurrentTimestamp RawDefault = "CURRENT_TIMESTAMP"
+
+// NullIf returns v as a nullable value that binds NULL when cond is true,
+// e.g. NullIf(email == "", email). Like the sql.Null* types it scans back
+// with Valid false for NULL.
+func NullIf[T any](cond bool, v T) sql.Null[T] {
+	return sql.Null[T]{V: v, Valid: !cond}
+}
+
+// ReferentialAction is the action taken when a referenced row changes
+type ReferentialAction string
+
+const (
+	Cascade    ReferentialAction = "CASCADE"
+	SetNull    ReferentialAction = "SET NULL"
+	SetDefault ReferentialAction = "SET DEFAULT"
+	Restrict   ReferentialAction = "RESTRICT"
+	NoAction   ReferentialAction = "NO ACTION"
+)
+
 // NewColumn creates a new column
 func NewColumn[T any](name string) *Column[T] {
 	return &Column[T]{
@@ -49,6 +96,14 @@ func (c *Column[T]) TableName() string {
 	return c.tableName
 }
 
+// ColumnReference is implemented by every *Column[T], for APIs that take
+// columns of mixed value types
+type ColumnReference interface {
+	Name() string
+	TableName() string
+	FullName() string
+}
+
 // FullName returns the fully qualified column name (table.column)
 func (c *Column[T]) FullName() string {
 	if c.tableName != "" {
@@ -57,6 +112,11 @@ func (c *Column[T]) FullName() string {
 	return c.name
 }
 
+// goType returns the Go type the column holds
+func (c *Column[T]) goType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
 // setTableName sets the parent table name (called during table initialization)
 func (c *Column[T]) setTableName(tableName string) {
 	c.tableName = tableName
@@ -104,6 +164,39 @@ func (c *Column[T]) Default(val T) *Column[T] {
 	return c
 }
 
+// SoftDelete marks this column as the soft-delete timestamp. Selects skip rows
+// where it is set and deletes set it instead of removing the row.
+func (c *Column[T]) SoftDelete() *Column[T] {
+	c.options.SoftDelete = true
+	return c
+}
+
+// Sensitive marks this column's bound values (passwords, tokens) to be
+// redacted from query logs. Values sent to the database are unchanged.
+func (c *Column[T]) Sensitive() *Column[T] {
+	c.options.Sensitive = true
+	return c
+}
+
+// Index requests a secondary index on this column named idx_<table>_<column>
+func (c *Column[T]) Index() *Column[T] {
+	c.options.Index = true
+	return c
+}
+
+// IndexNamed requests a secondary index on this column with an explicit name
+func (c *Column[T]) IndexNamed(name string) *Column[T] {
+	c.options.Index = true
+	c.options.IndexName = name
+	return c
+}
+
+// DefaultCurrentTimestamp sets CURRENT_TIMESTAMP as the column default
+func (c *Column[T]) DefaultCurrentTimestamp() *Column[T] {
+	c.options.DefaultVal = CurrentTimestamp
+	return c
+}
+
 // ForeignKey sets a foreign key reference
 func (c *Column[T]) ForeignKey(table, column string) *Column[T] {
 	c.options.ForeignKey = &ForeignKeyRef{
@@ -113,6 +206,22 @@ func (c *Column[T]) ForeignKey(table, column string) *Column[T] {
 	return c
 }
 
+// OnDelete sets the ON DELETE action of the foreign key (call after ForeignKey)
+func (c *Column[T]) OnDelete(action ReferentialAction) *Column[T] {
+	if c.options.ForeignKey != nil {
+		c.options.ForeignKey.OnDelete = action
+	}
+	return c
+}
+
+// OnUpdate sets the ON UPDATE action of the foreign key (call after ForeignKey)
+func (c *Column[T]) OnUpdate(action ReferentialAction) *Column[T] {
+	if c.options.ForeignKey != nil {
+		c.options.ForeignKey.OnUpdate = action
+	}
+	return c
+}
+
 // SQLString implements the SQLValue interface for Column
 // Returns the column name and false (not a literal value)
 func (c *Column[T]) SQLString() (string, bool) {
@@ -0,0 +1,292 @@
+package table
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
+
+// CheckConstraint represents a table-level CHECK constraint
+type CheckConstraint struct {
+	Name string // Optional; rendered as CONSTRAINT <name> CHECK (...)
+	Expr string
+}
+
+// PrimaryKey declares a table-level (possibly composite) primary key.
+// When set it takes precedence over column-level PrimaryKey() flags.
+func (t *Table[T]) PrimaryKey(cols ...string) *Table[T] {
+	t.primaryKey = cols
+	return t
+}
+
+// Unique declares a table-level UNIQUE constraint over the given columns
+func (t *Table[T]) Unique(cols ...string) *Table[T] {
+	t.uniques = append(t.uniques, cols)
+	return t
+}
+
+// Check declares a CHECK constraint; name may be empty for an unnamed constraint
+func (t *Table[T]) Check(name string, expr string) *Table[T] {
+	t.checks = append(t.checks, CheckConstraint{Name: name, Expr: expr})
+	return t
+}
+
+// PrimaryKeyColumns returns the primary key columns, preferring the
+// table-level declaration over column-level PrimaryKey() flags
+func (t *Table[T]) PrimaryKeyColumns() []string {
+	if len(t.primaryKey) > 0 {
+		return t.primaryKey
+	}
+	var cols []string
+	for _, col := range t.columns {
+		if col.Options.PrimaryKey {
+			cols = append(cols, col.Name)
+		}
+	}
+	return cols
+}
+
+// CreateTableSQL generates the CREATE TABLE statement for the table.
+// A single-column primary key is rendered inline; composite keys (declared
+// with Table.PrimaryKey or several column-level PrimaryKey() flags) are
+// rendered as a table-level PRIMARY KEY (a, b) constraint. Column foreign
+// keys are rendered as FOREIGN KEY (x) REFERENCES other (col) clauses.
+func (t *Table[T]) CreateTableSQL(d dialect.Dialect) (string, error) {
+	if t.name == "" {
+		return "", fmt.Errorf("invalid table")
+	}
+	if len(t.columns) == 0 {
+		return "", fmt.Errorf("table %s has no columns", t.name)
+	}
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+
+	pkCols := t.PrimaryKeyColumns()
+	if len(t.primaryKey) > 0 {
+		declared := make(map[string]struct{}, len(t.primaryKey))
+		for _, name := range t.primaryKey {
+			if t.column(name) == nil {
+				return "", fmt.Errorf("primary key column %s not found in table %s", name, t.name)
+			}
+			declared[name] = struct{}{}
+		}
+		for _, col := range t.columns {
+			if _, ok := declared[col.Name]; col.Options.PrimaryKey && !ok {
+				return "", fmt.Errorf("column %s is marked as primary key but is not part of the table primary key", col.Name)
+			}
+		}
+	}
+	inlinePK := len(pkCols) == 1
+
+	defs := make([]string, 0, len(t.columns)+len(t.uniques)+len(t.checks)+1)
+	for _, col := range t.columns {
+		def, err := columnDefinition(d, col, inlinePK && col.Name == pkCols[0])
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+
+	if len(pkCols) > 1 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(pkCols, ", ")+")")
+	}
+	for _, col := range t.columns {
+		if col.Options.ForeignKey != nil {
+			defs = append(defs, foreignKeyConstraint(col.Name, col.Options.ForeignKey))
+		}
+	}
+	for _, cols := range t.uniques {
+		defs = append(defs, "UNIQUE ("+strings.Join(cols, ", ")+")")
+	}
+	for _, check := range t.checks {
+		if check.Name != "" {
+			defs = append(defs, "CONSTRAINT "+check.Name+" CHECK ("+check.Expr+")")
+			continue
+		}
+		defs = append(defs, "CHECK ("+check.Expr+")")
+	}
+
+	return "CREATE TABLE " + t.name + " (" + strings.Join(defs, ", ") + ")", nil
+}
+
+// AddColumnSQL generates the ALTER TABLE ... ADD COLUMN statement for a
+// declared column, e.g. one SchemaDiff reports missing. Dialect rules:
+//   - existing rows get the column DEFAULT; on PostgreSQL a NOT NULL column
+//     without one fails unless the table is empty
+//   - foreign keys are added as an ADD FOREIGN KEY clause of the same
+//     statement, or inline REFERENCES on SQLite
+//   - SQLite cannot add PRIMARY KEY or UNIQUE columns, NOT NULL columns
+//     without a default, or columns with a non-constant default such as
+//     CURRENT_TIMESTAMP; those are errors
+func (t *Table[T]) AddColumnSQL(d dialect.Dialect, colName string) (string, error) {
+	if t.name == "" {
+		return "", fmt.Errorf("invalid table")
+	}
+	col := t.column(colName)
+	if col == nil {
+		return "", fmt.Errorf("column %s not found in table %s", colName, t.name)
+	}
+
+	pkCols := t.PrimaryKeyColumns()
+	primaryKey := false
+	for _, name := range pkCols {
+		if name == col.Name {
+			primaryKey = true
+		}
+	}
+	if primaryKey && len(pkCols) > 1 {
+		return "", fmt.Errorf("column %s is part of the composite primary key of table %s and cannot be added with ADD COLUMN", col.Name, t.name)
+	}
+	sqlite := d.Name() == "sqlite"
+	if sqlite {
+		if err := checkSQLiteAddColumn(col, primaryKey); err != nil {
+			return "", err
+		}
+	}
+
+	def, err := columnDefinition(d, col, primaryKey)
+	if err != nil {
+		return "", err
+	}
+	sql := "ALTER TABLE " + t.name + " ADD COLUMN " + def
+	if ref := col.Options.ForeignKey; ref != nil {
+		if sqlite {
+			sql += " " + referencesClause(ref)
+		} else {
+			sql += ", ADD " + foreignKeyConstraint(col.Name, ref)
+		}
+	}
+	return sql, nil
+}
+
+// checkSQLiteAddColumn rejects columns SQLite's ALTER TABLE ADD COLUMN
+// refuses, so the error names the column instead of surfacing at execution
+func checkSQLiteAddColumn(col *ColumnRef, primaryKey bool) error {
+	if primaryKey || col.Options.Unique {
+		return fmt.Errorf("sqlite cannot add PRIMARY KEY or UNIQUE column %s with ADD COLUMN", col.Name)
+	}
+	if col.Options.NotNull && col.Options.DefaultVal == nil {
+		return fmt.Errorf("sqlite cannot add NOT NULL column %s without a default", col.Name)
+	}
+	if raw, ok := col.Options.DefaultVal.(RawDefault); ok {
+		expr := strings.ToUpper(strings.TrimSpace(string(raw)))
+		if strings.HasPrefix(expr, "CURRENT_") || strings.HasPrefix(expr, "(") {
+			return fmt.Errorf("sqlite cannot add column %s with non-constant default %s", col.Name, raw)
+		}
+	}
+	return nil
+}
+
+// column returns the column reference with the given name, or nil
+func (t *Table[T]) column(name string) *ColumnRef {
+	for _, col := range t.columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// columnDefinition renders a single column definition for CREATE TABLE
+func columnDefinition(d dialect.Dialect, col *ColumnRef, primaryKey bool) (string, error) {
+	sqlType := d.ColumnType(ddlType(col.Type))
+	if sqlType == "" {
+		return "", fmt.Errorf("unsupported type %s for column %s", col.Type, col.Name)
+	}
+
+	parts := []string{col.Name, sqlType}
+	if col.Options.NotNull {
+		parts = append(parts, "NOT NULL")
+	}
+	if primaryKey {
+		parts = append(parts, "PRIMARY KEY")
+	}
+	if col.Options.AutoIncr {
+		parts = append(parts, d.FormatAutoIncrement())
+	}
+	if col.Options.Unique {
+		parts = append(parts, "UNIQUE")
+	}
+	if col.Options.DefaultVal != nil {
+		parts = append(parts, "DEFAULT "+formatLiteral(col.Options.DefaultVal))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// foreignKeyConstraint renders a table-level FOREIGN KEY clause.
+// The table-level form is used because MySQL ignores inline REFERENCES.
+func foreignKeyConstraint(column string, ref *ForeignKeyRef) string {
+	return "FOREIGN KEY (" + column + ") " + referencesClause(ref)
+}
+
+// referencesClause renders REFERENCES other (col) with its actions
+func referencesClause(ref *ForeignKeyRef) string {
+	sql := "REFERENCES " + ref.Table + " (" + ref.Column + ")"
+	if ref.OnDelete != "" {
+		sql += " ON DELETE " + string(ref.OnDelete)
+	}
+	if ref.OnUpdate != "" {
+		sql += " ON UPDATE " + string(ref.OnUpdate)
+	}
+	return sql
+}
+
+// SQLType returns the dialect SQL type of the column, e.g. for casts.
+// Returns empty string if the Go type has no mapping.
+func (c *ColumnRef) SQLType(d dialect.Dialect) string {
+	if c.Type == nil {
+		return ""
+	}
+	return d.ColumnType(ddlType(c.Type))
+}
+
+// ddlType unwraps pointers and sql.Null* wrappers to the underlying value type
+func ddlType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.NumField() == 2 {
+		if valid, ok := t.FieldByName("Valid"); ok && valid.Type.Kind() == reflect.Bool {
+			for i := 0; i < t.NumField(); i++ {
+				if f := t.Field(i); f.Name != "Valid" {
+					return f.Type
+				}
+			}
+		}
+	}
+	return t
+}
+
+// formatLiteral renders a Go value as a SQL literal for DDL
+func formatLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case RawDefault:
+		return string(val)
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return "'" + val.Format("2006-01-02 15:04:05") + "'"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(val)
+	case driver.Valuer:
+		// sql.Null* and custom types render as the value they bind
+		if dv, err := val.Value(); err == nil {
+			return formatLiteral(dv)
+		}
+		return "'" + strings.ReplaceAll(fmt.Sprint(val), "'", "''") + "'"
+	default:
+		return "'" + strings.ReplaceAll(fmt.Sprint(val), "'", "''") + "'"
+	}
+}
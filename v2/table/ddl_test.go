@@ -0,0 +1,297 @@
+package table
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/sqlite"
+)
+
+type membershipColumns struct {
+	UserID  *Column[int64]
+	GroupID *Column[int64]
+	Role    *Column[string]
+	Weight  *Column[int]
+}
+
+func newMembershipTable() *Table[membershipColumns] {
+	return NewTable("memberships", membershipColumns{
+		UserID:  Col[int64]("user_id").NotNull(),
+		GroupID: Col[int64]("group_id").NotNull(),
+		Role:    Col[string]("role").Default("member"),
+		Weight:  Col[int]("weight"),
+	})
+}
+
+func TestCreateTableSQLSingleColumnPrimaryKey(t *testing.T) {
+	users := newUsersTable()
+
+	tests := []struct {
+		name string
+		got  func() (string, error)
+		want string
+	}{
+		{
+			name: "postgres",
+			got:  func() (string, error) { return users.CreateTableSQL(&postgres.PostgresDialect{}) },
+			want: "CREATE TABLE users (id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY, email TEXT NOT NULL UNIQUE, name TEXT)",
+		},
+		{
+			name: "sqlite",
+			got:  func() (string, error) { return users.CreateTableSQL(&sqlite.SQLiteDialect{}) },
+			want: "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL UNIQUE, name TEXT)",
+		},
+		{
+			name: "mysql",
+			got:  func() (string, error) { return users.CreateTableSQL(&mysql.MySQLDialect{}) },
+			want: "CREATE TABLE users (id BIGINT PRIMARY KEY AUTO_INCREMENT, email VARCHAR(255) NOT NULL UNIQUE, name VARCHAR(255))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil {
+				t.Fatalf("CreateTableSQL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTableSQLCompositeConstraints(t *testing.T) {
+	memberships := newMembershipTable().
+		PrimaryKey("user_id", "group_id").
+		Unique("group_id", "role").
+		Check("weight_positive", "weight > 0").
+		Check("", "role <> ''")
+
+	got, err := memberships.CreateTableSQL(&postgres.PostgresDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	want := "CREATE TABLE memberships (user_id BIGINT NOT NULL, group_id BIGINT NOT NULL, role TEXT DEFAULT 'member', weight BIGINT, " +
+		"PRIMARY KEY (user_id, group_id), UNIQUE (group_id, role), CONSTRAINT weight_positive CHECK (weight > 0), CHECK (role <> ''))"
+	if got != want {
+		t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateTableSQLColumnLevelCompositePrimaryKey(t *testing.T) {
+	memberships := NewTable("memberships", membershipColumns{
+		UserID:  Col[int64]("user_id").PrimaryKey(),
+		GroupID: Col[int64]("group_id").PrimaryKey(),
+	})
+
+	got, err := memberships.CreateTableSQL(&sqlite.SQLiteDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	want := "CREATE TABLE memberships (user_id INTEGER, group_id INTEGER, PRIMARY KEY (user_id, group_id))"
+	if got != want {
+		t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateTableSQLTableLevelPrimaryKeyMatchesColumnFlag(t *testing.T) {
+	memberships := NewTable("memberships", membershipColumns{
+		UserID:  Col[int64]("user_id").PrimaryKey(),
+		GroupID: Col[int64]("group_id"),
+	}).PrimaryKey("user_id", "group_id")
+
+	got, err := memberships.CreateTableSQL(&sqlite.SQLiteDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	if !strings.HasSuffix(got, "PRIMARY KEY (user_id, group_id))") || strings.Contains(got, "user_id INTEGER PRIMARY KEY") {
+		t.Fatalf("expected only the table-level primary key, got %s", got)
+	}
+}
+
+func TestCreateTableSQLConflictingPrimaryKey(t *testing.T) {
+	memberships := NewTable("memberships", membershipColumns{
+		UserID:  Col[int64]("user_id"),
+		GroupID: Col[int64]("group_id"),
+		Role:    Col[string]("role").PrimaryKey(),
+	}).PrimaryKey("user_id", "group_id")
+
+	if _, err := memberships.CreateTableSQL(&postgres.PostgresDialect{}); err == nil {
+		t.Fatalf("expected error for column primary key outside the table primary key")
+	}
+
+	unknown := newMembershipTable().PrimaryKey("user_id", "missing")
+	if _, err := unknown.CreateTableSQL(&postgres.PostgresDialect{}); err == nil {
+		t.Fatalf("expected error for unknown primary key column")
+	}
+}
+
+type odooInstanceColumns struct {
+	ID       *Column[int64]
+	Name     *Column[string]
+	ClientID *Column[int64]
+	OwnerID  *Column[int64]
+}
+
+func TestCreateTableSQLForeignKeys(t *testing.T) {
+	instances := NewTable("odoo_instance", odooInstanceColumns{
+		ID:       Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Name:     Col[string]("name").NotNull(),
+		ClientID: Col[int64]("client_id").NotNull().ForeignKey("client", "id").OnDelete(Cascade),
+		OwnerID:  Col[int64]("owner_id").ForeignKey("users", "id").OnDelete(SetNull).OnUpdate(Cascade),
+	})
+
+	got, err := instances.CreateTableSQL(&sqlite.SQLiteDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	want := "CREATE TABLE odoo_instance (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, client_id INTEGER NOT NULL, owner_id INTEGER, " +
+		"FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE CASCADE, " +
+		"FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE CASCADE)"
+	if got != want {
+		t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateTableSQLRawDefault(t *testing.T) {
+	type eventColumns struct {
+		ID        *Column[int64]
+		CreatedAt *Column[time.Time]
+	}
+	events := NewTable("events", eventColumns{
+		ID:        Col[int64]("id").PrimaryKey(),
+		CreatedAt: Col[time.Time]("created_at").NotNull().DefaultCurrentTimestamp(),
+	})
+
+	got, err := events.CreateTableSQL(&postgres.PostgresDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	if want := "CREATE TABLE events (id BIGINT PRIMARY KEY, created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP)"; got != want {
+		t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateTableSQLNullableDefaults(t *testing.T) {
+	type profileColumns struct {
+		ID       *Column[int64]
+		Nickname *Column[sql.NullString]
+		Rank     *Column[sql.Null[int64]]
+	}
+	profiles := NewTable("profiles", profileColumns{
+		ID:       Col[int64]("id").PrimaryKey(),
+		Nickname: Col[sql.NullString]("nickname").Default(sql.NullString{String: "anon", Valid: true}),
+		Rank:     Col[sql.Null[int64]]("rank").Default(NullIf(true, int64(0))),
+	})
+
+	got, err := profiles.CreateTableSQL(&postgres.PostgresDialect{})
+	if err != nil {
+		t.Fatalf("CreateTableSQL() error = %v", err)
+	}
+	if want := "CREATE TABLE profiles (id BIGINT PRIMARY KEY, nickname TEXT DEFAULT 'anon', rank BIGINT DEFAULT NULL)"; got != want {
+		t.Fatalf("CreateTableSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddColumnSQL(t *testing.T) {
+	memberships := newMembershipTable()
+	instances := NewTable("odoo_instance", odooInstanceColumns{
+		ID:       Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Name:     Col[string]("name").NotNull(),
+		ClientID: Col[int64]("client_id").NotNull().ForeignKey("client", "id").OnDelete(Cascade),
+		OwnerID:  Col[int64]("owner_id").ForeignKey("users", "id").OnDelete(SetNull),
+	})
+
+	tests := []struct {
+		name string
+		got  func() (string, error)
+		want string
+	}{
+		{
+			name: "postgres default backfill",
+			got:  func() (string, error) { return memberships.AddColumnSQL(&postgres.PostgresDialect{}, "role") },
+			want: "ALTER TABLE memberships ADD COLUMN role TEXT DEFAULT 'member'",
+		},
+		{
+			name: "mysql",
+			got:  func() (string, error) { return memberships.AddColumnSQL(&mysql.MySQLDialect{}, "role") },
+			want: "ALTER TABLE memberships ADD COLUMN role VARCHAR(255) DEFAULT 'member'",
+		},
+		{
+			name: "sqlite",
+			got:  func() (string, error) { return memberships.AddColumnSQL(&sqlite.SQLiteDialect{}, "weight") },
+			want: "ALTER TABLE memberships ADD COLUMN weight INTEGER",
+		},
+		{
+			name: "postgres foreign key",
+			got:  func() (string, error) { return instances.AddColumnSQL(&postgres.PostgresDialect{}, "owner_id") },
+			want: "ALTER TABLE odoo_instance ADD COLUMN owner_id BIGINT, ADD FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE SET NULL",
+		},
+		{
+			name: "sqlite foreign key",
+			got:  func() (string, error) { return instances.AddColumnSQL(&sqlite.SQLiteDialect{}, "owner_id") },
+			want: "ALTER TABLE odoo_instance ADD COLUMN owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil {
+				t.Fatalf("AddColumnSQL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("AddColumnSQL() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddColumnSQLErrors(t *testing.T) {
+	type eventColumns struct {
+		ID        *Column[int64]
+		Code      *Column[string]
+		CreatedAt *Column[time.Time]
+	}
+	events := NewTable("events", eventColumns{
+		ID:        Col[int64]("id").PrimaryKey(),
+		Code:      Col[string]("code").NotNull().Unique(),
+		CreatedAt: Col[time.Time]("created_at").DefaultCurrentTimestamp(),
+	})
+	memberships := newMembershipTable().PrimaryKey("user_id", "group_id")
+
+	tests := []struct {
+		name string
+		err  func() error
+		want string
+	}{
+		{"unknown column", func() error { _, err := events.AddColumnSQL(&postgres.PostgresDialect{}, "missing"); return err }, "not found"},
+		{"composite primary key", func() error { _, err := memberships.AddColumnSQL(&postgres.PostgresDialect{}, "group_id"); return err }, "composite primary key"},
+		{"sqlite primary key", func() error { _, err := events.AddColumnSQL(&sqlite.SQLiteDialect{}, "id"); return err }, "PRIMARY KEY or UNIQUE"},
+		{"sqlite not null", func() error {
+			_, err := newMembershipTable().AddColumnSQL(&sqlite.SQLiteDialect{}, "user_id")
+			return err
+		}, "without a default"},
+		{"sqlite non-constant default", func() error { _, err := events.AddColumnSQL(&sqlite.SQLiteDialect{}, "created_at"); return err }, "non-constant default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("AddColumnSQL() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	got, err := events.AddColumnSQL(&postgres.PostgresDialect{}, "created_at")
+	if err != nil {
+		t.Fatalf("AddColumnSQL() error = %v", err)
+	}
+	if want := "ALTER TABLE events ADD COLUMN created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP"; got != want {
+		t.Fatalf("AddColumnSQL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect"
+)
+
+// Index describes a secondary index declared on a table
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Where   string // Optional predicate for partial indexes
+}
+
+// AddIndex declares a (possibly composite) index over the given columns.
+// An empty name defaults to idx_<table>_<col1>_<col2>...
+func (t *Table[T]) AddIndex(name string, cols ...string) *Table[T] {
+	t.indexes = append(t.indexes, Index{
+		Name:    name,
+		Columns: cols,
+	})
+	return t
+}
+
+// AddPartialIndex declares an index restricted to the rows matching where,
+// e.g. AddPartialIndex("", "deleted_at IS NULL", "email").
+// Dialects without partial indexes (MySQL) get a full index over the same
+// columns instead, which serves the same lookups at a larger size.
+func (t *Table[T]) AddPartialIndex(name string, where string, cols ...string) *Table[T] {
+	t.indexes = append(t.indexes, Index{
+		Name:    name,
+		Columns: cols,
+		Where:   where,
+	})
+	return t
+}
+
+// Indexes returns every index declared on the table, column-level first
+func (t *Table[T]) Indexes() []Index {
+	var indexes []Index
+	for _, col := range t.columns {
+		if !col.Options.Index {
+			continue
+		}
+		// A UNIQUE column already implies a unique index, so an explicit
+		// Index() on it produces a UNIQUE index instead of a second plain one.
+		indexes = append(indexes, Index{
+			Name:    col.Options.IndexName,
+			Columns: []string{col.Name},
+			Unique:  col.Options.Unique,
+		})
+	}
+	indexes = append(indexes, t.indexes...)
+
+	for i := range indexes {
+		if indexes[i].Name == "" {
+			indexes[i].Name = "idx_" + t.name + "_" + strings.Join(indexes[i].Columns, "_")
+		}
+	}
+	return indexes
+}
+
+// CreateIndexesSQL generates one CREATE INDEX statement per declared index,
+// e.g. CREATE INDEX idx_users_email ON users (email). A partial index is
+// emitted without its WHERE clause on dialects that do not support it.
+func (t *Table[T]) CreateIndexesSQL(d dialect.Dialect) []string {
+	var stmts []string
+	for _, idx := range t.Indexes() {
+		if len(idx.Columns) == 0 {
+			continue
+		}
+		var sql strings.Builder
+		sql.WriteString("CREATE ")
+		if idx.Unique {
+			sql.WriteString("UNIQUE ")
+		}
+		sql.WriteString("INDEX ")
+		sql.WriteString(idx.Name)
+		sql.WriteString(" ON ")
+		sql.WriteString(t.name)
+		sql.WriteString(" (")
+		sql.WriteString(strings.Join(idx.Columns, ", "))
+		sql.WriteString(")")
+		if idx.Where != "" && d.SupportsPartialIndex() {
+			sql.WriteString(" WHERE ")
+			sql.WriteString(idx.Where)
+		}
+		stmts = append(stmts, sql.String())
+	}
+	return stmts
+}
@@ -0,0 +1,69 @@
+package table
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/mysql"
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+)
+
+type indexedColumns struct {
+	ID        *Column[int64]
+	Email     *Column[string]
+	Name      *Column[string]
+	ClientID  *Column[int64]
+	DeletedAt *Column[string]
+}
+
+func newIndexedTable() *Table[indexedColumns] {
+	return NewTable("users", indexedColumns{
+		ID:        Col[int64]("id").PrimaryKey(),
+		Email:     Col[string]("email").Unique().Index(),
+		Name:      Col[string]("name").IndexNamed("users_by_name"),
+		ClientID:  Col[int64]("client_id").Index(),
+		DeletedAt: Col[string]("deleted_at"),
+	})
+}
+
+func TestCreateIndexesSQL(t *testing.T) {
+	users := newIndexedTable().
+		AddIndex("", "client_id", "name").
+		AddPartialIndex("users_active_email", "deleted_at IS NULL", "email")
+
+	got := users.CreateIndexesSQL(&postgres.PostgresDialect{})
+	want := []string{
+		"CREATE UNIQUE INDEX idx_users_email ON users (email)",
+		"CREATE INDEX users_by_name ON users (name)",
+		"CREATE INDEX idx_users_client_id ON users (client_id)",
+		"CREATE INDEX idx_users_client_id_name ON users (client_id, name)",
+		"CREATE INDEX users_active_email ON users (email) WHERE deleted_at IS NULL",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CreateIndexesSQL() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCreateIndexesSQLUniqueColumnWithoutIndex(t *testing.T) {
+	users := newUsersTable()
+
+	if got := users.CreateIndexesSQL(&postgres.PostgresDialect{}); len(got) != 0 {
+		t.Fatalf("expected UNIQUE constraint alone to emit no index, got %v", got)
+	}
+}
+
+func TestCreateIndexesSQLPartialIndexFallsBackOnMySQL(t *testing.T) {
+	users := newIndexedTable().
+		AddPartialIndex("users_active_email", "deleted_at IS NULL", "email")
+
+	got := users.CreateIndexesSQL(&mysql.MySQLDialect{})
+	want := []string{
+		"CREATE UNIQUE INDEX idx_users_email ON users (email)",
+		"CREATE INDEX users_by_name ON users (name)",
+		"CREATE INDEX idx_users_client_id ON users (client_id)",
+		"CREATE INDEX users_active_email ON users (email)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CreateIndexesSQL() =\n%v\nwant\n%v", got, want)
+	}
+}
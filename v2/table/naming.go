@@ -0,0 +1,26 @@
+package table
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AcronymSnakeCase converts a Go field name to snake_case, splitting
+// acronyms from the following word: APIKey -> api_key,
+// HTTPServer -> http_server, UserID -> user_id. Pass it to MapFields, or
+// wrap it to override individual names.
+func AcronymSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) && prev != '_' || unicode.IsUpper(prev) && nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
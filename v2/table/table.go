@@ -1,8 +1,8 @@
 package table
 
 import (
+	"fmt"
 	"reflect"
-	"strings"
 )
 
 // TableInterface is the interface that all table types must implement.
@@ -10,13 +10,19 @@ import (
 type TableInterface interface {
 	Name() string
 	Columns() []*ColumnRef
+	PrimaryKeyColumns() []string
 }
 
 // Table represents a database table with typed columns
 type Table[T any] struct {
-	name    string
-	columns []*ColumnRef
-	C       T // Column accessor (holds column definitions)
+	name       string
+	columns    []*ColumnRef
+	indexes    []Index
+	primaryKey []string
+	uniques    [][]string
+	checks     []CheckConstraint
+	mapper     func(string) string
+	C          T // Column accessor (holds column definitions)
 }
 
 // ColumnRef holds metadata about a column without type parameters
@@ -50,6 +56,21 @@ func (t *Table[T]) Columns() []*ColumnRef {
 	return t.columns
 }
 
+// MapFields sets how the builders map untagged struct fields to column
+// names when inserting and scanning rows of this table, e.g.
+// MapFields(table.AcronymSnakeCase) maps APIKey to api_key. The default
+// snake_case conversion keeps acronyms together (APIKey -> apikey). Tagged
+// fields always use their tag.
+func (t *Table[T]) MapFields(mapper func(string) string) *Table[T] {
+	t.mapper = mapper
+	return t
+}
+
+// FieldMapper returns the function set by MapFields, or nil for the default
+func (t *Table[T]) FieldMapper() func(string) string {
+	return t.mapper
+}
+
 // ColumnNames returns all column names
 func (t *Table[T]) ColumnNames() []string {
 	names := make([]string, len(t.columns))
@@ -59,6 +80,38 @@ func (t *Table[T]) ColumnNames() []string {
 	return names
 }
 
+// Validate reports definition mistakes that NewTable cannot reject, such as
+// two fields mapping to the same column name or more than one
+// AutoIncrement column. CreateTableSQL calls it; call it directly, e.g. from
+// a test, to catch copy-paste errors in column structs early.
+func (t *Table[T]) Validate() error {
+	seen := make(map[string]struct{}, len(t.columns))
+	var autoIncr string
+	for _, col := range t.columns {
+		if _, ok := seen[col.Name]; ok {
+			return fmt.Errorf("table %s declares column %s more than once", t.name, col.Name)
+		}
+		seen[col.Name] = struct{}{}
+		if !col.Options.AutoIncr {
+			continue
+		}
+		if autoIncr != "" {
+			return fmt.Errorf("table %s has more than one auto-increment column: %s and %s", t.name, autoIncr, col.Name)
+		}
+		autoIncr = col.Name
+	}
+	return nil
+}
+
+// columnDef is satisfied by every *Column[T]. It lets extractColumns read
+// column metadata without knowing the type parameter.
+type columnDef interface {
+	Name() string
+	Options() ColumnOptions
+	goType() reflect.Type
+	setTableName(tableName string)
+}
+
 // extractColumns uses reflection to extract column metadata from the struct
 func extractColumns(tableName string, columnStruct interface{}) []*ColumnRef {
 	var columns []*ColumnRef
@@ -85,83 +138,26 @@ func extractColumns(tableName string, columnStruct interface{}) []*ColumnRef {
 			continue
 		}
 
-		// Check if this field is a *Column[T] type
-		if fieldVal.Kind() == reflect.Ptr && fieldVal.Type().String() == "*table.Column[...]" {
-			if fieldVal.IsNil() {
-				continue
-			}
-
-			// Use reflection to call methods on the column
-			nameMethod := fieldVal.MethodByName("Name")
-			if !nameMethod.IsValid() {
-				continue
-			}
-
-			nameResults := nameMethod.Call(nil)
-			if len(nameResults) == 0 {
-				continue
-			}
-
-			columnName := nameResults[0].String()
-
-			// Set the table name on the column
-			setTableNameMethod := fieldVal.MethodByName("setTableName")
-			if setTableNameMethod.IsValid() {
-				// This won't work because setTableName is unexported
-				// We'll need to handle this differently
-			}
-
-			// Get column options
-			var opts ColumnOptions
-			optionsMethod := fieldVal.MethodByName("Options")
-			if optionsMethod.IsValid() {
-				optResults := optionsMethod.Call(nil)
-				if len(optResults) > 0 {
-					if o, ok := optResults[0].Interface().(ColumnOptions); ok {
-						opts = o
-					}
-				}
-			}
-
-			// Extract the type parameter from Column[T]
-			columnType := extractColumnType(fieldVal.Type())
-
-			colRef := &ColumnRef{
-				Name:     columnName,
-				FullName: tableName + "." + columnName,
-				Type:     columnType,
-				Options:  opts,
-			}
-
-			columns = append(columns, colRef)
+		// Only *Column[T] fields describe columns
+		if fieldVal.Kind() != reflect.Ptr || fieldVal.IsNil() {
+			continue
+		}
+		col, ok := fieldVal.Interface().(columnDef)
+		if !ok {
+			continue
 		}
-	}
-
-	return columns
-}
 
-// extractColumnType extracts the type parameter T from *Column[T]
-func extractColumnType(columnPtrType reflect.Type) reflect.Type {
-	// Remove pointer
-	if columnPtrType.Kind() == reflect.Ptr {
-		columnPtrType = columnPtrType.Elem()
-	}
+		// Qualify the column so expressions render table.column
+		col.setTableName(tableName)
 
-	// For generic types, we need to extract the type parameter
-	// Since Go reflection doesn't directly expose type parameters,
-	// we'll use a workaround: get the field type from the struct
-	if columnPtrType.Kind() == reflect.Struct {
-		// This is a simplified approach - in practice, we might need
-		// to store type information differently
-		typeStr := columnPtrType.String()
-		// Extract type from "table.Column[int64]" -> "int64"
-		if idx := strings.Index(typeStr, "["); idx != -1 {
-			typeStr = typeStr[idx+1 : len(typeStr)-1]
-			// This is a placeholder - proper type extraction would require
-			// registering types at column creation time
-		}
+		columnName := col.Name()
+		columns = append(columns, &ColumnRef{
+			Name:     columnName,
+			FullName: tableName + "." + columnName,
+			Type:     col.goType(),
+			Options:  col.Options(),
+		})
 	}
 
-	// Return interface{} as fallback
-	return reflect.TypeOf((*interface{})(nil)).Elem()
+	return columns
 }
@@ -0,0 +1,102 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/guadalsistema/go-compose-sql/v2/dialect/postgres"
+)
+
+type userColumns struct {
+	ID    *Column[int64]
+	Email *Column[string]
+	Name  *Column[string]
+}
+
+func newUsersTable() *Table[userColumns] {
+	return NewTable("users", userColumns{
+		ID:    Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Email: Col[string]("email").Unique().NotNull(),
+		Name:  Col[string]("name"),
+	})
+}
+
+func TestNewTableExtractsColumns(t *testing.T) {
+	users := newUsersTable()
+
+	if got, want := users.ColumnNames(), []string{"id", "email", "name"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ColumnNames() = %v, want %v", got, want)
+	}
+
+	cols := users.Columns()
+	if cols[0].FullName != "users.id" {
+		t.Fatalf("FullName = %q, want %q", cols[0].FullName, "users.id")
+	}
+	if cols[0].Type != reflect.TypeOf(int64(0)) {
+		t.Fatalf("Type = %v, want int64", cols[0].Type)
+	}
+	if !cols[0].Options.PrimaryKey || !cols[0].Options.AutoIncr {
+		t.Fatalf("expected id options to be preserved, got %+v", cols[0].Options)
+	}
+	if cols[1].Type != reflect.TypeOf("") || !cols[1].Options.Unique {
+		t.Fatalf("unexpected email column %+v", cols[1])
+	}
+
+	// Typed columns are qualified too, so they render as table.column operands
+	if got := users.C.Email.FullName(); got != "users.email" {
+		t.Fatalf("C.Email.FullName() = %q, want users.email", got)
+	}
+	if sql, literal := users.C.ID.SQLString(); sql != "users.id" || literal {
+		t.Fatalf("C.ID.SQLString() = %q, %v; want users.id, false", sql, literal)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := newUsersTable().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Email was copied from Name without renaming the column
+	dup := NewTable("users", userColumns{
+		ID:    Col[int64]("id").PrimaryKey(),
+		Email: Col[string]("name"),
+		Name:  Col[string]("name"),
+	})
+	err := dup.Validate()
+	if err == nil || !strings.Contains(err.Error(), "column name more than once") {
+		t.Fatalf("expected duplicate column error, got %v", err)
+	}
+	if _, err := dup.CreateTableSQL(&postgres.PostgresDialect{}); err == nil {
+		t.Fatalf("expected CreateTableSQL to reject duplicate columns")
+	}
+
+	type counterColumns struct {
+		ID  *Column[int64]
+		Seq *Column[int64]
+	}
+	twoAuto := NewTable("counters", counterColumns{
+		ID:  Col[int64]("id").PrimaryKey().AutoIncrement(),
+		Seq: Col[int64]("seq").AutoIncrement(),
+	})
+	if err := twoAuto.Validate(); err == nil || !strings.Contains(err.Error(), "id and seq") {
+		t.Fatalf("expected auto-increment error, got %v", err)
+	}
+}
+
+func TestAcronymSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"Name":       "name",
+		"UserID":     "user_id",
+		"APIKey":     "api_key",
+		"HTTPServer": "http_server",
+		"OAuthToken": "o_auth_token",
+		"ServeHTTP":  "serve_http",
+		"Snake_Case": "snake_case",
+	}
+	for in, want := range cases {
+		if got := AcronymSnakeCase(in); got != want {
+			t.Fatalf("AcronymSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}